@@ -0,0 +1,42 @@
+package main
+
+import (
+	"io"
+	"os"
+)
+
+// captureOutput runs fn while copying everything it prints to stdout into the file at path,
+// so the listing still appears on screen. It returns the number of bytes written to the file.
+func captureOutput(path string, fn func()) (int64, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return 0, err
+	}
+
+	stdout := os.Stdout
+	os.Stdout = writer
+
+	type copyResult struct {
+		written int64
+		err     error
+	}
+	copied := make(chan copyResult)
+	go func() {
+		written, err := io.Copy(io.MultiWriter(stdout, file), reader)
+		copied <- copyResult{written, err}
+	}()
+
+	fn()
+
+	os.Stdout = stdout
+	writer.Close()
+	result := <-copied
+	reader.Close()
+	return result.written, result.err
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"sync"
+)
+
+// console is the single path through which the menu and the background goroutines
+// (SIGHUP reload, live booking refresh, subscription renewal) print and use the
+// GraphHelper. Holding it for a whole action keeps a listing from being interleaved
+// with output from another goroutine, and keeps Graph from being re-initialized
+// while a call is in flight. Background work queues until the current menu action ends.
+type console struct {
+	mu sync.Mutex
+}
+
+func newConsole() *console {
+	return &console{}
+}
+
+// do runs fn with exclusive use of stdout and the GraphHelper.
+func (c *console) do(fn func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fn()
+}
+
+// colourOutput reports whether warnings can be coloured: stdout is a terminal and NO_COLOR
+// is not set.
+func colourOutput() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// sixelTerminals are the TERM and TERM_PROGRAM values of terminals known to draw sixel graphics.
+var sixelTerminals = []string{"foot", "mlterm", "yaft", "contour", "wezterm", "mintty", "iterm.app"}
+
+// sixelSupported reports whether stdout is a terminal known to draw sixel graphics.
+func sixelSupported() bool {
+	info, err := os.Stdout.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	for _, name := range []string{os.Getenv("TERM"), os.Getenv("TERM_PROGRAM")} {
+		name = strings.ToLower(name)
+		for _, terminal := range sixelTerminals {
+			if name != "" && strings.HasPrefix(name, terminal) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestConsoleSerialisesWriters checks, under -race, that output written from several
+// goroutines through the console is never interleaved.
+func TestConsoleSerialisesWriters(t *testing.T) {
+	out := newConsole()
+	var buffer strings.Builder
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				out.do(func() {
+					// a listing is written a piece at a time
+					buffer.WriteString("[")
+					buffer.WriteString("listing")
+					buffer.WriteString("]\n")
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	if len(lines) != 400 {
+		t.Fatalf("got %d lines, want 400", len(lines))
+	}
+	for _, line := range lines {
+		if line != "[listing]" {
+			t.Fatalf("interleaved output: %q", line)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// endpointValidationTimeout is how long Graph waits for the endpoint to answer a validation request.
+const endpointValidationTimeout = 10 * time.Second
+
+// checkEndpoint sends endpoint the validation request Graph sends when a subscription is
+// created, and checks the token is echoed back as Graph requires. It returns how long the
+// endpoint took to answer. The client should time out after endpointValidationTimeout,
+// like Graph does.
+func checkEndpoint(client *http.Client, endpoint string) (time.Duration, error) {
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Host == "" {
+		return 0, fmt.Errorf("ENDPOINT %q is not a URL", endpoint)
+	}
+	if parsed.Scheme != "https" {
+		return 0, fmt.Errorf("ENDPOINT %q is not HTTPS, Graph only delivers notifications over HTTPS", endpoint)
+	}
+
+	token := fmt.Sprintf("msgraph-cli endpoint test %d", time.Now().UnixNano())
+	query := parsed.Query()
+	query.Set("validationToken", token)
+	parsed.RawQuery = query.Encode()
+
+	started := time.Now()
+	response, err := client.Post(parsed.String(), "text/plain", nil)
+	latency := time.Since(started)
+	if err != nil {
+		return latency, describeEndpointError(err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, 4096))
+	if err != nil {
+		return latency, fmt.Errorf("failed to read the response: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		return latency, fmt.Errorf("endpoint answered %s, Graph requires 200 OK", response.Status)
+	}
+	if contentType := response.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "text/plain") {
+		return latency, fmt.Errorf("endpoint answered with Content-Type %q, Graph requires text/plain", contentType)
+	}
+	if string(body) != token {
+		return latency, fmt.Errorf("endpoint answered %q, not the validation token %q", body, token)
+	}
+	return latency, nil
+}
+
+// describeEndpointError explains the usual reasons a request to the endpoint fails.
+func describeEndpointError(err error) error {
+	var verification *tls.CertificateVerificationError
+	var hostname x509.HostnameError
+	var recordHeader tls.RecordHeaderError
+	switch {
+	case errors.As(err, &hostname):
+		return fmt.Errorf("TLS error, the certificate is not for this host: %v", err)
+	case errors.As(err, &verification):
+		return fmt.Errorf("TLS error, the certificate is not trusted: %v", err)
+	case errors.As(err, &recordHeader):
+		return fmt.Errorf("TLS error, the endpoint does not speak HTTPS: %v", err)
+	}
+	var timeout interface{ Timeout() bool }
+	if errors.As(err, &timeout) && timeout.Timeout() {
+		return fmt.Errorf("endpoint did not answer within %v: %v", endpointValidationTimeout, err)
+	}
+	return fmt.Errorf("endpoint is not reachable: %v", err)
+}
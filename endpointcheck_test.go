@@ -0,0 +1,52 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCheckEndpoint(t *testing.T) {
+	live := newLiveBookings(nil, newConsole())
+	queue := newNotificationQueue(1, nil, func(body []byte) { processNotification(body, live, nil, nil) })
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleGraphSubscription(w, r, queue, "")
+	}))
+	defer server.Close()
+
+	if _, err := checkEndpoint(server.Client(), server.URL+"/webhook"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestCheckEndpointProblems(t *testing.T) {
+	wrongAnswer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "received"})
+	}))
+	defer wrongAnswer.Close()
+	notFound := httptest.NewTLSServer(http.NotFoundHandler())
+	defer notFound.Close()
+
+	tests := []struct {
+		name     string
+		client   *http.Client
+		endpoint string
+		want     string
+	}{
+		{"not a url", wrongAnswer.Client(), "ngrok", "is not a URL"},
+		{"not https", wrongAnswer.Client(), "http://example.com/webhook", "is not HTTPS"},
+		{"untrusted certificate", http.DefaultClient, wrongAnswer.URL, "TLS error, the certificate is not trusted"},
+		{"not found", notFound.Client(), notFound.URL, "404 Not Found"},
+		{"token not echoed", wrongAnswer.Client(), wrongAnswer.URL, "Graph requires text/plain"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := checkEndpoint(test.client, test.endpoint)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("checkEndpoint() = %v, want an error containing %q", err, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+// envFiles are the .env file and the .env.local beside it that override it.
+type envFiles struct {
+	env   string
+	local string
+}
+
+// findEnvFiles returns the .env files to read. "DOTENV_PATH" names the .env file, or the
+// directory holding it. Otherwise the current directory and then each parent is searched for a
+// .env or .env.local, so the tool works when run from outside its own directory. found is
+// false when there are none.
+func findEnvFiles() (files envFiles, found bool, err error) {
+	if path := os.Getenv("DOTENV_PATH"); path != "" {
+		info, err := os.Stat(path)
+		if err != nil {
+			return envFiles{}, false, fmt.Errorf("DOTENV_PATH %q: %v", path, err)
+		}
+		if info.IsDir() {
+			return envFilesIn(path, ".env"), true, nil
+		}
+		return envFilesIn(filepath.Dir(path), filepath.Base(path)), true, nil
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return envFiles{}, false, err
+	}
+	dir, found = findEnvDir(dir)
+	return envFilesIn(dir, ".env"), found, nil
+}
+
+// findEnvDir returns the nearest of dir and its parents holding a .env or .env.local.
+func findEnvDir(dir string) (string, bool) {
+	for {
+		for _, name := range []string{".env", ".env.local"} {
+			if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				return dir, true
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+func envFilesIn(dir string, name string) envFiles {
+	return envFiles{env: filepath.Join(dir, name), local: filepath.Join(dir, ".env.local")}
+}
+
+// read returns the values in the .env file, overridden by those in .env.local. Either file may
+// be missing, but not both.
+func (f envFiles) read() (map[string]string, error) {
+	values := map[string]string{}
+	found := false
+	for _, path := range []string{f.env, f.local} {
+		fileValues, exists, err := readEnvFile(path)
+		if err != nil {
+			return nil, err
+		}
+		found = found || exists
+		for key, value := range fileValues {
+			values[key] = value
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("neither %s nor %s exists", f.env, f.local)
+	}
+	return values, nil
+}
+
+// readEnvFile reads the values in one .env file. A missing file is not an error, exists is false.
+func readEnvFile(path string) (values map[string]string, exists bool, err error) {
+	values, err = godotenv.Read(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	return values, true, nil
+}
+
+// envDiff is how one key is set in the .env file and in .env.local.
+type envDiff struct {
+	key            string
+	env, local     string
+	inEnv, inLocal bool
+}
+
+// diff compares the .env file with .env.local, reading them as read does, one entry per key
+// in either file, sorted by key.
+func (f envFiles) diff() ([]envDiff, error) {
+	env, envExists, err := readEnvFile(f.env)
+	if err != nil {
+		return nil, err
+	}
+	local, localExists, err := readEnvFile(f.local)
+	if err != nil {
+		return nil, err
+	}
+	if !envExists && !localExists {
+		return nil, fmt.Errorf("neither %s nor %s exists", f.env, f.local)
+	}
+
+	var diffs []envDiff
+	for key, value := range env {
+		localValue, inLocal := local[key]
+		diffs = append(diffs, envDiff{key: key, env: value, local: localValue, inEnv: true, inLocal: inLocal})
+	}
+	for key, value := range local {
+		if _, inEnv := env[key]; !inEnv {
+			diffs = append(diffs, envDiff{key: key, local: value, inLocal: true})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].key < diffs[j].key })
+	return diffs, nil
+}
+
+// effective returns the value the tool uses, .env.local's when both set the key.
+func (d envDiff) effective() string {
+	if d.inLocal {
+		return d.local
+	}
+	return d.env
+}
+
+// secretEnvKeyParts mark the keys whose values are masked when shown.
+var secretEnvKeyParts = []string{"SECRET", "PASSWORD", "TOKEN", "CLIENT_STATE"}
+
+// isSecretEnvKey reports whether a key holds a secret, such as CLIENT_SECRET.
+func isSecretEnvKey(key string) bool {
+	for _, part := range secretEnvKeyParts {
+		if strings.Contains(strings.ToUpper(key), part) {
+			return true
+		}
+	}
+	return false
+}
+
+// shownEnvValue returns a value as the diff shows it, masked for secrets.
+func shownEnvValue(key string, value string) string {
+	switch {
+	case value == "":
+		return "(empty)"
+	case isSecretEnvKey(key):
+		return "********"
+	}
+	return value
+}
+
+// writeEnvDiff writes each key with where its value comes from and the value the tool uses,
+// secrets masked. lookup tells which keys are already set in the environment, which the
+// files do not override.
+func writeEnvDiff(w io.Writer, files envFiles, diffs []envDiff, lookup func(string) (string, bool)) {
+	envName, localName := filepath.Base(files.env), filepath.Base(files.local)
+	fmt.Fprintf(w, "Comparing %s with %s, which takes precedence\n", files.env, files.local)
+	if len(diffs) == 0 {
+		fmt.Fprintln(w, "Neither file sets anything")
+		return
+	}
+
+	overridden := 0
+	for _, diff := range diffs {
+		var source string
+		switch {
+		case diff.inEnv && diff.inLocal && diff.env == diff.local:
+			source = fmt.Sprintf("both, %s repeats %s", localName, envName)
+		case diff.inEnv && diff.inLocal:
+			source = fmt.Sprintf("%s overrides %s (%s)", localName, envName, shownEnvValue(diff.key, diff.env))
+			overridden++
+		case diff.inLocal:
+			source = localName + " only"
+		default:
+			source = envName + " only"
+		}
+		fmt.Fprintf(w, "%s = %s\n  from %s\n", diff.key, shownEnvValue(diff.key, diff.effective()), source)
+		if value, set := lookup(diff.key); set {
+			fmt.Fprintf(w, "  but the environment sets it to %s, which both files leave as it is\n", shownEnvValue(diff.key, value))
+		}
+	}
+	fmt.Fprintf(w, "%d settings, %d overridden by %s\n", len(diffs), overridden, localName)
+}
+
+// envDiffCommand compares the .env files the tool would read.
+func envDiffCommand(w io.Writer) error {
+	files, found, err := findEnvFiles()
+	if err != nil {
+		return err
+	}
+	if !found {
+		return errors.New("no .env or .env.local found")
+	}
+	diffs, err := files.diff()
+	if err != nil {
+		return err
+	}
+	writeEnvDiff(w, files, diffs, os.LookupEnv)
+	return nil
+}
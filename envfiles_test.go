@@ -0,0 +1,115 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindEnvDir(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "bin", "linux")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".env.local"), []byte("ROOM_EMAIL=room@example.com\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dir, found := findEnvDir(nested)
+	if !found || dir != root {
+		t.Errorf("findEnvDir() = %q, %t, want %q, true", dir, found, root)
+	}
+}
+
+func TestEnvFilesRead(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "settings.env"), []byte("ROOM_EMAIL=room@example.com\nPORT=8080\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".env.local"), []byte("PORT=9090\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DOTENV_PATH", filepath.Join(dir, "settings.env"))
+
+	files, found, err := findEnvFiles()
+	if err != nil || !found {
+		t.Fatalf("findEnvFiles() = %v, %t, want found", err, found)
+	}
+	values, err := files.read()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if values["ROOM_EMAIL"] != "room@example.com" || values["PORT"] != "9090" {
+		t.Errorf("read() = %v, want .env.local to override PORT", values)
+	}
+}
+
+func TestFindEnvFilesMissingDotenvPath(t *testing.T) {
+	t.Setenv("DOTENV_PATH", filepath.Join(t.TempDir(), "missing.env"))
+	if _, _, err := findEnvFiles(); err == nil {
+		t.Error("findEnvFiles() accepted a DOTENV_PATH that does not exist")
+	}
+}
+
+func TestWriteEnvDiff(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("ROOM_EMAIL=room@example.com\nPORT=8080\nCLIENT_SECRET=from-env\nTENANT_ID=tenant\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".env.local"), []byte("PORT=9090\nCLIENT_SECRET=from-local\nTENANT_ID=tenant\nDEBUG=true\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	files := envFilesIn(dir, ".env")
+
+	diffs, err := files.diff()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var out strings.Builder
+	writeEnvDiff(&out, files, diffs, func(key string) (string, bool) {
+		if key == "ROOM_EMAIL" {
+			return "other@example.com", true
+		}
+		return "", false
+	})
+
+	for _, want := range []string{
+		"CLIENT_SECRET = ********\n  from .env.local overrides .env (********)\n",
+		"DEBUG = true\n  from .env.local only\n",
+		"PORT = 9090\n  from .env.local overrides .env (8080)\n",
+		"ROOM_EMAIL = room@example.com\n  from .env only\n  but the environment sets it to other@example.com",
+		"TENANT_ID = tenant\n  from both, .env.local repeats .env\n",
+		"5 settings, 2 overridden by .env.local\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("writeEnvDiff() wrote\n%s\nwant it to contain\n%s", out.String(), want)
+		}
+	}
+	if strings.Contains(out.String(), "from-") {
+		t.Errorf("writeEnvDiff() showed a secret:\n%s", out.String())
+	}
+	if strings.Index(out.String(), "CLIENT_SECRET") > strings.Index(out.String(), "DEBUG") {
+		t.Errorf("writeEnvDiff() did not sort the keys:\n%s", out.String())
+	}
+}
+
+func TestFromEnvFiles(t *testing.T) {
+	t.Setenv("ROOM_EMAIL", "room@example.com")
+	t.Setenv("PORT", "8080")
+	t.Setenv("TENANT_ID", "")
+	os.Unsetenv("TENANT_ID")
+	envFileKeys["PORT"] = true
+	t.Cleanup(func() { delete(envFileKeys, "PORT") })
+
+	if fromEnvFiles("ROOM_EMAIL") {
+		t.Error("fromEnvFiles(ROOM_EMAIL) = true, want a variable set in the environment kept")
+	}
+	if !fromEnvFiles("PORT") {
+		t.Error("fromEnvFiles(PORT) = false, want a variable set from the files overridden")
+	}
+	if !fromEnvFiles("TENANT_ID") {
+		t.Error("fromEnvFiles(TENANT_ID) = false, want an unset variable taken from the files")
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package main
+
+import "os"
+
+// lockFile does nothing where advisory locks are not available; run one process per log file there.
+func lockFile(file *os.File) error {
+	return nil
+}
+
+// unlockFile does nothing where advisory locks are not available.
+func unlockFile(file *os.File) error {
+	return nil
+}
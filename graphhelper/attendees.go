@@ -0,0 +1,61 @@
+package graphhelper
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// Attendee is an invitee of an event and whether they are required, optional or a resource.
+type Attendee struct {
+	Email string
+	Type  models.AttendeeType
+}
+
+// attendeeTypes maps the keywords accepted by ParseAttendees to Graph attendee types.
+var attendeeTypes = map[string]models.AttendeeType{
+	"required": models.REQUIRED_ATTENDEETYPE,
+	"optional": models.OPTIONAL_ATTENDEETYPE,
+	"resource": models.RESOURCE_ATTENDEETYPE,
+}
+
+// ParseAttendees parses a comma separated list of attendees such as
+// "alice@example.com:optional,bob@example.com". Each email may be followed by
+// ":required", ":optional" or ":resource"; attendees without a type are required.
+// Spaces around entries are ignored, and an empty spec means no attendees.
+func ParseAttendees(spec string) ([]Attendee, error) {
+	var attendees []Attendee
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		email, keyword, hasType := strings.Cut(entry, ":")
+		email, keyword = strings.TrimSpace(email), strings.TrimSpace(keyword)
+		attendeeType := models.REQUIRED_ATTENDEETYPE
+		if hasType {
+			var ok bool
+			attendeeType, ok = attendeeTypes[strings.ToLower(keyword)]
+			if !ok {
+				return nil, fmt.Errorf("attendee %q has unknown type %q, expected required, optional or resource", email, keyword)
+			}
+		}
+		if err := validateEmail("attendee", email); err != nil {
+			return nil, err
+		}
+		attendees = append(attendees, Attendee{Email: email, Type: attendeeType})
+	}
+	return attendees, nil
+}
+
+// newAttendee builds the Graph attendee for an email and attendee type.
+func newAttendee(email string, attendeeType models.AttendeeType) models.Attendeeable {
+	address := models.NewEmailAddress()
+	address.SetAddress(&email)
+	attendee := models.NewAttendee()
+	attendee.SetEmailAddress(address)
+	attendee.SetTypeEscaped(&attendeeType)
+	return attendee
+}
@@ -0,0 +1,43 @@
+package graphhelper
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+func TestParseAttendees(t *testing.T) {
+	tests := []struct {
+		spec string
+		want []Attendee
+	}{
+		{"", nil},
+		{"   ", nil},
+		{"alice@example.com", []Attendee{{"alice@example.com", models.REQUIRED_ATTENDEETYPE}}},
+		{"alice@example.com:optional, bob@example.com , projector@example.com : Resource", []Attendee{
+			{"alice@example.com", models.OPTIONAL_ATTENDEETYPE},
+			{"bob@example.com", models.REQUIRED_ATTENDEETYPE},
+			{"projector@example.com", models.RESOURCE_ATTENDEETYPE},
+		}},
+	}
+
+	for _, test := range tests {
+		got, err := ParseAttendees(test.spec)
+		if err != nil {
+			t.Errorf("ParseAttendees(%q) returned %v", test.spec, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ParseAttendees(%q) = %v, want %v", test.spec, got, test.want)
+		}
+	}
+}
+
+func TestParseAttendeesRejectsBadEntries(t *testing.T) {
+	for _, spec := range []string{"alice@example.com:maybe", "alice", "alice@example.com,bob"} {
+		if _, err := ParseAttendees(spec); err == nil {
+			t.Errorf("ParseAttendees(%q) returned no error", spec)
+		}
+	}
+}
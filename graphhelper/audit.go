@@ -0,0 +1,151 @@
+package graphhelper
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The outcomes recorded in the audit log.
+const (
+	AuditSuccess = "success"
+	AuditFailure = "failure"
+)
+
+// AuditRecord is the line written to "AUDIT_LOG" for each action that changes something in Graph.
+type AuditRecord struct {
+	Time     time.Time      `json:"time"`
+	Action   string         `json:"action"`
+	Actor    string         `json:"actor"`  // the app registration's client id
+	Target   string         `json:"target"` // the resource changed, such as a mailbox and event id
+	Outcome  string         `json:"outcome"`
+	Error    string         `json:"error,omitempty"`
+	Requests []AuditRequest `json:"requests,omitempty"`
+}
+
+// AuditRequest identifies a Graph request made for an action.
+type AuditRequest struct {
+	Method          string `json:"method"`
+	Status          int    `json:"status,omitempty"` // zero when Graph did not respond
+	ClientRequestId string `json:"clientRequestId"`
+	RequestId       string `json:"requestId,omitempty"`
+}
+
+// auditTrailKey is the context key of the auditTrail the request id transport adds each request to.
+type auditTrailKey struct{}
+
+// auditTrail collects the Graph requests made for one action.
+type auditTrail struct {
+	mu       sync.Mutex
+	requests []AuditRequest
+}
+
+func (t *auditTrail) add(record RequestRecord) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.requests = append(t.requests, AuditRequest{
+		Method:          record.Method,
+		Status:          record.Status,
+		ClientRequestId: record.ClientRequestId,
+		RequestId:       record.RequestId,
+	})
+}
+
+// auditTrailOf returns the trail the requests made with ctx are added to, or nil when they are not audited.
+func auditTrailOf(ctx context.Context) *auditTrail {
+	trail, _ := ctx.Value(auditTrailKey{}).(*auditTrail)
+	return trail
+}
+
+// audit starts recording an action for the audit log. The requests made with the returned context
+// are recorded with it, and the returned function writes the record with the action's outcome.
+// Without "AUDIT_LOG" nothing is recorded.
+func (g *GraphHelper) audit(ctx context.Context, action string, target string) (context.Context, func(error)) {
+	config := g.Config()
+	if config.AuditLog == "" {
+		return ctx, func(error) {}
+	}
+
+	trail := &auditTrail{}
+	ctx = context.WithValue(ctx, auditTrailKey{}, trail)
+	return ctx, func(err error) {
+		record := AuditRecord{
+			Time:    time.Now().UTC(),
+			Action:  action,
+			Actor:   g.auditActor(config),
+			Target:  target,
+			Outcome: AuditSuccess,
+		}
+		if err != nil {
+			record.Outcome, record.Error = AuditFailure, err.Error()
+		}
+		trail.mu.Lock()
+		record.Requests = trail.requests
+		trail.mu.Unlock()
+
+		if err := g.writeAuditRecord(config.AuditLog, record); err != nil {
+			log.Printf("Error writing audit log %s: %v", config.AuditLog, err)
+		}
+	}
+}
+
+// auditActor returns the client id of the app registration, or with "AUTH_MODE" default the
+// app or user the access token was issued to.
+func (g *GraphHelper) auditActor(config Config) string {
+	if config.AuthMode != AuthModeDefault && config.ClientId != "" {
+		return config.ClientId
+	}
+	token, err := g.appToken()
+	if err != nil {
+		return "unknown"
+	}
+	return orDefault(tokenActor(token.Token), "unknown")
+}
+
+// tokenActor reads who an access token was issued to: the app id of an app-only token, or the
+// user principal name of a user's token. It returns "" when the token cannot be read.
+func tokenActor(token string) string {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return ""
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return ""
+	}
+	var claims struct {
+		AppId string `json:"appid"`
+		Upn   string `json:"upn"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return ""
+	}
+	return orDefault(claims.Upn, claims.AppId)
+}
+
+// writeAuditRecord appends a record to the audit log as one JSON line. Each record is written
+// with a single append, so records from several goroutines do not interleave.
+func (g *GraphHelper) writeAuditRecord(path string, record AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	g.auditMu.Lock()
+	defer g.auditMu.Unlock()
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,102 @@
+package graphhelper
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAuditRecordsRequestsAndOutcome(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("request-id", "graph-request-1")
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	g := NewGraphHelper(&Config{AuditLog: path, ClientId: "client-1"})
+	transport := &requestIdTransport{next: http.DefaultTransport, history: g.requests}
+
+	ctx, audited := g.audit(context.Background(), "delete event", "room@example.com/events/event-1")
+	request, _ := http.NewRequestWithContext(ctx, "DELETE", server.URL, nil)
+	response, err := transport.RoundTrip(request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	response.Body.Close()
+	audited(errors.New("not found"))
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var record AuditRecord
+	if err := json.Unmarshal(content, &record); err != nil {
+		t.Fatalf("audit log is not a JSON line: %v\n%s", err, content)
+	}
+	if record.Action != "delete event" || record.Actor != "client-1" || record.Target != "room@example.com/events/event-1" {
+		t.Errorf("record = %+v", record)
+	}
+	if record.Outcome != AuditFailure || record.Error != "not found" {
+		t.Errorf("outcome = %q, %q; want a failure with the error", record.Outcome, record.Error)
+	}
+	if len(record.Requests) != 1 || record.Requests[0].RequestId != "graph-request-1" || record.Requests[0].Status != http.StatusNotFound {
+		t.Errorf("requests = %+v, want the DELETE with Graph's request id", record.Requests)
+	}
+	if info, err := os.Stat(path); err == nil && info.Mode().Perm() != 0600 {
+		t.Errorf("permissions = %o, want 600", info.Mode().Perm())
+	}
+}
+
+func TestAuditWithoutLogRecordsNothing(t *testing.T) {
+	g := NewGraphHelper(&Config{})
+	ctx, audited := g.audit(context.Background(), "delete subscription", "sub-1")
+	if auditTrailOf(ctx) != nil {
+		t.Error("audit() without AUDIT_LOG collected requests")
+	}
+	audited(nil)
+}
+
+func TestTokenActor(t *testing.T) {
+	token := func(claims string) string {
+		return "header." + base64.RawURLEncoding.EncodeToString([]byte(claims)) + ".signature"
+	}
+	tests := []struct {
+		token string
+		want  string
+	}{
+		{token(`{"appid":"app-1"}`), "app-1"},
+		{token(`{"appid":"cli-app","upn":"admin@example.com"}`), "admin@example.com"},
+		{"not a token", ""},
+	}
+	for _, test := range tests {
+		if got := tokenActor(test.token); got != test.want {
+			t.Errorf("tokenActor(%q) = %q, want %q", test.token, got, test.want)
+		}
+	}
+}
+
+func TestAuditLogAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	g := NewGraphHelper(&Config{AuditLog: path, ClientId: "client-1"})
+	for _, action := range []string{"create subscription", "renew subscription"} {
+		_, audited := g.audit(context.Background(), action, "/users/room@example.com/events")
+		audited(nil)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[1], `"outcome":"success"`) {
+		t.Errorf("audit log = %q, want two successful records", content)
+	}
+}
@@ -0,0 +1,172 @@
+package graphhelper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+)
+
+// AvailabilityInterval is the length of each slot in a room availability comparison.
+const AvailabilityInterval = 30 * time.Minute
+
+// availabilityStates are the meanings of the digits of a getSchedule availability view.
+var availabilityStates = map[byte]string{
+	'0': "free",
+	'1': "tentative",
+	'2': "busy",
+	'3': "away",
+	'4': "elsewhere",
+}
+
+// RoomAvailability is one room's free/busy view, one digit per AvailabilityInterval from the
+// start of the comparison, or the reason the room's free/busy could not be read.
+type RoomAvailability struct {
+	Room string
+	View string
+	Err  error
+}
+
+// state returns what the room is doing in the given slot, "?" when it is not known.
+func (r RoomAvailability) state(slot int) string {
+	if r.Err != nil || slot >= len(r.View) {
+		return "?"
+	}
+	if state, ok := availabilityStates[r.View[slot]]; ok {
+		return state
+	}
+	return "?"
+}
+
+// free reports whether the room is known to be free in the given slot.
+func (r RoomAvailability) free(slot int) bool {
+	return r.state(slot) == "free"
+}
+
+// AvailabilityComparison is the free/busy of two rooms over the same slots.
+type AvailabilityComparison struct {
+	Start time.Time
+	Slots int
+	Rooms [2]RoomAvailability
+}
+
+// CompareRoomAvailability reads the free/busy of two rooms between start and end with
+// getSchedule, called as "ORGANISER_EMAIL". A room that does not share its free/busy is
+// reported in its RoomAvailability instead of failing the comparison.
+//
+// Parameters:
+//   - ctx: The context of the request.
+//   - roomA: The email of the first room.
+//   - roomB: The email of the second room.
+//   - start: The start of the window.
+//   - end: The end of the window.
+//
+// Returns:
+//   - AvailabilityComparison: The rooms' availability in AvailabilityInterval slots.
+//   - error: An error object if the request fails, otherwise nil.
+func (g *GraphHelper) CompareRoomAvailability(ctx context.Context, roomA string, roomB string, start time.Time, end time.Time) (AvailabilityComparison, error) {
+
+	if err := validateEmail("room", roomA); err != nil {
+		return AvailabilityComparison{}, err
+	}
+	if err := validateEmail("room", roomB); err != nil {
+		return AvailabilityComparison{}, err
+	}
+	if !end.After(start) {
+		return AvailabilityComparison{}, errors.New("the end must be after the start")
+	}
+	client, err := g.graphClient()
+	if err != nil {
+		return AvailabilityComparison{}, err
+	}
+
+	interval := int32(AvailabilityInterval / time.Minute)
+	requestBody := users.NewItemCalendarGetSchedulePostRequestBody()
+	requestBody.SetSchedules([]string{roomA, roomB})
+	requestBody.SetStartTime(newDateTimeTimeZone(start))
+	requestBody.SetEndTime(newDateTimeTimeZone(end))
+	requestBody.SetAvailabilityViewInterval(&interval)
+
+	result, err := client.Users().ByUserId(g.Config().OrganiserEmail).Calendar().GetSchedule().PostAsGetSchedulePostResponse(ctx, requestBody, nil)
+	if err != nil {
+		return AvailabilityComparison{}, fmt.Errorf("failed to get schedules: %v", err)
+	}
+
+	slots := int((end.Sub(start) + AvailabilityInterval - 1) / AvailabilityInterval)
+	return newAvailabilityComparison(start, slots, [2]string{roomA, roomB}, result.GetValue()), nil
+}
+
+// newAvailabilityComparison matches the schedules Graph returned to the rooms asked for.
+func newAvailabilityComparison(start time.Time, slots int, rooms [2]string, schedules []models.ScheduleInformationable) AvailabilityComparison {
+	comparison := AvailabilityComparison{Start: start, Slots: slots}
+	for i, room := range rooms {
+		comparison.Rooms[i] = RoomAvailability{Room: room, Err: errors.New("no schedule returned")}
+		for _, schedule := range schedules {
+			if !sameResource(StringOrDefault(schedule.GetScheduleId(), ""), room) {
+				continue
+			}
+			if scheduleError := schedule.GetError(); scheduleError != nil {
+				comparison.Rooms[i].Err = fmt.Errorf("%s: %s", StringOrDefault(scheduleError.GetResponseCode(), "error"),
+					StringOrDefault(scheduleError.GetMessage(), "free/busy not available"))
+			} else {
+				comparison.Rooms[i] = RoomAvailability{Room: room, View: StringOrDefault(schedule.GetAvailabilityView(), "")}
+			}
+			break
+		}
+	}
+	return comparison
+}
+
+// Write prints the comparison as two columns of slots in location, marking the slots when
+// both rooms are free, followed by those slots joined into ranges.
+func (c AvailabilityComparison) Write(w io.Writer, location *time.Location) {
+	for _, room := range c.Rooms {
+		if room.Err != nil {
+			fmt.Fprintf(w, "Free/busy of %s is not available: %v\n", room.Room, room.Err)
+		}
+	}
+
+	fmt.Fprintf(w, "%-16s  %-24s  %s\n", "Time", c.Rooms[0].Room, c.Rooms[1].Room)
+	var ranges []string
+	rangeStart := -1
+	for slot := 0; slot <= c.Slots; slot++ {
+		bothFree := slot < c.Slots && c.Rooms[0].free(slot) && c.Rooms[1].free(slot)
+		if slot < c.Slots {
+			marker := ""
+			if bothFree {
+				marker = "  <- both free"
+			}
+			row := fmt.Sprintf("%-16s  %-24s  %-10s%s", c.slotStart(slot).In(location).Format(DisplayLayout),
+				c.Rooms[0].state(slot), c.Rooms[1].state(slot), marker)
+			fmt.Fprintln(w, strings.TrimRight(row, " "))
+		}
+
+		if bothFree && rangeStart < 0 {
+			rangeStart = slot
+		} else if !bothFree && rangeStart >= 0 {
+			ranges = append(ranges, c.slotStart(rangeStart).In(location).Format(DisplayLayout)+" - "+
+				c.slotStart(slot).In(location).Format(TimeOfDayLayout))
+			rangeStart = -1
+		}
+	}
+
+	fmt.Fprintln(w)
+	if len(ranges) == 0 {
+		fmt.Fprintln(w, "No time when both rooms are free")
+		return
+	}
+	fmt.Fprintln(w, "Both rooms free:")
+	for _, free := range ranges {
+		fmt.Fprintf(w, "  %s\n", free)
+	}
+}
+
+// slotStart returns when the given slot starts.
+func (c AvailabilityComparison) slotStart(slot int) time.Time {
+	return c.Start.Add(time.Duration(slot) * AvailabilityInterval)
+}
@@ -0,0 +1,82 @@
+package graphhelper
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+func TestNewAvailabilityComparison(t *testing.T) {
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	schedule := func(id string, view string) models.ScheduleInformationable {
+		information := models.NewScheduleInformation()
+		information.SetScheduleId(&id)
+		information.SetAvailabilityView(&view)
+		return information
+	}
+	denied := models.NewScheduleInformation()
+	deniedId, code, message := "room-b@example.com", "ErrorAccessDenied", "Access is denied"
+	denied.SetScheduleId(&deniedId)
+	denied.SetError(models.NewFreeBusyError())
+	denied.GetError().SetResponseCode(&code)
+	denied.GetError().SetMessage(&message)
+
+	comparison := newAvailabilityComparison(start, 4, [2]string{"room-a@example.com", "room-b@example.com"},
+		[]models.ScheduleInformationable{schedule("Room-A@example.com", "0200"), denied})
+
+	if comparison.Rooms[0].Err != nil || comparison.Rooms[0].View != "0200" {
+		t.Errorf("room A = %+v, want view 0200", comparison.Rooms[0])
+	}
+	if comparison.Rooms[1].Err == nil || comparison.Rooms[1].Err.Error() != "ErrorAccessDenied: Access is denied" {
+		t.Errorf("room B error = %v, want the access denied error", comparison.Rooms[1].Err)
+	}
+
+	missing := newAvailabilityComparison(start, 4, [2]string{"room-a@example.com", "room-c@example.com"},
+		[]models.ScheduleInformationable{schedule("room-a@example.com", "0000")})
+	if missing.Rooms[1].Err == nil {
+		t.Error("a room without a schedule has no error")
+	}
+}
+
+func TestAvailabilityComparisonWrite(t *testing.T) {
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		viewA string
+		viewB string
+		want  []string
+	}{
+		{"common free slots", "0020", "0100", []string{
+			"Fri 01 Mar 09:00  free                      free        <- both free",
+			"Fri 01 Mar 09:30  free                      tentative",
+			"Both rooms free:",
+			"  Fri 01 Mar 09:00 - 09:30",
+			"  Fri 01 Mar 10:30 - 11:00",
+		}},
+		{"never both free", "2222", "0000", []string{"No time when both rooms are free"}},
+		{"short view", "00", "0000", []string{
+			"Fri 01 Mar 10:00  ?                         free",
+			"  Fri 01 Mar 09:00 - 10:00",
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			comparison := AvailabilityComparison{Start: start, Slots: 4, Rooms: [2]RoomAvailability{
+				{Room: "room-a@example.com", View: test.viewA},
+				{Room: "room-b@example.com", View: test.viewB},
+			}}
+			var out bytes.Buffer
+			comparison.Write(&out, time.UTC)
+
+			for _, want := range test.want {
+				if !strings.Contains(out.String(), want+"\n") {
+					t.Errorf("output does not contain %q:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
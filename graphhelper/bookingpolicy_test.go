@@ -0,0 +1,47 @@
+package graphhelper
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+func TestRoomBookingPolicyValidate(t *testing.T) {
+	capacity := func(n int32) *int32 { return &n }
+	tests := []struct {
+		policy RoomBookingPolicy
+		want   string
+	}{
+		{RoomBookingPolicy{BookingType: "standard"}, ""},
+		{RoomBookingPolicy{BookingType: "reserved", Capacity: capacity(1)}, ""},
+		{RoomBookingPolicy{BookingType: "standard", Capacity: capacity(MaxRoomCapacity)}, ""},
+		{RoomBookingPolicy{BookingType: "unknown"}, "not one of standard, reserved"},
+		{RoomBookingPolicy{}, "not one of"},
+		{RoomBookingPolicy{BookingType: "standard", Capacity: capacity(0)}, "capacity 0 is not from 1 to 10000"},
+		{RoomBookingPolicy{BookingType: "standard", Capacity: capacity(MaxRoomCapacity + 1)}, "is not from 1"},
+	}
+	for _, test := range tests {
+		err := test.policy.Validate()
+		if test.want == "" && err != nil {
+			t.Errorf("Validate(%s) = %v, want no error", test.policy, err)
+		}
+		if test.want != "" && (err == nil || !strings.Contains(err.Error(), test.want)) {
+			t.Errorf("Validate(%s) = %v, want an error containing %q", test.policy, err, test.want)
+		}
+	}
+}
+
+func TestRoomBookingPolicyOf(t *testing.T) {
+	room := models.NewRoom()
+	if got := roomBookingPolicyOf(room).String(); got != "booking type -, capacity -" {
+		t.Errorf("policy of an empty room = %q", got)
+	}
+
+	reserved, capacity := models.RESERVED_BOOKINGTYPE, int32(12)
+	room.SetBookingType(&reserved)
+	room.SetCapacity(&capacity)
+	if got := roomBookingPolicyOf(room).String(); got != "booking type reserved, capacity 12" {
+		t.Errorf("policy = %q, want the booking type and capacity", got)
+	}
+}
@@ -0,0 +1,172 @@
+package graphhelper
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models/odataerrors"
+)
+
+// DefaultDeletionProgressFile is where DeleteAllSubscriptions records the subscriptions it has
+// dealt with, so an interrupted run can be resumed.
+const DefaultDeletionProgressFile = "subscriptions-deleted.txt"
+
+// ErrThrottled is returned when Graph still answers 429 Too Many Requests after the SDK has
+// retried the request as long as Retry-After asked.
+var ErrThrottled = errors.New("throttled by Graph")
+
+// DeleteAllSubscriptions deletes every subscription the app can see, one at a time. Each request
+// waits for the rate limiter, and a throttled request is retried by the SDK after the Retry-After
+// Graph gives. If Graph keeps throttling, the run stops instead of pressing on.
+//
+// Each subscription deleted, or found already gone, is appended to progressPath, and those are
+// skipped when the run is started again, so an interrupted or throttled run resumes where it
+// stopped. The file is removed once every subscription is deleted.
+//
+// Parameters:
+//   - ctx: Stops the deletions between subscriptions when cancelled.
+//   - w: Where the progress and the outcome for each subscription is written.
+//   - progressPath: The file recording the subscriptions dealt with.
+//
+// Returns:
+//   - int: The number of subscriptions deleted in this run.
+//   - error: An error object if listing fails, the run stopped or any deletion failed, otherwise nil.
+func (g *GraphHelper) DeleteAllSubscriptions(ctx context.Context, w io.Writer, progressPath string) (int, error) {
+
+	subscriptions, err := g.ListSubscriptions()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list subscriptions: %v", err)
+	}
+
+	var ids []string
+	for _, subscription := range subscriptions {
+		if subscription.GetId() != nil {
+			ids = append(ids, *subscription.GetId())
+		}
+	}
+	return deleteSubscriptionIds(ctx, w, ids, progressPath, g.deleteSubscription)
+}
+
+// deleteSubscriptionIds deletes the subscriptions not yet recorded in progressPath with
+// deleteFunc, recording each one dealt with. The file is removed once none are left.
+func deleteSubscriptionIds(ctx context.Context, w io.Writer, ids []string, progressPath string, deleteFunc func(context.Context, string) error) (int, error) {
+
+	processed, err := loadProcessedIds(progressPath)
+	if err != nil {
+		return 0, err
+	}
+	var remaining []string
+	for _, id := range ids {
+		if !processed[id] {
+			remaining = append(remaining, id)
+		}
+	}
+	if skipped := len(ids) - len(remaining); skipped > 0 {
+		fmt.Fprintf(w, "Resuming: %d subscriptions were dealt with by an earlier run\n", skipped)
+	}
+
+	deleted, failed := 0, 0
+	for i, id := range remaining {
+		if err := ctx.Err(); err != nil {
+			return deleted, fmt.Errorf("stopped after %d of %d subscriptions, run again to resume: %v", i, len(remaining), err)
+		}
+
+		err := deleteFunc(ctx, id)
+		switch {
+		case errors.Is(err, ErrThrottled):
+			fmt.Fprintf(w, "[%d/%d] %s  Throttled\n", i+1, len(remaining), id)
+			return deleted, fmt.Errorf("stopped after %d of %d subscriptions, run again later to resume: %w", i, len(remaining), err)
+		case errors.Is(err, ErrSubscriptionNotFound):
+			fmt.Fprintf(w, "[%d/%d] %s  Already gone\n", i+1, len(remaining), id)
+		case err != nil:
+			// not recorded, so the next run tries it again
+			fmt.Fprintf(w, "[%d/%d] %s  Delete failed: %v\n", i+1, len(remaining), id, err)
+			failed++
+			continue
+		default:
+			fmt.Fprintf(w, "[%d/%d] %s  Deleted\n", i+1, len(remaining), id)
+			deleted++
+		}
+		if err := appendProcessedId(progressPath, id); err != nil {
+			return deleted, fmt.Errorf("failed to record progress in %s: %v", progressPath, err)
+		}
+	}
+
+	if failed > 0 {
+		return deleted, fmt.Errorf("failed to delete %d of %d subscriptions, run again to retry them", failed, len(remaining))
+	}
+	if err := os.Remove(progressPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return deleted, fmt.Errorf("failed to remove %s: %v", progressPath, err)
+	}
+	return deleted, nil
+}
+
+// deleteSubscription deletes a subscription, telling a subscription that is already gone and
+// a request Graph is still throttling apart from other failures.
+func (g *GraphHelper) deleteSubscription(ctx context.Context, subscriptionId string) error {
+	client, err := g.graphClient()
+	if err != nil {
+		return err
+	}
+
+	ctx, audited := g.audit(ctx, "delete subscription", subscriptionId)
+	err = client.Subscriptions().BySubscriptionId(subscriptionId).Delete(ctx, nil)
+	audited(err)
+	if err != nil {
+		var odataError *odataerrors.ODataError
+		if errors.As(err, &odataError) {
+			switch odataError.GetStatusCode() {
+			case http.StatusNotFound:
+				return fmt.Errorf("%s: %w", subscriptionId, ErrSubscriptionNotFound)
+			case http.StatusTooManyRequests:
+				return fmt.Errorf("%s: %w", subscriptionId, ErrThrottled)
+			}
+		}
+		return fmt.Errorf("failed to delete subscription: %v", err)
+	}
+	return nil
+}
+
+// loadProcessedIds reads the subscription ids recorded in a progress file, one per line.
+// A missing file means none have been dealt with.
+func loadProcessedIds(path string) (map[string]bool, error) {
+	processed := map[string]bool{}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return processed, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if id := strings.TrimSpace(scanner.Text()); id != "" {
+			processed[id] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	return processed, nil
+}
+
+// appendProcessedId records a subscription id in a progress file.
+func appendProcessedId(path string, id string) error {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(file, id); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,94 @@
+package graphhelper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDeleteSubscriptionIdsResumes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultDeletionProgressFile)
+	ids := []string{"sub-1", "sub-2", "sub-3", "sub-4"}
+
+	// the first run is throttled on the third subscription
+	var calls []string
+	deleteFunc := func(ctx context.Context, id string) error {
+		calls = append(calls, id)
+		switch id {
+		case "sub-2":
+			return fmt.Errorf("%s: %w", id, ErrSubscriptionNotFound)
+		case "sub-3":
+			return fmt.Errorf("%s: %w", id, ErrThrottled)
+		}
+		return nil
+	}
+	var out strings.Builder
+	deleted, err := deleteSubscriptionIds(context.Background(), &out, ids, path, deleteFunc)
+	if !errors.Is(err, ErrThrottled) || deleted != 1 {
+		t.Fatalf("first run = %d, %v; want 1 deleted and stopped by throttling", deleted, err)
+	}
+	if !strings.Contains(out.String(), "[2/4] sub-2  Already gone") {
+		t.Errorf("first run wrote %q, want the progress of each subscription", out.String())
+	}
+
+	// the second run skips the two dealt with and finishes
+	calls = nil
+	out.Reset()
+	deleted, err = deleteSubscriptionIds(context.Background(), &out, ids, path, func(ctx context.Context, id string) error {
+		calls = append(calls, id)
+		return nil
+	})
+	if err != nil || deleted != 2 {
+		t.Fatalf("second run = %d, %v; want the 2 left deleted", deleted, err)
+	}
+	if strings.Join(calls, ",") != "sub-3,sub-4" {
+		t.Errorf("second run deleted %v, want only sub-3 and sub-4", calls)
+	}
+	if !strings.Contains(out.String(), "Resuming: 2 subscriptions") {
+		t.Errorf("second run wrote %q, want it to say it is resuming", out.String())
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("progress file left behind after every subscription was deleted: %v", err)
+	}
+}
+
+func TestDeleteSubscriptionIdsRetriesFailures(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultDeletionProgressFile)
+
+	var out strings.Builder
+	deleted, err := deleteSubscriptionIds(context.Background(), &out, []string{"sub-1", "sub-2"}, path, func(ctx context.Context, id string) error {
+		if id == "sub-1" {
+			return errors.New("forbidden")
+		}
+		return nil
+	})
+	if err == nil || deleted != 1 {
+		t.Fatalf("deleteSubscriptionIds() = %d, %v; want 1 deleted and the failure reported", deleted, err)
+	}
+
+	processed, err := loadProcessedIds(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if processed["sub-1"] || !processed["sub-2"] {
+		t.Errorf("processed = %v, want only sub-2 so the next run retries sub-1", processed)
+	}
+}
+
+func TestDeleteSubscriptionIdsStopsWhenCancelled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultDeletionProgressFile)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	deleted, err := deleteSubscriptionIds(ctx, &strings.Builder{}, []string{"sub-1"}, path, func(ctx context.Context, id string) error {
+		t.Errorf("deleted %s after the context was cancelled", id)
+		return nil
+	})
+	if err == nil || deleted != 0 || !strings.Contains(err.Error(), "run again to resume") {
+		t.Errorf("deleteSubscriptionIds() = %d, %v; want it stopped before deleting anything", deleted, err)
+	}
+}
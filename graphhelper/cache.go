@@ -0,0 +1,165 @@
+package graphhelper
+
+import (
+	"sync"
+	"time"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// DefaultCacheTTL is how long fetched rooms and users are reused before Graph is queried again.
+const DefaultCacheTTL = 5 * time.Minute
+
+// cache holds the most recent rooms, users, user ids and photos fetched from Graph.
+//
+// Eviction is lazy: an entry is never removed in the background, instead it is
+// treated as missing once it is older than the TTL and is replaced by the next
+// fetch. A TTL of zero disables caching entirely. InvalidateCache drops every
+// entry straight away.
+type cache struct {
+	mu sync.Mutex
+
+	ttl time.Duration
+	now func() time.Time // the clock, replaced in tests
+
+	rooms        []models.Roomable
+	roomsFetched time.Time
+
+	users        []models.Userable
+	usersFetched time.Time
+
+	photos  map[string]cachedPhoto  // by lower case user id
+	userIds map[string]cachedUserId // by lower case email
+}
+
+// cachedPhoto is a photo and when it was fetched.
+type cachedPhoto struct {
+	photo   []byte
+	fetched time.Time
+}
+
+// cachedUserId is the object id of a user and when it was looked up.
+type cachedUserId struct {
+	id      string
+	fetched time.Time
+}
+
+func newCache(ttl time.Duration) *cache {
+	return &cache{ttl: ttl, now: time.Now}
+}
+
+// fresh reports whether an entry fetched at the given time is still within the TTL.
+func (c *cache) fresh(fetched time.Time) bool {
+	return c.ttl > 0 && !fetched.IsZero() && c.now().Sub(fetched) < c.ttl
+}
+
+func (c *cache) getRooms() ([]models.Roomable, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.fresh(c.roomsFetched) {
+		return nil, false
+	}
+	return c.rooms, true
+}
+
+func (c *cache) setRooms(rooms []models.Roomable) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rooms = rooms
+	c.roomsFetched = c.now()
+}
+
+func (c *cache) getUsers() ([]models.Userable, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.fresh(c.usersFetched) {
+		return nil, false
+	}
+	return c.users, true
+}
+
+func (c *cache) setUsers(users []models.Userable) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.users = users
+	c.usersFetched = c.now()
+}
+
+func (c *cache) getPhoto(userId string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.photos[userId]
+	if !ok || !c.fresh(entry.fetched) {
+		return nil, false
+	}
+	return entry.photo, true
+}
+
+func (c *cache) setPhoto(userId string, photo []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.photos == nil {
+		c.photos = map[string]cachedPhoto{}
+	}
+	c.photos[userId] = cachedPhoto{photo: photo, fetched: c.now()}
+}
+
+func (c *cache) getUserId(email string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.userIds[email]
+	if !ok || !c.fresh(entry.fetched) {
+		return "", false
+	}
+	return entry.id, true
+}
+
+func (c *cache) setUserId(email string, id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.userIds == nil {
+		c.userIds = map[string]cachedUserId{}
+	}
+	c.userIds[email] = cachedUserId{id: id, fetched: c.now()}
+}
+
+func (c *cache) setTTL(ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ttl = ttl
+}
+
+func (c *cache) clearRooms() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rooms = nil
+	c.roomsFetched = time.Time{}
+}
+
+func (c *cache) clearUsers() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.users = nil
+	c.usersFetched = time.Time{}
+}
+
+func (c *cache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rooms = nil
+	c.roomsFetched = time.Time{}
+	c.users = nil
+	c.usersFetched = time.Time{}
+	c.photos = nil
+	c.userIds = nil
+}
+
+// SetCacheTTL changes how long rooms and users are cached. A TTL of zero disables the cache.
+func (g *GraphHelper) SetCacheTTL(ttl time.Duration) {
+	g.cache.setTTL(ttl)
+}
+
+// InvalidateCache discards all cached rooms, users, user ids and photos so the next listing is fetched from Graph.
+func (g *GraphHelper) InvalidateCache() {
+	g.cache.clear()
+}
@@ -0,0 +1,154 @@
+package graphhelper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// testClock is a clock for the cache that only moves when told to.
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func (c *testClock) advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestCache(ttl time.Duration) (*cache, *testClock) {
+	clock := &testClock{now: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)}
+	c := newCache(ttl)
+	c.now = clock.Now
+	return c, clock
+}
+
+func TestCacheFresh(t *testing.T) {
+	c, clock := newTestCache(5 * time.Minute)
+	c.setRooms([]models.Roomable{models.NewRoom()})
+	c.setUsers([]models.Userable{models.NewUser(), models.NewUser()})
+
+	clock.advance(5*time.Minute - time.Second)
+
+	rooms, ok := c.getRooms()
+	if !ok || len(rooms) != 1 {
+		t.Errorf("getRooms() = %d rooms, %t; want 1 room, true", len(rooms), ok)
+	}
+	users, ok := c.getUsers()
+	if !ok || len(users) != 2 {
+		t.Errorf("getUsers() = %d users, %t; want 2 users, true", len(users), ok)
+	}
+}
+
+func TestCacheExpired(t *testing.T) {
+	c, clock := newTestCache(5 * time.Minute)
+	c.setRooms([]models.Roomable{models.NewRoom()})
+	c.setUsers([]models.Userable{models.NewUser()})
+
+	clock.advance(5 * time.Minute)
+
+	if _, ok := c.getRooms(); ok {
+		t.Error("getRooms() returned rooms older than the TTL")
+	}
+	if _, ok := c.getUsers(); ok {
+		t.Error("getUsers() returned users older than the TTL")
+	}
+
+	// a new fetch is cached again
+	c.setRooms([]models.Roomable{models.NewRoom()})
+	if _, ok := c.getRooms(); !ok {
+		t.Error("getRooms() missed right after setRooms")
+	}
+}
+
+func TestCacheEmpty(t *testing.T) {
+	c, _ := newTestCache(5 * time.Minute)
+
+	if _, ok := c.getRooms(); ok {
+		t.Error("getRooms() hit on an empty cache")
+	}
+	if _, ok := c.getUsers(); ok {
+		t.Error("getUsers() hit on an empty cache")
+	}
+}
+
+func TestCacheZeroTTLDisablesCaching(t *testing.T) {
+	c, _ := newTestCache(0)
+	c.setRooms([]models.Roomable{models.NewRoom()})
+	c.setUsers([]models.Userable{models.NewUser()})
+
+	if _, ok := c.getRooms(); ok {
+		t.Error("getRooms() hit with a TTL of zero")
+	}
+	if _, ok := c.getUsers(); ok {
+		t.Error("getUsers() hit with a TTL of zero")
+	}
+}
+
+func TestCacheSetTTL(t *testing.T) {
+	c, clock := newTestCache(time.Minute)
+	c.setRooms([]models.Roomable{models.NewRoom()})
+	clock.advance(2 * time.Minute)
+
+	c.setTTL(time.Hour)
+	if _, ok := c.getRooms(); !ok {
+		t.Error("getRooms() missed after the TTL was raised")
+	}
+}
+
+func TestCacheClear(t *testing.T) {
+	c, _ := newTestCache(5 * time.Minute)
+	c.setRooms([]models.Roomable{models.NewRoom()})
+	c.setUsers([]models.Userable{models.NewUser()})
+
+	c.clear()
+
+	if _, ok := c.getRooms(); ok {
+		t.Error("getRooms() hit after clear()")
+	}
+	if _, ok := c.getUsers(); ok {
+		t.Error("getUsers() hit after clear()")
+	}
+}
+
+func TestCacheClearUsersKeepsRooms(t *testing.T) {
+	c, _ := newTestCache(5 * time.Minute)
+	c.setRooms([]models.Roomable{models.NewRoom()})
+	c.setUsers([]models.Userable{models.NewUser()})
+
+	c.clearUsers()
+
+	if _, ok := c.getUsers(); ok {
+		t.Error("getUsers() hit after clearUsers()")
+	}
+	if _, ok := c.getRooms(); !ok {
+		t.Error("getRooms() missed after clearUsers()")
+	}
+}
+
+func TestCachePhotos(t *testing.T) {
+	c, clock := newTestCache(5 * time.Minute)
+	c.setPhoto("room@example.com", []byte("jpeg"))
+
+	if photo, ok := c.getPhoto("room@example.com"); !ok || string(photo) != "jpeg" {
+		t.Errorf("getPhoto() = %q, %t; want the photo", photo, ok)
+	}
+	if _, ok := c.getPhoto("other@example.com"); ok {
+		t.Error("getPhoto() hit for another user")
+	}
+
+	clock.advance(5 * time.Minute)
+	if _, ok := c.getPhoto("room@example.com"); ok {
+		t.Error("getPhoto() returned a photo older than the TTL")
+	}
+
+	c.setPhoto("room@example.com", []byte("jpeg"))
+	c.clear()
+	if _, ok := c.getPhoto("room@example.com"); ok {
+		t.Error("getPhoto() hit after clear()")
+	}
+}
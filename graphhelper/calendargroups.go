@@ -0,0 +1,66 @@
+package graphhelper
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// ListCalendarGroups retrieves the calendar groups of the given user or room mailbox. Outlook
+// keeps every mailbox's calendars in groups, "My Calendars" by default, and users may add more.
+//
+// Parameters:
+//   - ctx: Cancels the request.
+//   - userId: The ID or email of the user whose calendar groups are listed.
+//
+// Returns:
+//   - []models.CalendarGroupable: The user's calendar groups.
+//   - error: An error object if the user id is invalid or the request fails, otherwise nil.
+func (g *GraphHelper) ListCalendarGroups(ctx context.Context, userId string) ([]models.CalendarGroupable, error) {
+	if err := validateUserId("user", userId); err != nil {
+		return nil, err
+	}
+
+	client, err := g.graphClient()
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := client.Users().ByUserId(userId).CalendarGroups().Get(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list calendar groups: %v", err)
+	}
+	return groups.GetValue(), nil
+}
+
+// ListGroupCalendars retrieves the calendars in one of a user's calendar groups.
+//
+// Parameters:
+//   - ctx: Cancels the request.
+//   - userId: The ID or email of the user who owns the calendar group.
+//   - groupId: The ID of the calendar group, from ListCalendarGroups.
+//
+// Returns:
+//   - []models.Calendarable: The calendars in the group, empty when the group has none.
+//   - error: An error object if an id is invalid or the request fails, otherwise nil.
+func (g *GraphHelper) ListGroupCalendars(ctx context.Context, userId string, groupId string) ([]models.Calendarable, error) {
+	if err := validateUserId("user", userId); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(groupId) == "" {
+		return nil, fmt.Errorf("no calendar group id given")
+	}
+
+	client, err := g.graphClient()
+	if err != nil {
+		return nil, err
+	}
+
+	calendars, err := client.Users().ByUserId(userId).CalendarGroups().ByCalendarGroupId(groupId).Calendars().Get(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the calendars in calendar group %s: %v", groupId, err)
+	}
+	return calendars.GetValue(), nil
+}
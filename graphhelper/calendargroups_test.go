@@ -0,0 +1,50 @@
+package graphhelper
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestCalendarGroupsRejectInvalidIds(t *testing.T) {
+	g := NewGraphHelper(&Config{})
+
+	tests := []struct {
+		name    string
+		call    func() error
+		wantErr string
+	}{
+		{
+			name: "groups of an invalid user",
+			call: func() error {
+				_, err := g.ListCalendarGroups(context.Background(), "not a user")
+				return err
+			},
+			wantErr: "is not a valid user id",
+		},
+		{
+			name: "calendars of an invalid user",
+			call: func() error {
+				_, err := g.ListGroupCalendars(context.Background(), "not a user", "AAMkAGI2")
+				return err
+			},
+			wantErr: "is not a valid user id",
+		},
+		{
+			name: "calendars of no group",
+			call: func() error {
+				_, err := g.ListGroupCalendars(context.Background(), "room@example.com", " ")
+				return err
+			},
+			wantErr: "no calendar group id",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package graphhelper
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// DeleteRoomEventsInRange deletes every event in a room's calendar between start and end,
+// such as the bookings left behind by testing. Each event is written to w with its outcome,
+// and a failure does not stop the remaining events from being deleted.
+//
+// Parameters:
+//   - ctx: Stops the deletions between events when cancelled.
+//   - w: Where each event and its outcome is written.
+//   - roomId: The ID or email of the room.
+//   - start: The start of the window.
+//   - end: The end of the window.
+//   - dryRun: Only list the events that would be deleted.
+//
+// Returns:
+//   - int: The number of events deleted, or that would be deleted on a dry run.
+//   - error: An error object if the calendar cannot be read or any deletion fails, otherwise nil.
+func (g *GraphHelper) DeleteRoomEventsInRange(ctx context.Context, w io.Writer, roomId string, start time.Time, end time.Time, dryRun bool) (int, error) {
+	if err := validateUserId("room", roomId); err != nil {
+		return 0, err
+	}
+	if !end.After(start) {
+		return 0, fmt.Errorf("the end %s is not after the start %s", formatQueryTime(end), formatQueryTime(start))
+	}
+
+	events, err := g.GetCalendarView(roomId, start, end)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get calendar view: %v", err)
+	}
+
+	deleted, failed := 0, 0
+	for _, event := range events {
+		if err := ctx.Err(); err != nil {
+			return deleted, fmt.Errorf("stopped after deleting %d of %d events: %v", deleted, len(events), err)
+		}
+
+		summary := g.NewEventSummary(event)
+		fmt.Fprintf(w, "%s  %s  %s\n", orDefault(summary.Start, "-"), orDefault(summary.Subject, "(no subject)"), orDefault(summary.Id, "-"))
+		if summary.Id == "" {
+			fmt.Fprintln(w, "  Skipped: the event has no id")
+			failed++
+			continue
+		}
+		if dryRun {
+			deleted++
+			continue
+		}
+		if err := g.DeleteEvent(roomId, summary.Id); err != nil {
+			fmt.Fprintf(w, "  Delete failed: %v\n", err)
+			failed++
+			continue
+		}
+		deleted++
+	}
+
+	if failed > 0 {
+		return deleted, fmt.Errorf("failed to delete %d of %d events", failed, deleted+failed)
+	}
+	return deleted, nil
+}
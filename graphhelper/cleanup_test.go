@@ -0,0 +1,34 @@
+package graphhelper
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDeleteRoomEventsInRangeRejectsBadArguments(t *testing.T) {
+	g := &GraphHelper{}
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		roomId string
+		end    time.Time
+		want   string
+	}{
+		{"invalid room", "not a room", start.Add(time.Hour), "is not a valid user id or email address"},
+		{"end before start", "room@example.com", start.Add(-time.Hour), "is not after the start"},
+		{"empty range", "room@example.com", start, "is not after the start"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			count, err := g.DeleteRoomEventsInRange(context.Background(), io.Discard, test.roomId, start, test.end, true)
+			if err == nil || !strings.Contains(err.Error(), test.want) || count != 0 {
+				t.Errorf("DeleteRoomEventsInRange() = %d, %v, want an error containing %q", count, err, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,476 @@
+package graphhelper
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+)
+
+// DefaultWebhookBindRetries is how many times binding the webhook port is retried before giving up.
+const DefaultWebhookBindRetries = 5
+
+// DefaultWebhookBufferSize is how many webhook notifications can wait to be processed.
+const DefaultWebhookBufferSize = 100
+
+// The values accepted for WEBHOOK_OVERFLOW, what happens to a notification arriving while the
+// buffer is full: it is dropped and counted, or written to "WEBHOOK_OVERFLOW_FILE".
+const (
+	WebhookOverflowDrop    = "drop"
+	WebhookOverflowPersist = "persist"
+)
+
+// DefaultWebhookOverflowFile is where notifications that overflow the buffer are written.
+const DefaultWebhookOverflowFile = "webhook-overflow.jsonl"
+
+// DefaultGraphTimeout is how long a single Graph request may take before it is abandoned.
+const DefaultGraphTimeout = 60 * time.Second
+
+// DefaultRateLimit is how many Graph requests are sent per second at most.
+const DefaultRateLimit = 10
+
+// DefaultRateBurst is how many Graph requests may be sent at once after a quiet spell.
+const DefaultRateBurst = 10
+
+// DefaultUsersPageSize is how many users are requested per page, MaxUsersPageSize the most Graph allows.
+const (
+	DefaultUsersPageSize = 100
+	MaxUsersPageSize     = 999
+)
+
+// UserOrderFields are the user properties user listings can be sorted by. Graph rejects
+// sorting by other properties: in particular $orderby id is not supported, so users cannot
+// be listed in id order.
+var UserOrderFields = []string{"displayName", "userPrincipalName", "mail"}
+
+// The values accepted for AUTH_MODE: sign in as the app registration with its client secret,
+// or with whatever azidentity.DefaultAzureCredential finds first.
+const (
+	AuthModeSecret  = "secret"
+	AuthModeDefault = "default"
+)
+
+// DefaultEventSubject and DefaultEventDuration are used for events created from the menu
+// when no other subject or length is given.
+const (
+	DefaultEventSubject  = "Room booking"
+	DefaultEventDuration = 30 * time.Minute
+)
+
+// DefaultSubscriptionTLSVersion is the latest TLS version declared for the notification endpoint.
+const DefaultSubscriptionTLSVersion = "v1_2"
+
+// subscriptionTLSVersions are the values Graph accepts for latestSupportedTlsVersion.
+var subscriptionTLSVersions = []string{"v1_0", "v1_1", "v1_2", "v1_3"}
+
+// nationalCloud is where an app registration signs in, which Graph endpoint it calls and
+// the domain of its Key Vaults.
+type nationalCloud struct {
+	authority   cloud.Configuration
+	graphHost   string
+	vaultDomain string
+}
+
+// clouds are the values accepted for AZURE_CLOUD.
+var clouds = map[string]nationalCloud{
+	"public": {cloud.AzurePublic, "graph.microsoft.com", "vault.azure.net"},
+	"usgov":  {cloud.AzureGovernment, "graph.microsoft.us", "vault.usgovcloudapi.net"},
+	"china":  {cloud.AzureChina, "microsoftgraph.chinacloudapi.cn", "vault.azure.cn"},
+}
+
+// Config holds every setting the tool reads from the environment (usually via .env and .env.local).
+type Config struct {
+	// Credentials of the app registration
+	AuthMode     string // AUTH_MODE, AuthModeSecret or AuthModeDefault
+	ClientId     string // CLIENT_ID, not needed with AuthModeDefault
+	ClientSecret string // CLIENT_SECRET, not needed when read from Key Vault or with AuthModeDefault
+	TenantId     string // TENANT_ID, optional with AuthModeDefault
+	GraphScope   string // GRAPH_SCOPE, the ".default" scope of the cloud's Graph endpoint when empty
+
+	// Key Vault holding the client secret, read with the managed identity of the host
+	KeyVaultURL string // KEYVAULT_URL, empty uses CLIENT_SECRET
+	SecretName  string // SECRET_NAME, the Key Vault secret holding the client secret
+
+	OrganiserEmail string // ORGANISER_EMAIL
+	RoomEmail      string // ROOM_EMAIL, replaced by the room chosen from the menu
+
+	// Webhook server and subscriptions
+	Endpoint                  string        // ENDPOINT, the public notification URL
+	Port                      string        // PORT, or the port of ENDPOINT, stored as a listen address such as ":8080"
+	WebhookBindRetries        int           // WEBHOOK_BIND_RETRIES
+	WebhookTLSCert            string        // WEBHOOK_TLS_CERT
+	WebhookTLSKey             string        // WEBHOOK_TLS_KEY
+	WebhookLogFile            string        // WEBHOOK_LOG_FILE
+	WebhookClientState        string        // WEBHOOK_CLIENT_STATE, sent with new subscriptions and required in their notifications
+	WebhookBufferSize         int           // WEBHOOK_BUFFER_SIZE, notifications waiting to be processed
+	WebhookOverflow           string        // WEBHOOK_OVERFLOW, WebhookOverflowDrop or WebhookOverflowPersist
+	WebhookOverflowFile       string        // WEBHOOK_OVERFLOW_FILE
+	StartupSubscribe          bool          // STARTUP_SUBSCRIBE
+	SubscriptionRenewInterval time.Duration // SUBSCRIPTION_RENEW_INTERVAL, zero disables automatic renewal
+	SubscriptionTLSVersion    string        // SUBSCRIPTION_TLS_VERSION, the latest TLS version ENDPOINT supports
+	SubscriptionCheckInterval time.Duration // SUBSCRIPTION_CHECK_INTERVAL, zero disables the subscription health check
+	SubscriptionExpiryWarning time.Duration // SUBSCRIPTION_EXPIRY_WARNING, how close to expiry the health check warns
+
+	// Rich notifications, which include the changed event encrypted with this certificate
+	RichNotifications       bool   // RICH_NOTIFICATIONS
+	RichNotificationsCert   string // RICH_NOTIFICATIONS_CERT, a PEM certificate file
+	RichNotificationsKey    string // RICH_NOTIFICATIONS_KEY, the certificate's PEM RSA private key file
+	RichNotificationsCertId string // RICH_NOTIFICATIONS_CERT_ID
+
+	RoomResponseTimeout time.Duration // ROOM_RESPONSE_TIMEOUT, how long to wait for a room to accept a booking, zero skips the check
+	EventSubject        string        // DEFAULT_SUBJECT, the subject of events created from the menu
+	EventDuration       time.Duration // DEFAULT_DURATION, the length of events created from the menu
+	ShowPhotos          bool          // SHOW_PHOTOS, allows fetching user and room photos
+	HidePrivateEvents   bool          // HIDE_PRIVATE_EVENTS, shows private and confidential events without their subject
+	ShowRawJSON         bool          // SHOW_RAW_JSON, also shows the raw JSON of the object in detail views
+	AuditLog            string        // AUDIT_LOG, a JSON lines file recording every change made in Graph, empty records nothing
+
+	CacheTTL      time.Duration  // CACHE_TTL, zero disables the cache
+	GraphTimeout  time.Duration  // GRAPH_TIMEOUT, zero waits forever
+	RateLimit     float64        // RATE_LIMIT, Graph requests per second
+	RateBurst     int            // RATE_BURST, Graph requests sent at once after a quiet spell
+	Cloud         string         // AZURE_CLOUD, one of public, usgov or china
+	UsersPageSize int            // USERS_PAGE_SIZE, users requested per page
+	UsersOrderBy  string         // USERS_ORDER_BY, one of UserOrderFields
+	TimeZone      *time.Location // TIME_ZONE, the IANA or Windows zone events are shown in, the system zone by default
+}
+
+// validPort reports whether value is a TCP port number that can be listened on.
+func validPort(value string) bool {
+	port, err := strconv.Atoi(value)
+	return err == nil && port >= 1 && port <= 65535
+}
+
+// endpointPort returns the port in a notification URL, or the default port of its scheme.
+func endpointPort(endpoint string) (string, error) {
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Host == "" {
+		return "", fmt.Errorf("ENDPOINT %q is not a URL to take the port from", endpoint)
+	}
+	port := parsed.Port()
+	if port == "" {
+		switch strings.ToLower(parsed.Scheme) {
+		case "https":
+			port = "443"
+		case "http":
+			port = "80"
+		default:
+			return "", fmt.Errorf("ENDPOINT %q has no port and is not http or https", endpoint)
+		}
+	}
+	if !validPort(port) {
+		return "", fmt.Errorf("ENDPOINT %q does not have a valid port", endpoint)
+	}
+	return port, nil
+}
+
+// vaultScope returns the scope of a token for the Key Vaults of the configured cloud.
+func (c Config) vaultScope() string {
+	return "https://" + clouds[c.Cloud].vaultDomain + "/.default"
+}
+
+// graphScope returns the scope Graph access tokens are requested for.
+func (c Config) graphScope() string {
+	if c.GraphScope != "" {
+		return c.GraphScope
+	}
+	return "https://" + c.graphHost() + "/.default"
+}
+
+// graphHost returns the host name of the Graph endpoint of the configured cloud.
+func (c Config) graphHost() string {
+	return clouds[c.Cloud].graphHost
+}
+
+// LoadConfig reads the configuration from the process environment.
+// See LoadConfigFrom for the validation performed.
+func LoadConfig() (*Config, error) {
+	return LoadConfigFrom(os.Getenv)
+}
+
+// LoadConfigFrom reads the configuration using getenv to look up each variable.
+// Every problem found (missing required settings, invalid emails, durations or numbers)
+// is reported together in the returned error.
+func LoadConfigFrom(getenv func(string) string) (*Config, error) {
+	var problems []string
+
+	required := func(key string) string {
+		value := getenv(key)
+		if value == "" {
+			problems = append(problems, key+" is not set")
+		}
+		return value
+	}
+	email := func(key string) string {
+		value := required(key)
+		if value != "" && !IsValidEmail(value) {
+			problems = append(problems, fmt.Sprintf("%s %q is not a valid email address", key, value))
+		}
+		return value
+	}
+	duration := func(key string, fallback time.Duration) time.Duration {
+		value := getenv(key)
+		if value == "" {
+			return fallback
+		}
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed < 0 {
+			problems = append(problems, fmt.Sprintf("%s %q is not a valid duration", key, value))
+			return fallback
+		}
+		return parsed
+	}
+
+	config := &Config{
+		AuthMode:                  AuthModeSecret,
+		ClientId:                  getenv("CLIENT_ID"),
+		ClientSecret:              getenv("CLIENT_SECRET"),
+		KeyVaultURL:               getenv("KEYVAULT_URL"),
+		SecretName:                getenv("SECRET_NAME"),
+		TenantId:                  getenv("TENANT_ID"),
+		GraphScope:                getenv("GRAPH_SCOPE"),
+		OrganiserEmail:            email("ORGANISER_EMAIL"),
+		RoomEmail:                 email("ROOM_EMAIL"),
+		Endpoint:                  required("ENDPOINT"),
+		WebhookBindRetries:        DefaultWebhookBindRetries,
+		WebhookTLSCert:            getenv("WEBHOOK_TLS_CERT"),
+		WebhookTLSKey:             getenv("WEBHOOK_TLS_KEY"),
+		WebhookLogFile:            getenv("WEBHOOK_LOG_FILE"),
+		AuditLog:                  getenv("AUDIT_LOG"),
+		WebhookClientState:        getenv("WEBHOOK_CLIENT_STATE"),
+		WebhookBufferSize:         DefaultWebhookBufferSize,
+		WebhookOverflow:           WebhookOverflowDrop,
+		WebhookOverflowFile:       DefaultWebhookOverflowFile,
+		SubscriptionRenewInterval: duration("SUBSCRIPTION_RENEW_INTERVAL", 0),
+		SubscriptionTLSVersion:    DefaultSubscriptionTLSVersion,
+		SubscriptionCheckInterval: duration("SUBSCRIPTION_CHECK_INTERVAL", 0),
+		SubscriptionExpiryWarning: duration("SUBSCRIPTION_EXPIRY_WARNING", DefaultSubscriptionExpiryWarning),
+		RichNotificationsCert:     getenv("RICH_NOTIFICATIONS_CERT"),
+		RichNotificationsKey:      getenv("RICH_NOTIFICATIONS_KEY"),
+		RichNotificationsCertId:   getenv("RICH_NOTIFICATIONS_CERT_ID"),
+		RoomResponseTimeout:       duration("ROOM_RESPONSE_TIMEOUT", DefaultRoomResponseTimeout),
+		EventSubject:              DefaultEventSubject,
+		EventDuration:             duration("DEFAULT_DURATION", DefaultEventDuration),
+		CacheTTL:                  duration("CACHE_TTL", DefaultCacheTTL),
+		GraphTimeout:              duration("GRAPH_TIMEOUT", DefaultGraphTimeout),
+		RateLimit:                 DefaultRateLimit,
+		RateBurst:                 DefaultRateBurst,
+		Cloud:                     "public",
+		UsersPageSize:             DefaultUsersPageSize,
+		UsersOrderBy:              "displayName",
+		TimeZone:                  time.Local,
+	}
+
+	// Without PORT, listen on the port ENDPOINT is reached on. The server still speaks plain
+	// HTTP unless given a certificate, as an https ENDPOINT is usually a proxy or tunnel.
+	if value := getenv("PORT"); value != "" {
+		if !validPort(value) {
+			problems = append(problems, fmt.Sprintf("PORT %q is not a valid port", value))
+		}
+		config.Port = ":" + value
+	} else if config.Endpoint != "" {
+		port, err := endpointPort(config.Endpoint)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("PORT is not set and %v", err))
+		}
+		config.Port = ":" + port
+	}
+
+	// DefaultAzureCredential finds its own credentials, the app registration is only needed otherwise
+	if value := getenv("AUTH_MODE"); value != "" {
+		if mode := strings.ToLower(value); mode != AuthModeSecret && mode != AuthModeDefault {
+			problems = append(problems, fmt.Sprintf("AUTH_MODE %q is not one of %s or %s", value, AuthModeSecret, AuthModeDefault))
+		} else {
+			config.AuthMode = mode
+		}
+	}
+	if config.GraphScope != "" {
+		if parsed, err := url.Parse(config.GraphScope); err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+			problems = append(problems, fmt.Sprintf("GRAPH_SCOPE %q is not an https URL", config.GraphScope))
+		}
+	}
+
+	// The client secret comes from Key Vault when one is configured
+	if config.AuthMode == AuthModeSecret {
+		if config.ClientId == "" {
+			problems = append(problems, "CLIENT_ID is not set")
+		}
+		if config.TenantId == "" {
+			problems = append(problems, "TENANT_ID is not set")
+		}
+		if config.KeyVaultURL != "" {
+			if parsed, err := url.Parse(config.KeyVaultURL); err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+				problems = append(problems, fmt.Sprintf("KEYVAULT_URL %q is not an https URL", config.KeyVaultURL))
+			}
+			if config.SecretName == "" {
+				problems = append(problems, "SECRET_NAME is not set")
+			} else if !secretNamePattern.MatchString(config.SecretName) {
+				problems = append(problems, fmt.Sprintf("SECRET_NAME %q is not a valid Key Vault secret name", config.SecretName))
+			}
+		} else if config.ClientSecret == "" {
+			problems = append(problems, "CLIENT_SECRET is not set")
+		}
+	}
+
+	// Graph limits clientState to 128 characters
+	if len(config.WebhookClientState) > 128 {
+		problems = append(problems, "WEBHOOK_CLIENT_STATE is longer than 128 characters")
+	}
+
+	if value := getenv("DEFAULT_SUBJECT"); strings.TrimSpace(value) != "" {
+		config.EventSubject = strings.TrimSpace(value)
+	}
+	if value := getenv("DEFAULT_DURATION"); value != "" && config.EventDuration == 0 {
+		problems = append(problems, fmt.Sprintf("DEFAULT_DURATION %q is not a positive duration", value))
+		config.EventDuration = DefaultEventDuration
+	}
+
+	if value := getenv("AZURE_CLOUD"); value != "" {
+		if _, ok := clouds[strings.ToLower(value)]; !ok {
+			problems = append(problems, fmt.Sprintf("AZURE_CLOUD %q is not one of public, usgov or china", value))
+		} else {
+			config.Cloud = strings.ToLower(value)
+		}
+	}
+
+	if value := getenv("SUBSCRIPTION_TLS_VERSION"); value != "" {
+		if !slices.Contains(subscriptionTLSVersions, value) {
+			problems = append(problems, fmt.Sprintf("SUBSCRIPTION_TLS_VERSION %q is not one of %s", value, strings.Join(subscriptionTLSVersions, ", ")))
+		} else {
+			config.SubscriptionTLSVersion = value
+		}
+	}
+
+	if value := getenv("TIME_ZONE"); value != "" {
+		location, err := LoadTimeZone(value)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("TIME_ZONE %q is not a known time zone", value))
+		} else {
+			config.TimeZone = location
+		}
+	}
+
+	if value := getenv("WEBHOOK_BIND_RETRIES"); value != "" {
+		retries, err := strconv.Atoi(value)
+		if err != nil || retries < 0 {
+			problems = append(problems, fmt.Sprintf("WEBHOOK_BIND_RETRIES %q is not a valid count", value))
+		} else {
+			config.WebhookBindRetries = retries
+		}
+	}
+
+	if value := getenv("WEBHOOK_BUFFER_SIZE"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size <= 0 {
+			problems = append(problems, fmt.Sprintf("WEBHOOK_BUFFER_SIZE %q is not a positive count", value))
+		} else {
+			config.WebhookBufferSize = size
+		}
+	}
+
+	if value := getenv("WEBHOOK_OVERFLOW"); value != "" {
+		if policy := strings.ToLower(value); policy != WebhookOverflowDrop && policy != WebhookOverflowPersist {
+			problems = append(problems, fmt.Sprintf("WEBHOOK_OVERFLOW %q is not one of %s or %s", value, WebhookOverflowDrop, WebhookOverflowPersist))
+		} else {
+			config.WebhookOverflow = policy
+		}
+	}
+	if value := getenv("WEBHOOK_OVERFLOW_FILE"); value != "" {
+		config.WebhookOverflowFile = value
+	}
+
+	if value := getenv("RATE_LIMIT"); value != "" {
+		limit, err := strconv.ParseFloat(value, 64)
+		if err != nil || !(limit > 0) || math.IsInf(limit, 1) {
+			problems = append(problems, fmt.Sprintf("RATE_LIMIT %q is not a positive number of requests per second", value))
+		} else {
+			config.RateLimit = limit
+		}
+	}
+
+	if value := getenv("RATE_BURST"); value != "" {
+		burst, err := strconv.Atoi(value)
+		if err != nil || burst <= 0 {
+			problems = append(problems, fmt.Sprintf("RATE_BURST %q is not a positive count", value))
+		} else {
+			config.RateBurst = burst
+		}
+	}
+
+	if value := getenv("USERS_PAGE_SIZE"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size < 1 || size > MaxUsersPageSize {
+			problems = append(problems, fmt.Sprintf("USERS_PAGE_SIZE %q is not a page size from 1 to %d", value, MaxUsersPageSize))
+		} else {
+			config.UsersPageSize = size
+		}
+	}
+
+	if value := getenv("USERS_ORDER_BY"); value != "" {
+		if !slices.Contains(UserOrderFields, value) {
+			problems = append(problems, fmt.Sprintf("USERS_ORDER_BY %q is not one of %s", value, strings.Join(UserOrderFields, ", ")))
+		} else {
+			config.UsersOrderBy = value
+		}
+	}
+
+	if value := getenv("STARTUP_SUBSCRIBE"); value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("STARTUP_SUBSCRIBE %q is not a valid boolean", value))
+		}
+		config.StartupSubscribe = enabled
+	}
+
+	if value := getenv("SHOW_PHOTOS"); value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("SHOW_PHOTOS %q is not a valid boolean", value))
+		}
+		config.ShowPhotos = enabled
+	}
+
+	if value := getenv("HIDE_PRIVATE_EVENTS"); value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("HIDE_PRIVATE_EVENTS %q is not a valid boolean", value))
+		}
+		config.HidePrivateEvents = enabled
+	}
+
+	if value := getenv("SHOW_RAW_JSON"); value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("SHOW_RAW_JSON %q is not a valid boolean", value))
+		}
+		config.ShowRawJSON = enabled
+	}
+
+	if value := getenv("RICH_NOTIFICATIONS"); value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("RICH_NOTIFICATIONS %q is not a valid boolean", value))
+		}
+		config.RichNotifications = enabled
+	}
+	if config.RichNotifications {
+		if config.RichNotificationsCert == "" || config.RichNotificationsKey == "" {
+			problems = append(problems, "RICH_NOTIFICATIONS needs RICH_NOTIFICATIONS_CERT and RICH_NOTIFICATIONS_KEY")
+		}
+		if config.RichNotificationsCertId == "" {
+			config.RichNotificationsCertId = "msgraph-cli"
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, errors.New(strings.Join(problems, "; "))
+	}
+	return config, nil
+}
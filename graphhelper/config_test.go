@@ -0,0 +1,496 @@
+package graphhelper
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// validEnv returns a complete, valid set of settings that each test can change.
+func validEnv() map[string]string {
+	return map[string]string{
+		"CLIENT_ID":       "client",
+		"CLIENT_SECRET":   "secret",
+		"TENANT_ID":       "tenant",
+		"ORGANISER_EMAIL": "organiser@example.com",
+		"ROOM_EMAIL":      "room@example.com",
+		"ENDPOINT":        "https://example.com/webhook",
+		"PORT":            "8080",
+	}
+}
+
+func loadFrom(env map[string]string) (*Config, error) {
+	return LoadConfigFrom(func(key string) string { return env[key] })
+}
+
+func TestLoadConfigFromDefaults(t *testing.T) {
+	config, err := loadFrom(validEnv())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.Port != ":8080" {
+		t.Errorf("Port = %q, want %q", config.Port, ":8080")
+	}
+	if config.CacheTTL != DefaultCacheTTL {
+		t.Errorf("CacheTTL = %v, want %v", config.CacheTTL, DefaultCacheTTL)
+	}
+	if config.GraphTimeout != DefaultGraphTimeout {
+		t.Errorf("GraphTimeout = %v, want %v", config.GraphTimeout, DefaultGraphTimeout)
+	}
+	if config.WebhookBindRetries != DefaultWebhookBindRetries {
+		t.Errorf("WebhookBindRetries = %d, want %d", config.WebhookBindRetries, DefaultWebhookBindRetries)
+	}
+	if config.WebhookBufferSize != DefaultWebhookBufferSize || config.WebhookOverflow != WebhookOverflowDrop {
+		t.Errorf("webhook buffer = %d, %q, want %d, %q", config.WebhookBufferSize, config.WebhookOverflow, DefaultWebhookBufferSize, WebhookOverflowDrop)
+	}
+	if config.Cloud != "public" || config.graphHost() != "graph.microsoft.com" {
+		t.Errorf("Cloud = %q (%s), want public", config.Cloud, config.graphHost())
+	}
+	if config.TimeZone != time.Local {
+		t.Errorf("TimeZone = %v, want the system time zone", config.TimeZone)
+	}
+	if config.StartupSubscribe {
+		t.Error("StartupSubscribe should default to false")
+	}
+	if config.HidePrivateEvents {
+		t.Error("HidePrivateEvents should default to false")
+	}
+	if config.SubscriptionTLSVersion != "v1_2" {
+		t.Errorf("SubscriptionTLSVersion = %q, want v1_2", config.SubscriptionTLSVersion)
+	}
+	if config.SubscriptionCheckInterval != 0 || config.SubscriptionExpiryWarning != DefaultSubscriptionExpiryWarning {
+		t.Errorf("subscription check = %v warning at %v, want disabled warning at %v", config.SubscriptionCheckInterval, config.SubscriptionExpiryWarning, DefaultSubscriptionExpiryWarning)
+	}
+	if config.EventSubject != DefaultEventSubject || config.EventDuration != DefaultEventDuration {
+		t.Errorf("event = %q for %v, want %q for %v", config.EventSubject, config.EventDuration, DefaultEventSubject, DefaultEventDuration)
+	}
+	if config.RoomResponseTimeout != DefaultRoomResponseTimeout {
+		t.Errorf("RoomResponseTimeout = %v, want %v", config.RoomResponseTimeout, DefaultRoomResponseTimeout)
+	}
+	if config.RateLimit != DefaultRateLimit || config.RateBurst != DefaultRateBurst {
+		t.Errorf("RateLimit, RateBurst = %v, %d, want %v, %d", config.RateLimit, config.RateBurst, DefaultRateLimit, DefaultRateBurst)
+	}
+}
+
+func TestLoadConfigFromOptionalSettings(t *testing.T) {
+	env := validEnv()
+	env["CACHE_TTL"] = "0"
+	env["GRAPH_TIMEOUT"] = "15s"
+	env["AZURE_CLOUD"] = "USGov"
+	env["TIME_ZONE"] = "UTC"
+	env["STARTUP_SUBSCRIBE"] = "true"
+	env["WEBHOOK_BIND_RETRIES"] = "0"
+	env["WEBHOOK_BUFFER_SIZE"] = "500"
+	env["WEBHOOK_OVERFLOW"] = "Persist"
+	env["WEBHOOK_OVERFLOW_FILE"] = "/var/log/overflow.jsonl"
+	env["SUBSCRIPTION_TLS_VERSION"] = "v1_3"
+	env["RATE_LIMIT"] = "2.5"
+	env["ROOM_RESPONSE_TIMEOUT"] = "0"
+	env["RATE_BURST"] = "1"
+	env["DEFAULT_SUBJECT"] = " Stand-up "
+	env["DEFAULT_DURATION"] = "15m"
+	env["AUDIT_LOG"] = "/var/log/msgraph-audit.jsonl"
+
+	config, err := loadFrom(env)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.CacheTTL != 0 {
+		t.Errorf("CacheTTL = %v, want 0", config.CacheTTL)
+	}
+	if config.GraphTimeout != 15*time.Second {
+		t.Errorf("GraphTimeout = %v, want 15s", config.GraphTimeout)
+	}
+	if config.Cloud != "usgov" || config.graphHost() != "graph.microsoft.us" {
+		t.Errorf("Cloud = %q (%s), want usgov", config.Cloud, config.graphHost())
+	}
+	if config.TimeZone.String() != "UTC" {
+		t.Errorf("TimeZone = %v, want UTC", config.TimeZone)
+	}
+	if !config.StartupSubscribe {
+		t.Error("StartupSubscribe should be true")
+	}
+	if config.EventSubject != "Stand-up" || config.EventDuration != 15*time.Minute {
+		t.Errorf("event = %q for %v, want Stand-up for 15m", config.EventSubject, config.EventDuration)
+	}
+	if config.WebhookBindRetries != 0 {
+		t.Errorf("WebhookBindRetries = %d, want 0", config.WebhookBindRetries)
+	}
+	if config.WebhookBufferSize != 500 || config.WebhookOverflow != WebhookOverflowPersist || config.WebhookOverflowFile != "/var/log/overflow.jsonl" {
+		t.Errorf("webhook buffer = %d, %q to %q, want 500, persist to /var/log/overflow.jsonl", config.WebhookBufferSize, config.WebhookOverflow, config.WebhookOverflowFile)
+	}
+	if config.SubscriptionTLSVersion != "v1_3" {
+		t.Errorf("SubscriptionTLSVersion = %q, want v1_3", config.SubscriptionTLSVersion)
+	}
+	if config.RoomResponseTimeout != 0 {
+		t.Errorf("RoomResponseTimeout = %v, want 0", config.RoomResponseTimeout)
+	}
+	if config.RateLimit != 2.5 || config.RateBurst != 1 {
+		t.Errorf("RateLimit, RateBurst = %v, %d, want 2.5, 1", config.RateLimit, config.RateBurst)
+	}
+	if config.AuditLog != "/var/log/msgraph-audit.jsonl" {
+		t.Errorf("AuditLog = %q, want /var/log/msgraph-audit.jsonl", config.AuditLog)
+	}
+}
+
+func TestLoadConfigFromUserListing(t *testing.T) {
+	env := validEnv()
+	env["USERS_PAGE_SIZE"] = "999"
+	env["USERS_ORDER_BY"] = "mail"
+
+	config, err := loadFrom(env)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.UsersPageSize != 999 || config.UsersOrderBy != "mail" {
+		t.Errorf("UsersPageSize, UsersOrderBy = %d, %q, want 999, mail", config.UsersPageSize, config.UsersOrderBy)
+	}
+}
+
+func TestSetUserListing(t *testing.T) {
+	g := NewGraphHelper(&Config{})
+	if pageSize, orderBy := g.UserListing(); pageSize != DefaultUsersPageSize || orderBy != "displayName" {
+		t.Errorf("UserListing() = %d, %q, want the defaults", pageSize, orderBy)
+	}
+
+	if err := g.SetUserListing(250, "userPrincipalName"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pageSize, orderBy := g.UserListing(); pageSize != 250 || orderBy != "userPrincipalName" {
+		t.Errorf("UserListing() = %d, %q, want 250, userPrincipalName", pageSize, orderBy)
+	}
+
+	for _, test := range []struct {
+		pageSize int
+		orderBy  string
+	}{{0, "mail"}, {1000, "mail"}, {100, "id"}, {100, "displayname desc"}} {
+		if err := g.SetUserListing(test.pageSize, test.orderBy); err == nil {
+			t.Errorf("SetUserListing(%d, %q) succeeded", test.pageSize, test.orderBy)
+		}
+	}
+	if pageSize, orderBy := g.UserListing(); pageSize != 250 || orderBy != "userPrincipalName" {
+		t.Errorf("a rejected SetUserListing changed the listing to %d, %q", pageSize, orderBy)
+	}
+}
+
+func TestUsersRequest(t *testing.T) {
+	tests := []struct {
+		orderBy              string
+		resourceAccountsOnly bool
+		filter               string
+		advanced             bool
+	}{
+		{"displayName", false, "", false},
+		{"mail", false, "", true},
+		{"displayName", true, "isResourceAccount eq true", true},
+		{"mail", true, "isResourceAccount eq true", true},
+	}
+	for _, test := range tests {
+		config := usersRequest(100, test.orderBy, test.resourceAccountsOnly)
+		query := config.QueryParameters
+
+		if got := StringOrDefault(query.Filter, ""); got != test.filter {
+			t.Errorf("usersRequest(%q, %t) filter = %q, want %q", test.orderBy, test.resourceAccountsOnly, got, test.filter)
+		}
+		counted := query.Count != nil && *query.Count
+		eventual := config.Headers != nil && len(config.Headers.Get("ConsistencyLevel")) == 1 && config.Headers.Get("ConsistencyLevel")[0] == "eventual"
+		if counted != test.advanced || eventual != test.advanced {
+			t.Errorf("usersRequest(%q, %t) $count = %t, ConsistencyLevel eventual = %t, want both %t", test.orderBy, test.resourceAccountsOnly, counted, eventual, test.advanced)
+		}
+		if len(query.Orderby) != 1 || query.Orderby[0] != test.orderBy || *query.Top != 100 {
+			t.Errorf("usersRequest(%q, %t) orderby = %v, top = %d", test.orderBy, test.resourceAccountsOnly, query.Orderby, *query.Top)
+		}
+	}
+}
+
+func TestLoadConfigFromWindowsTimeZone(t *testing.T) {
+	env := validEnv()
+	env["TIME_ZONE"] = "AUS Eastern Standard Time"
+
+	config, err := loadFrom(env)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.TimeZone.String() != "Australia/Sydney" {
+		t.Errorf("TimeZone = %v, want Australia/Sydney", config.TimeZone)
+	}
+}
+
+func TestLoadConfigFromPortFromEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     string
+		problem  string
+	}{
+		{endpoint: "https://example.ngrok.app/webhook", want: ":443"},
+		{endpoint: "http://localhost/webhook", want: ":80"},
+		{endpoint: "https://example.com:8443/webhook", want: ":8443"},
+		{endpoint: "webhook", problem: `PORT is not set and ENDPOINT "webhook" is not a URL to take the port from`},
+		{endpoint: "ftp://example.com/webhook", problem: `PORT is not set and ENDPOINT "ftp://example.com/webhook" has no port and is not http or https`},
+		{endpoint: "https://example.com:0/webhook", problem: `PORT is not set and ENDPOINT "https://example.com:0/webhook" does not have a valid port`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.endpoint, func(t *testing.T) {
+			env := validEnv()
+			delete(env, "PORT")
+			env["ENDPOINT"] = test.endpoint
+
+			config, err := loadFrom(env)
+			if test.problem != "" {
+				if err == nil || err.Error() != test.problem {
+					t.Errorf("error = %v, want %q", err, test.problem)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if config.Port != test.want {
+				t.Errorf("Port = %q, want %q", config.Port, test.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfigFromProblems(t *testing.T) {
+	tests := []struct {
+		name  string
+		key   string
+		value string
+		want  string
+	}{
+		{"missing client id", "CLIENT_ID", "", "CLIENT_ID is not set"},
+		{"missing room email", "ROOM_EMAIL", "", "ROOM_EMAIL is not set"},
+		{"bad port", "PORT", "80a", `PORT "80a" is not a valid port`},
+		{"port out of range", "PORT", "70000", `PORT "70000" is not a valid port`},
+		{"bad organiser email", "ORGANISER_EMAIL", "organiser.example.com", `ORGANISER_EMAIL "organiser.example.com" is not a valid email address`},
+		{"bad room email", "ROOM_EMAIL", "room@localhost", `ROOM_EMAIL "room@localhost" is not a valid email address`},
+		{"bad cache ttl", "CACHE_TTL", "five minutes", `CACHE_TTL "five minutes" is not a valid duration`},
+		{"negative renew interval", "SUBSCRIPTION_RENEW_INTERVAL", "-1h", `SUBSCRIPTION_RENEW_INTERVAL "-1h" is not a valid duration`},
+		{"bad check interval", "SUBSCRIPTION_CHECK_INTERVAL", "often", `SUBSCRIPTION_CHECK_INTERVAL "often" is not a valid duration`},
+		{"zero event duration", "DEFAULT_DURATION", "0", `DEFAULT_DURATION "0" is not a positive duration`},
+		{"negative event duration", "DEFAULT_DURATION", "-30m", `DEFAULT_DURATION "-30m" is not a valid duration`},
+		{"long client state", "WEBHOOK_CLIENT_STATE", strings.Repeat("x", 129), "WEBHOOK_CLIENT_STATE is longer than 128 characters"},
+		{"bad show photos", "SHOW_PHOTOS", "maybe", `SHOW_PHOTOS "maybe" is not a valid boolean`},
+		{"bad hide private events", "HIDE_PRIVATE_EVENTS", "mostly", `HIDE_PRIVATE_EVENTS "mostly" is not a valid boolean`},
+		{"bad show raw json", "SHOW_RAW_JSON", "verbose", `SHOW_RAW_JSON "verbose" is not a valid boolean`},
+		{"bad graph timeout", "GRAPH_TIMEOUT", "30", `GRAPH_TIMEOUT "30" is not a valid duration`},
+		{"bad boolean", "STARTUP_SUBSCRIBE", "yes please", `STARTUP_SUBSCRIBE "yes please" is not a valid boolean`},
+		{"bad retries", "WEBHOOK_BIND_RETRIES", "many", `WEBHOOK_BIND_RETRIES "many" is not a valid count`},
+		{"zero webhook buffer", "WEBHOOK_BUFFER_SIZE", "0", `WEBHOOK_BUFFER_SIZE "0" is not a positive count`},
+		{"bad webhook overflow", "WEBHOOK_OVERFLOW", "block", `WEBHOOK_OVERFLOW "block" is not one of drop or persist`},
+		{"bad cloud", "AZURE_CLOUD", "mars", `AZURE_CLOUD "mars" is not one of public, usgov or china`},
+		{"bad time zone", "TIME_ZONE", "Middle/Earth", `TIME_ZONE "Middle/Earth" is not a known time zone`},
+		{"bad rich notifications", "RICH_NOTIFICATIONS", "sometimes", `RICH_NOTIFICATIONS "sometimes" is not a valid boolean`},
+		{"rich notifications without cert", "RICH_NOTIFICATIONS", "1", "RICH_NOTIFICATIONS needs RICH_NOTIFICATIONS_CERT and RICH_NOTIFICATIONS_KEY"},
+		{"zero rate limit", "RATE_LIMIT", "0", `RATE_LIMIT "0" is not a positive number of requests per second`},
+		{"bad rate limit", "RATE_LIMIT", "fast", `RATE_LIMIT "fast" is not a positive number of requests per second`},
+		{"negative rate burst", "RATE_BURST", "-5", `RATE_BURST "-5" is not a positive count`},
+		{"bad tls version", "SUBSCRIPTION_TLS_VERSION", "1.2", `SUBSCRIPTION_TLS_VERSION "1.2" is not one of v1_0, v1_1, v1_2, v1_3`},
+		{"users page too large", "USERS_PAGE_SIZE", "1000", `USERS_PAGE_SIZE "1000" is not a page size from 1 to 999`},
+		{"users order by id", "USERS_ORDER_BY", "id", `USERS_ORDER_BY "id" is not one of displayName, userPrincipalName, mail`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			env := validEnv()
+			env[test.key] = test.value
+
+			config, err := loadFrom(env)
+			if err == nil {
+				t.Fatalf("expected an error, got config %+v", config)
+			}
+			if err.Error() != test.want {
+				t.Errorf("error = %q, want %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfigFromReportsEveryProblem(t *testing.T) {
+	env := validEnv()
+	delete(env, "CLIENT_SECRET")
+	env["ROOM_EMAIL"] = "not an email"
+	env["CACHE_TTL"] = "soon"
+
+	_, err := loadFrom(env)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{"CLIENT_SECRET is not set", "ROOM_EMAIL", "CACHE_TTL"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %s", err, want)
+		}
+	}
+	if got := strings.Count(err.Error(), "; "); got != 2 {
+		t.Errorf("error %q joins %d problems, want 3", err, got+1)
+	}
+}
+
+func TestLoadConfigFromKeyVault(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+	}{
+		{"secret from key vault", map[string]string{"KEYVAULT_URL": "https://my-vault.vault.azure.net", "SECRET_NAME": "graph-secret"}, ""},
+		{"not https", map[string]string{"KEYVAULT_URL": "http://my-vault.vault.azure.net", "SECRET_NAME": "graph-secret"}, `KEYVAULT_URL "http://my-vault.vault.azure.net" is not an https URL`},
+		{"no secret name", map[string]string{"KEYVAULT_URL": "https://my-vault.vault.azure.net"}, "SECRET_NAME is not set"},
+		{"bad secret name", map[string]string{"KEYVAULT_URL": "https://my-vault.vault.azure.net", "SECRET_NAME": "graph_secret"}, `SECRET_NAME "graph_secret" is not a valid Key Vault secret name`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			env := validEnv()
+			delete(env, "CLIENT_SECRET")
+			for key, value := range test.env {
+				env[key] = value
+			}
+
+			config, err := loadFrom(env)
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Fatalf("error = %v, want %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if config.KeyVaultURL != test.env["KEYVAULT_URL"] || config.SecretName != test.env["SECRET_NAME"] {
+				t.Errorf("key vault = %q %q, want %q %q", config.KeyVaultURL, config.SecretName, test.env["KEYVAULT_URL"], test.env["SECRET_NAME"])
+			}
+			if got := config.vaultScope(); got != "https://vault.azure.net/.default" {
+				t.Errorf("vaultScope() = %q, want the public cloud's", got)
+			}
+		})
+	}
+}
+
+func TestLoadConfigFromAuthMode(t *testing.T) {
+	tests := []struct {
+		name      string
+		env       map[string]string
+		wantMode  string
+		wantScope string
+		wantErr   string
+	}{
+		{"secret by default", map[string]string{}, AuthModeSecret, "https://graph.microsoft.com/.default", ""},
+		{"default credential without app registration", map[string]string{"AUTH_MODE": "Default", "CLIENT_ID": "", "CLIENT_SECRET": "", "TENANT_ID": ""}, AuthModeDefault, "https://graph.microsoft.com/.default", ""},
+		{"custom scope", map[string]string{"GRAPH_SCOPE": "https://graph.microsoft.com/Calendars.Read"}, AuthModeSecret, "https://graph.microsoft.com/Calendars.Read", ""},
+		{"unknown mode", map[string]string{"AUTH_MODE": "cli"}, "", "", `AUTH_MODE "cli" is not one of secret or default`},
+		{"secret mode needs tenant", map[string]string{"TENANT_ID": ""}, "", "", "TENANT_ID is not set"},
+		{"bad scope", map[string]string{"GRAPH_SCOPE": "Calendars.Read"}, "", "", `GRAPH_SCOPE "Calendars.Read" is not an https URL`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			env := validEnv()
+			for key, value := range test.env {
+				env[key] = value
+			}
+
+			config, err := loadFrom(env)
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Fatalf("error = %v, want %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if config.AuthMode != test.wantMode || config.graphScope() != test.wantScope {
+				t.Errorf("auth = %q with scope %q, want %q with %q", config.AuthMode, config.graphScope(), test.wantMode, test.wantScope)
+			}
+		})
+	}
+}
+
+func TestReloadConfigIsSafeWhileReading(t *testing.T) {
+	config, err := loadFrom(validEnv())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	g := NewGraphHelper(config)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			reloaded := *config
+			reloaded.RoomEmail = "other@example.com"
+			g.ReloadConfig(&reloaded)
+			g.SetRoomEmail("room@example.com")
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		_ = g.Config().RoomEmail
+	}
+	<-done
+
+	if got := g.Config().RoomEmail; got != "room@example.com" {
+		t.Errorf("RoomEmail = %q, want room@example.com", got)
+	}
+	// the caller's copy is not changed by SetRoomEmail
+	if config.RoomEmail != "room@example.com" {
+		t.Errorf("caller's config was modified: %q", config.RoomEmail)
+	}
+}
+
+func TestGraphHelperStateIsSafeAcrossGoroutines(t *testing.T) {
+	config, err := loadFrom(validEnv())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	g := NewGraphHelper(config)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			id := "event-id"
+			g.SetLastId(&id)
+			g.SetResourceAccountsOnly(i%2 == 0)
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		_ = g.LastId()
+		_ = g.ResourceAccountsOnly()
+	}
+	<-done
+
+	if got := g.LastId(); got != "event-id" {
+		t.Errorf("LastId() = %q, want event-id", got)
+	}
+}
+
+func TestReinitializeIsSafeDuringGraphCalls(t *testing.T) {
+	config, err := loadFrom(validEnv())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	g := NewGraphHelper(config)
+	if err := g.InitializeGraphForAppAuth(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			g.ReloadConfig(config)
+			if err := g.InitializeGraphForAppAuth(); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+	}()
+	for i := 0; i < 20; i++ {
+		if _, err := g.graphClient(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		_ = g.RecentRequests()
+	}
+	<-done
+}
@@ -0,0 +1,86 @@
+package graphhelper
+
+import (
+	"context"
+	"fmt"
+
+	abstractions "github.com/microsoft/kiota-abstractions-go"
+	"github.com/microsoftgraph/msgraph-sdk-go/places"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+)
+
+// countHeaders returns the headers Graph requires for advanced queries such as $count.
+func countHeaders() *abstractions.RequestHeaders {
+	headers := abstractions.NewRequestHeaders()
+	headers.Add("ConsistencyLevel", "eventual")
+	return headers
+}
+
+// CountUsers asks Graph for the total number of users in the tenant, or only the
+// room and equipment mailboxes when the resource account filter is on.
+// Tenants without advanced query support return an error, callers should fall back
+// to counting what they have fetched.
+func (g *GraphHelper) CountUsers() (int64, error) {
+	client, err := g.graphClient()
+	if err != nil {
+		return 0, err
+	}
+
+	count := true
+	var topValue int32 = 1
+	query := users.UsersRequestBuilderGetQueryParameters{
+		Select: []string{"id"},
+		Top:    &topValue,
+		Count:  &count,
+	}
+	if g.ResourceAccountsOnly() {
+		filter := "isResourceAccount eq true"
+		query.Filter = &filter
+	}
+
+	result, err := client.Users().
+		Get(context.Background(),
+			&users.UsersRequestBuilderGetRequestConfiguration{
+				Headers:         countHeaders(),
+				QueryParameters: &query,
+			})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count users: %v", err)
+	}
+	if result.GetOdataCount() == nil {
+		return 0, fmt.Errorf("failed to count users: no @odata.count in response")
+	}
+	return *result.GetOdataCount(), nil
+}
+
+// CountRooms asks Graph for the total number of rooms in the tenant.
+// Tenants without advanced query support return an error, callers should fall back
+// to counting what they have fetched.
+func (g *GraphHelper) CountRooms() (int64, error) {
+	client, err := g.graphClient()
+	if err != nil {
+		return 0, err
+	}
+
+	count := true
+	var topValue int32 = 1
+	query := places.GraphRoomRequestBuilderGetQueryParameters{
+		Select: []string{"id"},
+		Top:    &topValue,
+		Count:  &count,
+	}
+
+	result, err := client.Places().GraphRoom().
+		Get(context.Background(),
+			&places.GraphRoomRequestBuilderGetRequestConfiguration{
+				Headers:         countHeaders(),
+				QueryParameters: &query,
+			})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count rooms: %v", err)
+	}
+	if result.GetOdataCount() == nil {
+		return 0, fmt.Errorf("failed to count rooms: no @odata.count in response")
+	}
+	return *result.GetOdataCount(), nil
+}
@@ -0,0 +1,68 @@
+package graphhelper
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// GetRoomDeclines returns the bookings of a room it declined between start and end, for
+// auditing its booking policy. A room usually removes a declined request from its own calendar,
+// so the calendars of the given organisers are read too, where the room's response is kept
+// on its attendee entry. Each meeting is returned once, earliest first.
+//
+// Parameters:
+//   - roomEmail: The email of the room.
+//   - organisers: The IDs or emails of the users whose bookings of the room are audited.
+//   - start: The start of the window.
+//   - end: The end of the window.
+//
+// Returns:
+//   - []AgendaItem: The declined bookings, labelled with the room.
+//   - error: An error object if a calendar cannot be read, otherwise nil.
+func (g *GraphHelper) GetRoomDeclines(roomEmail string, organisers []string, start time.Time, end time.Time) ([]AgendaItem, error) {
+	if err := validateEmail("room", roomEmail); err != nil {
+		return nil, err
+	}
+
+	var declines []AgendaItem
+	seen := map[string]bool{}
+	for _, mailbox := range append([]string{roomEmail}, organisers...) {
+		events, err := g.GetCalendarView(mailbox, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the calendar of %s: %v", mailbox, err)
+		}
+		for _, event := range declinedByRoom(events, roomEmail, strings.EqualFold(mailbox, roomEmail)) {
+			key := strings.ToLower(StringOrDefault(event.GetICalUId(), StringOrDefault(event.GetId(), "")))
+			if key != "" && seen[key] {
+				continue
+			}
+			seen[key] = true
+			declines = append(declines, newAgendaItem(roomEmail, roomEmail, event))
+		}
+	}
+
+	sortAgenda(declines)
+	return declines, nil
+}
+
+// declinedByRoom returns the events the room declined. In the room's own calendar that is the
+// event's response status, in anyone else's it is the status of the room's attendee entry.
+func declinedByRoom(events []models.Eventable, roomEmail string, roomCalendar bool) []models.Eventable {
+	var declined []models.Eventable
+	for _, event := range events {
+		if roomCalendar {
+			if event.GetResponseStatus() != nil && event.GetResponseStatus().GetResponse() != nil &&
+				*event.GetResponseStatus().GetResponse() == models.DECLINED_RESPONSETYPE {
+				declined = append(declined, event)
+			}
+			continue
+		}
+		if response, invited := roomResponse(event, roomEmail); invited && response == models.DECLINED_RESPONSETYPE {
+			declined = append(declined, event)
+		}
+	}
+	return declined
+}
@@ -0,0 +1,68 @@
+package graphhelper
+
+import (
+	"testing"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+func TestDeclinedByRoom(t *testing.T) {
+	withResponse := func(subject string, response models.ResponseType) models.Eventable {
+		event := models.NewEvent()
+		event.SetSubject(&subject)
+		event.SetResponseStatus(models.NewResponseStatus())
+		event.GetResponseStatus().SetResponse(&response)
+		return event
+	}
+	withRoomResponse := func(subject string, response models.ResponseType) models.Eventable {
+		event := models.NewEvent()
+		event.SetSubject(&subject)
+		room := newAttendee("Room@example.com", models.RESOURCE_ATTENDEETYPE)
+		room.SetStatus(models.NewResponseStatus())
+		room.GetStatus().SetResponse(&response)
+		event.SetAttendees([]models.Attendeeable{room})
+		return event
+	}
+
+	tests := []struct {
+		name         string
+		events       []models.Eventable
+		roomCalendar bool
+		want         []string
+	}{
+		{
+			name: "room calendar",
+			events: []models.Eventable{
+				withResponse("clash", models.DECLINED_RESPONSETYPE),
+				withResponse("weekly sync", models.ACCEPTED_RESPONSETYPE),
+				models.NewEvent(),
+			},
+			roomCalendar: true,
+			want:         []string{"clash"},
+		},
+		{
+			name: "organiser calendar",
+			events: []models.Eventable{
+				withRoomResponse("too long", models.DECLINED_RESPONSETYPE),
+				withRoomResponse("standup", models.ACCEPTED_RESPONSETYPE),
+				// the organiser declining is not the room declining
+				withResponse("not invited", models.DECLINED_RESPONSETYPE),
+			},
+			want: []string{"too long"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := declinedByRoom(test.events, "room@example.com", test.roomCalendar)
+			if len(got) != len(test.want) {
+				t.Fatalf("declinedByRoom() returned %d events, want %d", len(got), len(test.want))
+			}
+			for i, event := range got {
+				if subject := StringOrDefault(event.GetSubject(), ""); subject != test.want[i] {
+					t.Errorf("event %d = %q, want %q", i, subject, test.want[i])
+				}
+			}
+		})
+	}
+}
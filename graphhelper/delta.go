@@ -0,0 +1,108 @@
+package graphhelper
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+)
+
+// deltaWindow is how far ahead a room's changes are tracked from the first delta request.
+// The window is fixed by that request and carried in every token that follows.
+const deltaWindow = 30 * 24 * time.Hour
+
+// EventsDelta is what changed in a room's calendar since the previous delta request.
+type EventsDelta struct {
+	Changed    []models.Eventable // events added or updated
+	RemovedIds []string           // ids of events deleted, or moved out of the window
+	DeltaToken string             // pass to the next request to get only later changes
+}
+
+// GetRoomEventsDelta returns the changes to a room's calendar since deltaToken was issued,
+// following @odata.nextLink page by page, and remembers the new token for the room.
+// An empty deltaToken starts tracking the next 30 days and returns every event in them as changed.
+//
+// Parameters:
+//   - ctx: Cancels the requests.
+//   - roomId: The ID or email of the room or user.
+//   - deltaToken: The token from the previous call, or RoomDeltaToken, or empty to start over.
+//
+// Returns:
+//   - EventsDelta: The changed and removed events and the token for the next call.
+//   - error: An error object if a request fails, otherwise nil. A token Graph no longer
+//     accepts fails too, start over with an empty token.
+func (g *GraphHelper) GetRoomEventsDelta(ctx context.Context, roomId string, deltaToken string) (EventsDelta, error) {
+	client, err := g.graphClient()
+	if err != nil {
+		return EventsDelta{}, err
+	}
+
+	if err := validateUserId("room", roomId); err != nil {
+		return EventsDelta{}, err
+	}
+
+	delta := client.Users().ByUserId(roomId).CalendarView().Delta()
+	var result users.ItemCalendarViewDeltaGetResponseable
+	if deltaToken == "" {
+		now := time.Now()
+		startDateTime, endDateTime := formatQueryTime(now), formatQueryTime(now.Add(deltaWindow))
+		result, err = delta.GetAsDeltaGetResponse(ctx, &users.ItemCalendarViewDeltaRequestBuilderGetRequestConfiguration{
+			QueryParameters: &users.ItemCalendarViewDeltaRequestBuilderGetQueryParameters{
+				StartDateTime: &startDateTime,
+				EndDateTime:   &endDateTime,
+			},
+		})
+	} else {
+		// the token is the @odata.deltaLink, which carries the window and the sync state
+		result, err = delta.WithUrl(deltaToken).GetAsDeltaGetResponse(ctx, nil)
+	}
+
+	var changes EventsDelta
+	for page := 1; ; page++ {
+		if err != nil {
+			g.reportProgress("changes", page, len(changes.Changed)+len(changes.RemovedIds), true)
+			return EventsDelta{}, fmt.Errorf("failed to get calendar changes: %v", err)
+		}
+		for _, event := range result.GetValue() {
+			if isRemoved(event) {
+				changes.RemovedIds = append(changes.RemovedIds, StringOrDefault(event.GetId(), ""))
+			} else {
+				changes.Changed = append(changes.Changed, event)
+			}
+		}
+
+		nextLink := result.GetOdataNextLink()
+		if nextLink == nil {
+			g.reportProgress("changes", page, len(changes.Changed)+len(changes.RemovedIds), true)
+			break
+		}
+		g.reportProgress("changes", page, len(changes.Changed)+len(changes.RemovedIds), false)
+		result, err = delta.WithUrl(*nextLink).GetAsDeltaGetResponse(ctx, nil)
+	}
+
+	changes.DeltaToken = StringOrDefault(result.GetOdataDeltaLink(), "")
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.deltaTokens == nil {
+		g.deltaTokens = map[string]string{}
+	}
+	g.deltaTokens[strings.ToLower(roomId)] = changes.DeltaToken
+	return changes, nil
+}
+
+// RoomDeltaToken returns the token from the last GetRoomEventsDelta for the room, or an
+// empty string when its changes are not being tracked yet.
+func (g *GraphHelper) RoomDeltaToken(roomId string) string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.deltaTokens[strings.ToLower(roomId)]
+}
+
+// isRemoved reports whether a delta entry is an @removed marker rather than an event.
+func isRemoved(event models.Eventable) bool {
+	_, removed := event.GetAdditionalData()["@removed"]
+	return removed
+}
@@ -0,0 +1,31 @@
+package graphhelper
+
+import (
+	"testing"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+func TestIsRemoved(t *testing.T) {
+	changed := models.NewEvent()
+	removed := models.NewEvent()
+	removed.SetAdditionalData(map[string]any{"@removed": map[string]any{"reason": "deleted"}})
+
+	if isRemoved(changed) {
+		t.Error("isRemoved() = true for an event without @removed")
+	}
+	if !isRemoved(removed) {
+		t.Error("isRemoved() = false for an @removed marker")
+	}
+}
+
+func TestRoomDeltaTokenIgnoresCase(t *testing.T) {
+	g := &GraphHelper{deltaTokens: map[string]string{"room@example.com": "https://graph.microsoft.com/delta?$deltatoken=abc"}}
+
+	if got := g.RoomDeltaToken("Room@Example.com"); got != "https://graph.microsoft.com/delta?$deltatoken=abc" {
+		t.Errorf("RoomDeltaToken() = %q, want the stored token", got)
+	}
+	if got := g.RoomDeltaToken("other@example.com"); got != "" {
+		t.Errorf("RoomDeltaToken() = %q for an untracked room, want empty", got)
+	}
+}
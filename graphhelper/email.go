@@ -0,0 +1,45 @@
+package graphhelper
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+// objectIdPattern matches a directory object id, a GUID such as "5f3e1c4a-0b1d-4c2e-9a7f-123456789abc".
+var objectIdPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// IsValidEmail reports whether s is a bare email address such as "room@example.com".
+// Display names ("Room <room@example.com>") and surrounding whitespace are rejected
+// because Graph expects the address alone.
+func IsValidEmail(s string) bool {
+	if s == "" || strings.TrimSpace(s) != s {
+		return false
+	}
+	address, err := mail.ParseAddress(s)
+	if err != nil || address.Address != s {
+		return false
+	}
+	// mail.ParseAddress accepts a bare domain such as "user@localhost"
+	at := strings.LastIndex(s, "@")
+	return strings.Contains(s[at+1:], ".")
+}
+
+// validateEmail returns a friendly error naming the field when value is not a valid email,
+// so bad input is caught before Graph responds with an opaque error.
+func validateEmail(field string, value string) error {
+	if !IsValidEmail(value) {
+		return fmt.Errorf("%s %q is not a valid email address", field, value)
+	}
+	return nil
+}
+
+// validateUserId is validateEmail for parameters Graph accepts as either the user's
+// object id or their email address.
+func validateUserId(field string, value string) error {
+	if objectIdPattern.MatchString(value) || IsValidEmail(value) {
+		return nil
+	}
+	return fmt.Errorf("%s %q is not a valid user id or email address", field, value)
+}
@@ -0,0 +1,61 @@
+package graphhelper
+
+import "testing"
+
+func TestIsValidEmail(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"room@example.com", true},
+		{"first.last+tag@sub.example.com", true},
+		{"", false},
+		{"room.example.com", false},
+		{"room@", false},
+		{"@example.com", false},
+		{"room@localhost", false},
+		{" room@example.com", false},
+		{"room@example.com ", false},
+		{"\troom@example.com\n", false},
+		{"Room <room@example.com>", false},
+	}
+
+	for _, test := range tests {
+		if got := IsValidEmail(test.value); got != test.want {
+			t.Errorf("IsValidEmail(%q) = %t, want %t", test.value, got, test.want)
+		}
+	}
+}
+
+func TestValidateUserId(t *testing.T) {
+	tests := []struct {
+		value string
+		valid bool
+	}{
+		{"room@example.com", true},
+		{"5f3e1c4a-0b1d-4c2e-9a7f-123456789abc", true},
+		{"5F3E1C4A-0B1D-4C2E-9A7F-123456789ABC", true},
+		{"5f3e1c4a0b1d4c2e9a7f123456789abc", false},
+		{"room.example.com", false},
+		{" room@example.com", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		err := validateUserId("user", test.value)
+		if (err == nil) != test.valid {
+			t.Errorf("validateUserId(%q) = %v, want valid %t", test.value, err, test.valid)
+		}
+	}
+}
+
+func TestValidateEmailNamesTheField(t *testing.T) {
+	err := validateEmail("room", "room.example.com")
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	want := `room "room.example.com" is not a valid email address`
+	if err.Error() != want {
+		t.Errorf("error = %q, want %q", err, want)
+	}
+}
@@ -0,0 +1,100 @@
+package graphhelper
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// printed returns what fn writes to stdout.
+func printed(t *testing.T, fn func()) string {
+	t.Helper()
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = writer
+	defer func() { os.Stdout = stdout }()
+
+	fn()
+
+	writer.Close()
+	out, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return string(out)
+}
+
+func TestPrintEventWithoutOptionalFields(t *testing.T) {
+	g := &GraphHelper{}
+
+	out := printed(t, func() { g.PrintEvent(models.NewEvent()) })
+
+	for _, want := range []string{
+		"Event Id : -\n",
+		"  Subject: (no subject)\n",
+		"  Start: -, End: -\n",
+		"  OnlineMeeting: -\n",
+		"  isOrganiser: -\n",
+		"  isCancelled: -\n",
+		"  Organiser: -\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("PrintEvent output %q is missing %q", out, want)
+		}
+	}
+}
+
+func TestPrintEventWithOrganiserWithoutEmail(t *testing.T) {
+	g := &GraphHelper{}
+	event := models.NewEvent()
+	event.SetOrganizer(models.NewRecipient())
+
+	out := printed(t, func() { g.PrintEvent(event) })
+
+	if !strings.Contains(out, "  Organiser: -\n") {
+		t.Errorf("PrintEvent output %q is missing the organiser placeholder", out)
+	}
+}
+
+func TestPrintEventInMailboxTimeZone(t *testing.T) {
+	g := &GraphHelper{}
+	start, end, zone := "2024-03-01T09:00:00.0000000", "2024-03-01T09:30:00.0000000", "AUS Eastern Standard Time"
+	event := models.NewEvent()
+	event.SetStart(models.NewDateTimeTimeZone())
+	event.GetStart().SetDateTime(&start)
+	event.GetStart().SetTimeZone(&zone)
+	event.SetEnd(models.NewDateTimeTimeZone())
+	event.GetEnd().SetDateTime(&end)
+	event.GetEnd().SetTimeZone(&zone)
+
+	out := printed(t, func() { g.PrintEvent(event) })
+
+	if !strings.Contains(out, "  Time zone: AUS Eastern Standard Time\n") {
+		t.Errorf("PrintEvent output %q does not name the mailbox time zone", out)
+	}
+	if strings.Contains(out, "Local Start") || strings.Contains(out, "Local End") {
+		t.Errorf("PrintEvent output %q converted mailbox times as if they were UTC", out)
+	}
+}
+
+func TestCalendarViewQuery(t *testing.T) {
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	query := calendarViewQuery(start, start.Add(7*24*time.Hour))
+
+	if *query.StartDateTime != "2024-03-01T09:00:00Z" || *query.EndDateTime != "2024-03-08T09:00:00Z" {
+		t.Errorf("window = %s to %s, want 2024-03-01T09:00:00Z to 2024-03-08T09:00:00Z", *query.StartDateTime, *query.EndDateTime)
+	}
+	if len(query.Orderby) != 1 || query.Orderby[0] != "start/dateTime" {
+		t.Errorf("Orderby = %v, want start/dateTime", query.Orderby)
+	}
+	if query.Top == nil || *query.Top != calendarViewPageSize {
+		t.Errorf("Top = %v, want %d", query.Top, calendarViewPageSize)
+	}
+}
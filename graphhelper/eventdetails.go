@@ -0,0 +1,202 @@
+package graphhelper
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+)
+
+// AttendeeStatus is an attendee of an event and how they have responded.
+type AttendeeStatus struct {
+	Email    string
+	Type     string // required, optional or resource, empty when Graph leaves it unset
+	Response string // Graph's response type, "none" when the attendee has not been asked
+}
+
+// attendeeStatuses reads the attendees of an event, skipping entries without an address.
+func attendeeStatuses(event models.Eventable) []AttendeeStatus {
+	var statuses []AttendeeStatus
+	for _, attendee := range event.GetAttendees() {
+		if attendee == nil || attendee.GetEmailAddress() == nil {
+			continue
+		}
+		status := AttendeeStatus{
+			Email:    StringOrDefault(attendee.GetEmailAddress().GetAddress(), ""),
+			Response: models.NONE_RESPONSETYPE.String(),
+		}
+		if status.Email == "" {
+			continue
+		}
+		if attendee.GetTypeEscaped() != nil {
+			status.Type = attendee.GetTypeEscaped().String()
+		}
+		if attendee.GetStatus() != nil && attendee.GetStatus().GetResponse() != nil {
+			status.Response = attendee.GetStatus().GetResponse().String()
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// RoomAttendees returns the rooms and other resources invited to an event and how they have responded.
+func RoomAttendees(event models.Eventable) []AttendeeStatus {
+	var rooms []AttendeeStatus
+	for _, attendee := range attendeeStatuses(event) {
+		if attendee.Type == models.RESOURCE_ATTENDEETYPE.String() {
+			rooms = append(rooms, attendee)
+		}
+	}
+	return rooms
+}
+
+// EventDetails is what the detail view shows beyond the summary of an event.
+type EventDetails struct {
+	Attendees []AttendeeStatus
+
+	// SeriesId is the series master of a recurring event, empty for a single event
+	SeriesId  string
+	Instances []EventSummary // the occurrences of the series in the window
+}
+
+// eventDetailsFields are read again when showing an event, as listings may leave them out.
+var eventDetailsFields = []string{"id", "type", "seriesMasterId", "attendees"}
+
+// seriesIdOf returns the series master of a recurring event, the event itself for the master,
+// or an empty string for a single event.
+func seriesIdOf(event models.Eventable) string {
+	if event.GetTypeEscaped() == nil {
+		return ""
+	}
+	switch *event.GetTypeEscaped() {
+	case models.SERIESMASTER_EVENTTYPE:
+		return StringOrDefault(event.GetId(), "")
+	case models.OCCURRENCE_EVENTTYPE, models.EXCEPTION_EVENTTYPE:
+		return StringOrDefault(event.GetSeriesMasterId(), "")
+	}
+	return ""
+}
+
+// GetEventDetails reads an event again for every attendee and, when it is part of a recurring
+// series, the occurrences of the series between start and end, following every page of them.
+//
+// Parameters:
+//   - ctx: Cancels the requests.
+//   - userId: The ID or email of the mailbox the event is in.
+//   - eventId: The ID of the event.
+//   - start: The start of the window for occurrences.
+//   - end: The end of the window for occurrences.
+//
+// Returns:
+//   - EventDetails: The attendees and occurrences.
+//   - error: An error object if a request fails, otherwise nil.
+func (g *GraphHelper) GetEventDetails(ctx context.Context, userId string, eventId string, start time.Time, end time.Time) (EventDetails, error) {
+	client, err := g.graphClient()
+	if err != nil {
+		return EventDetails{}, err
+	}
+
+	if err := validateUserId("mailbox", userId); err != nil {
+		return EventDetails{}, err
+	}
+
+	event, err := client.Users().ByUserId(userId).Events().ByEventId(eventId).Get(ctx, &users.ItemEventsEventItemRequestBuilderGetRequestConfiguration{
+		QueryParameters: &users.ItemEventsEventItemRequestBuilderGetQueryParameters{
+			Select: eventDetailsFields,
+		},
+	})
+	if err != nil {
+		return EventDetails{}, fmt.Errorf("failed to get event: %v", err)
+	}
+
+	details := EventDetails{Attendees: attendeeStatuses(event), SeriesId: seriesIdOf(event)}
+	if details.SeriesId == "" {
+		return details, nil
+	}
+	instances, err := g.GetEventInstances(ctx, userId, details.SeriesId, start, end)
+	if err != nil {
+		return EventDetails{}, err
+	}
+	for _, instance := range instances {
+		details.Instances = append(details.Instances, g.NewEventSummary(instance))
+	}
+	return details, nil
+}
+
+// GetEventInstances returns the occurrences of a recurring series between start and end,
+// earliest first, following every page of them.
+//
+// Parameters:
+//   - ctx: Cancels the requests.
+//   - userId: The ID or email of the mailbox the series is in.
+//   - seriesId: The ID of the series master.
+//   - start: The start of the window.
+//   - end: The end of the window.
+//
+// Returns:
+//   - []models.Eventable: The occurrences and exceptions in the window.
+//   - error: An error object if a request fails, otherwise nil.
+func (g *GraphHelper) GetEventInstances(ctx context.Context, userId string, seriesId string, start time.Time, end time.Time) ([]models.Eventable, error) {
+	client, err := g.graphClient()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateUserId("mailbox", userId); err != nil {
+		return nil, err
+	}
+
+	startDateTime := formatQueryTime(start)
+	endDateTime := formatQueryTime(end)
+	top := int32(calendarViewPageSize)
+	instances := client.Users().ByUserId(userId).Events().ByEventId(seriesId).Instances()
+	result, err := instances.Get(ctx, &users.ItemEventsItemInstancesRequestBuilderGetRequestConfiguration{
+		QueryParameters: &users.ItemEventsItemInstancesRequestBuilderGetQueryParameters{
+			StartDateTime: &startDateTime,
+			EndDateTime:   &endDateTime,
+			Orderby:       []string{"start/dateTime"},
+			Top:           &top,
+		},
+	})
+
+	var events []models.Eventable
+	for page := 1; ; page++ {
+		if err != nil {
+			g.reportProgress("instances", page, len(events), true)
+			return nil, fmt.Errorf("failed to get the instances of %s: %v", seriesId, err)
+		}
+		events = append(events, result.GetValue()...)
+
+		nextLink := result.GetOdataNextLink()
+		if nextLink == nil {
+			g.reportProgress("instances", page, len(events), true)
+			return events, nil
+		}
+		g.reportProgress("instances", page, len(events), false)
+
+		// the next link already carries the window
+		result, err = instances.WithUrl(*nextLink).Get(ctx, nil)
+	}
+}
+
+// WriteText writes the attendees and occurrences below an event's summary.
+func (d EventDetails) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "  Attendees: %d\n", len(d.Attendees))
+	for _, attendee := range d.Attendees {
+		fmt.Fprintf(w, "    %s (%s): %s\n", attendee.Email, orDefault(attendee.Type, "-"), attendee.Response)
+	}
+	if d.SeriesId == "" {
+		return
+	}
+	fmt.Fprintf(w, "  Occurrences: %d\n", len(d.Instances))
+	for _, summary := range d.Instances {
+		cancelled := ""
+		if summary.IsCancelled != nil && *summary.IsCancelled {
+			cancelled = " (cancelled)"
+		}
+		fmt.Fprintf(w, "    %s  %s%s\n", summary.DisplayTime(), orDefault(summary.Id, "-"), cancelled)
+	}
+}
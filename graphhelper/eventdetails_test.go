@@ -0,0 +1,93 @@
+package graphhelper
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+func TestAttendeeStatuses(t *testing.T) {
+	event := models.NewEvent()
+	room := newAttendee("room@example.com", models.RESOURCE_ATTENDEETYPE)
+	accepted := models.ACCEPTED_RESPONSETYPE
+	room.SetStatus(models.NewResponseStatus())
+	room.GetStatus().SetResponse(&accepted)
+	person := newAttendee("alice@example.com", models.REQUIRED_ATTENDEETYPE)
+	// Graph can return attendees without an address, such as removed distribution lists
+	noAddress := models.NewAttendee()
+	event.SetAttendees([]models.Attendeeable{room, person, noAddress, nil})
+
+	got := attendeeStatuses(event)
+	want := []AttendeeStatus{
+		{Email: "room@example.com", Type: "resource", Response: "accepted"},
+		{Email: "alice@example.com", Type: "required", Response: "none"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("attendeeStatuses() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("attendee %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestRoomAttendees(t *testing.T) {
+	event := models.NewEvent()
+	event.SetAttendees([]models.Attendeeable{
+		newAttendee("alice@example.com", models.REQUIRED_ATTENDEETYPE),
+		newAttendee("room@example.com", models.RESOURCE_ATTENDEETYPE),
+	})
+
+	rooms := RoomAttendees(event)
+	if len(rooms) != 1 || rooms[0].Email != "room@example.com" {
+		t.Errorf("RoomAttendees() = %+v, want only room@example.com", rooms)
+	}
+}
+
+func TestSeriesIdOf(t *testing.T) {
+	event := func(id string, eventType *models.EventType, masterId string) models.Eventable {
+		e := models.NewEvent()
+		e.SetId(&id)
+		e.SetTypeEscaped(eventType)
+		e.SetSeriesMasterId(&masterId)
+		return e
+	}
+	single, master, occurrence := models.SINGLEINSTANCE_EVENTTYPE, models.SERIESMASTER_EVENTTYPE, models.OCCURRENCE_EVENTTYPE
+
+	tests := []struct {
+		name  string
+		event models.Eventable
+		want  string
+	}{
+		{"single", event("event-1", &single, ""), ""},
+		{"master", event("series-1", &master, ""), "series-1"},
+		{"occurrence", event("event-2", &occurrence, "series-1"), "series-1"},
+		{"unknown type", event("event-3", nil, "series-1"), ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := seriesIdOf(test.event); got != test.want {
+				t.Errorf("seriesIdOf() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestEventDetailsWriteText(t *testing.T) {
+	cancelled := true
+	details := EventDetails{
+		Attendees: []AttendeeStatus{{Email: "room@example.com", Type: "resource", Response: "declined"}},
+		SeriesId:  "series-1",
+		Instances: []EventSummary{{Id: "event-1"}, {Id: "event-2", IsCancelled: &cancelled}},
+	}
+
+	var out strings.Builder
+	details.WriteText(&out)
+	for _, want := range []string{"Attendees: 1", "room@example.com (resource): declined", "Occurrences: 2", "event-2 (cancelled)"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("WriteText() = %q, want it to contain %q", out.String(), want)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package graphhelper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+func TestEventFilterMatches(t *testing.T) {
+	newEvent := func(cancelled *bool, online *bool, organiser string) models.Eventable {
+		event := models.NewEvent()
+		event.SetIsCancelled(cancelled)
+		event.SetIsOnlineMeeting(online)
+		if organiser != "" {
+			event.SetOrganizer(models.NewRecipient())
+			event.GetOrganizer().SetEmailAddress(models.NewEmailAddress())
+			event.GetOrganizer().GetEmailAddress().SetAddress(&organiser)
+		}
+		return event
+	}
+	yes, no := true, false
+
+	tests := []struct {
+		name   string
+		filter EventFilter
+		event  models.Eventable
+		want   bool
+	}{
+		{"no conditions", EventFilter{}, newEvent(nil, nil, ""), true},
+		{"cancelled", EventFilter{OnlyCancelled: true}, newEvent(&yes, nil, ""), true},
+		{"not cancelled", EventFilter{OnlyCancelled: true}, newEvent(&no, nil, ""), false},
+		{"cancelled not set", EventFilter{OnlyCancelled: true}, newEvent(nil, nil, ""), false},
+		{"online", EventFilter{OnlyOnlineMeetings: true}, newEvent(nil, &yes, ""), true},
+		{"in person", EventFilter{OnlyOnlineMeetings: true}, newEvent(nil, &no, ""), false},
+		{"organiser ignoring case", EventFilter{Organiser: "alice@example.com"}, newEvent(nil, nil, "Alice@Example.com"), true},
+		{"other organiser", EventFilter{Organiser: "alice@example.com"}, newEvent(nil, nil, "bob@example.com"), false},
+		{"no organiser", EventFilter{Organiser: "alice@example.com"}, newEvent(nil, nil, ""), false},
+		{"every condition", EventFilter{OnlyCancelled: true, OnlyOnlineMeetings: true, Organiser: "alice@example.com"}, newEvent(&yes, &no, "alice@example.com"), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.filter.Matches(test.event); got != test.want {
+				t.Errorf("Matches() = %t, want %t", got, test.want)
+			}
+		})
+	}
+
+	organised, invited, unknown := models.NewEvent(), models.NewEvent(), models.NewEvent()
+	organised.SetIsOrganizer(&yes)
+	invited.SetIsOrganizer(&no)
+	filter := EventFilter{OnlyOrganised: true}
+	if !filter.Matches(organised) || filter.Matches(invited) || filter.Matches(unknown) {
+		t.Error("Matches() with OnlyOrganised should only match events with isOrganizer set")
+	}
+
+	categorised := models.NewEvent()
+	categorised.SetCategories([]string{"Blue category", "Catering"})
+	if !(EventFilter{Category: "catering"}).Matches(categorised) || (EventFilter{Category: "Cater"}).Matches(categorised) || (EventFilter{Category: "catering"}).Matches(unknown) {
+		t.Error("Matches() with Category should only match events in that category, ignoring case")
+	}
+}
+
+func TestEventFilterString(t *testing.T) {
+	tests := []struct {
+		filter EventFilter
+		want   string
+	}{
+		{EventFilter{}, "events"},
+		{EventFilter{OnlyCancelled: true}, "cancelled events"},
+		{EventFilter{OnlyCancelled: true, OnlyOnlineMeetings: true}, "cancelled online meetings"},
+		{EventFilter{Organiser: "alice@example.com"}, "events organised by alice@example.com"},
+		{EventFilter{OnlyOrganised: true}, "events they organised"},
+		{EventFilter{Category: "Catering"}, "events in category Catering"},
+	}
+	for _, test := range tests {
+		if got := test.filter.String(); got != test.want {
+			t.Errorf("String() = %q, want %q", got, test.want)
+		}
+	}
+}
+
+func TestGetFilteredCalendarViewValidatesOrganiser(t *testing.T) {
+	g := &GraphHelper{}
+	now := time.Now()
+	if _, err := g.GetFilteredCalendarView("room@example.com", now, now.Add(time.Hour), EventFilter{Organiser: "not an email"}); err == nil {
+		t.Error("GetFilteredCalendarView() accepted an invalid organiser")
+	}
+}
@@ -0,0 +1,312 @@
+package graphhelper
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// EventSummary is the part of an event the listings show, read from Graph once so the
+// text, JSON and CSV output do not each have to deal with fields Graph leaves unset.
+type EventSummary struct {
+	Id      string `json:"id"`
+	Subject string `json:"subject"`
+
+	// Start and End are as returned by Graph, in TimeZone, or empty when not set
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	TimeZone string `json:"timeZone"`
+
+	// The times in UTC and in the configured "TIME_ZONE", zero when they are not set or
+	// TimeZone is unknown. For an all day event both are midnight of its dates, unconverted.
+	StartUTC   time.Time `json:"startUTC"`
+	EndUTC     time.Time `json:"endUTC"`
+	StartLocal time.Time `json:"startLocal"`
+	EndLocal   time.Time `json:"endLocal"`
+
+	Organiser       string `json:"organiser"`
+	IsAllDay        bool   `json:"isAllDay"`
+	IsCancelled     *bool  `json:"isCancelled"`
+	IsOnlineMeeting *bool  `json:"isOnlineMeeting"`
+	IsOrganizer     *bool  `json:"isOrganizer"`
+
+	Categories  []string `json:"categories,omitempty"`
+	Sensitivity string   `json:"sensitivity,omitempty"` // normal, personal, private or confidential
+
+	OnlineMeeting *OnlineMeetingDetails `json:"onlineMeeting,omitempty"` // nil for events that are not online meetings
+}
+
+// OnlineMeetingDetails are how to join an online meeting, empty when the provider gives no dial-in.
+type OnlineMeetingDetails struct {
+	JoinUrl         string   `json:"joinUrl"`
+	ConferenceId    string   `json:"conferenceId,omitempty"`
+	TollNumber      string   `json:"tollNumber,omitempty"`
+	TollFreeNumbers []string `json:"tollFreeNumbers,omitempty"`
+}
+
+// onlineMeetingOf reads the join details of an online meeting, or returns nil when the event is
+// not one. Events created before onlineMeeting existed only have onlineMeetingUrl.
+func onlineMeetingOf(event models.Eventable) *OnlineMeetingDetails {
+	if event.GetIsOnlineMeeting() == nil || !*event.GetIsOnlineMeeting() {
+		return nil
+	}
+	details := &OnlineMeetingDetails{JoinUrl: StringOrDefault(event.GetOnlineMeetingUrl(), "")}
+	if info := event.GetOnlineMeeting(); info != nil {
+		details.JoinUrl = StringOrDefault(info.GetJoinUrl(), details.JoinUrl)
+		details.ConferenceId = StringOrDefault(info.GetConferenceId(), "")
+		details.TollNumber = StringOrDefault(info.GetTollNumber(), "")
+		details.TollFreeNumbers = info.GetTollFreeNumbers()
+	}
+	return details
+}
+
+// privateSubject replaces the subject of private and confidential events when "HIDE_PRIVATE_EVENTS" is set.
+const privateSubject = "Private"
+
+// isPrivate reports whether an event's sensitivity asks for its details to be kept to its attendees.
+func isPrivate(sensitivity string) bool {
+	return sensitivity == models.PRIVATE_SENSITIVITY.String() || sensitivity == models.CONFIDENTIAL_SENSITIVITY.String()
+}
+
+// NewEventSummary reads the fields of an event that are set, with local times in the configured
+// "TIME_ZONE". When "HIDE_PRIVATE_EVENTS" is set, private and confidential events are shown as
+// "Private" without their join details. A nil event gives an empty summary.
+func (g *GraphHelper) NewEventSummary(event models.Eventable) EventSummary {
+	if event == nil {
+		return EventSummary{TimeZone: "UTC"}
+	}
+	summary := EventSummary{
+		Id:              StringOrDefault(event.GetId(), ""),
+		Subject:         StringOrDefault(event.GetSubject(), ""),
+		Start:           StringOrDefault(dateTimeOf(event.GetStart()), ""),
+		End:             StringOrDefault(dateTimeOf(event.GetEnd()), ""),
+		TimeZone:        timeZoneOf(event.GetStart()),
+		IsAllDay:        event.GetIsAllDay() != nil && *event.GetIsAllDay(),
+		IsCancelled:     event.GetIsCancelled(),
+		IsOnlineMeeting: event.GetIsOnlineMeeting(),
+		IsOrganizer:     event.GetIsOrganizer(),
+		OnlineMeeting:   onlineMeetingOf(event),
+		Categories:      event.GetCategories(),
+	}
+	if event.GetSensitivity() != nil {
+		summary.Sensitivity = event.GetSensitivity().String()
+	}
+	config := g.Config()
+	if config.HidePrivateEvents && isPrivate(summary.Sensitivity) {
+		summary.Subject, summary.OnlineMeeting = privateSubject, nil
+	}
+	if event.GetOrganizer() != nil && event.GetOrganizer().GetEmailAddress() != nil {
+		summary.Organiser = StringOrDefault(event.GetOrganizer().GetEmailAddress().GetAddress(), "")
+	}
+
+	local := config.TimeZone
+	if local == nil {
+		local = time.Local
+	}
+	if summary.IsAllDay {
+		if start, err := allDayDate(summary.Start, local); err == nil {
+			summary.StartLocal, summary.StartUTC = start, time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+		}
+		if end, err := allDayDate(summary.End, local); err == nil {
+			summary.EndLocal, summary.EndUTC = end, time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+		}
+		return summary
+	}
+
+	// Graph returns Windows time zone names when asked for a mailbox's own zone
+	zone, err := LoadTimeZone(summary.TimeZone)
+	if err != nil {
+		return summary
+	}
+	if start, err := time.ParseInLocation(graphDateTimeLayout, summary.Start, zone); err == nil {
+		summary.StartUTC, summary.StartLocal = start.UTC(), start.In(local)
+	}
+	if end, err := time.ParseInLocation(graphDateTimeLayout, summary.End, zone); err == nil {
+		summary.EndUTC, summary.EndLocal = end.UTC(), end.In(local)
+	}
+	return summary
+}
+
+// WriteText writes the summary as PrintEvent shows it. Fields Graph left unset are shown as "-".
+func (s EventSummary) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "Event Id : %s\n", orDefault(s.Id, "-"))
+	fmt.Fprintf(w, "  Subject: %s\n", orDefault(s.Subject, "(no subject)"))
+	fmt.Fprintf(w, "  Start: %s, End: %s\n", orDefault(s.Start, "-"), orDefault(s.End, "-"))
+
+	// Times fetched in a mailbox time zone are shown in that zone instead of being converted
+	if s.IsAllDay {
+		fmt.Fprintf(w, "  All day: %s\n", s.DisplayTime())
+	} else if s.TimeZone != "UTC" {
+		fmt.Fprintf(w, "  Time zone: %s\n", s.TimeZone)
+	} else {
+		if s.Start != "" {
+			fmt.Fprintf(w, "  Local Start: %s\n", timeOrDash(s.StartLocal))
+		}
+		if s.End != "" {
+			fmt.Fprintf(w, "  Local End: %s\n", timeOrDash(s.EndLocal))
+		}
+	}
+	fmt.Fprintf(w, "  OnlineMeeting: %s\n", boolOrDefault(s.IsOnlineMeeting, "-"))
+	if meeting := s.OnlineMeeting; meeting != nil {
+		fmt.Fprintf(w, "    Join URL: %s\n", orDefault(meeting.JoinUrl, "-"))
+		if meeting.ConferenceId != "" {
+			fmt.Fprintf(w, "    Conference Id: %s\n", meeting.ConferenceId)
+		}
+		if meeting.TollNumber != "" {
+			fmt.Fprintf(w, "    Dial-in: %s\n", meeting.TollNumber)
+		}
+		if len(meeting.TollFreeNumbers) > 0 {
+			fmt.Fprintf(w, "    Toll free: %s\n", strings.Join(meeting.TollFreeNumbers, ", "))
+		}
+	}
+	fmt.Fprintf(w, "  isOrganiser: %s\n", boolOrDefault(s.IsOrganizer, "-"))
+	fmt.Fprintf(w, "  isCancelled: %s\n", boolOrDefault(s.IsCancelled, "-"))
+	fmt.Fprintf(w, "  Organiser: %s\n", orDefault(s.Organiser, "-"))
+	if len(s.Categories) > 0 {
+		fmt.Fprintf(w, "  Categories: %s\n", strings.Join(s.Categories, ", "))
+	}
+	if s.Sensitivity != "" && s.Sensitivity != models.NORMAL_SENSITIVITY.String() {
+		fmt.Fprintf(w, "  Sensitivity: %s\n", s.Sensitivity)
+	}
+}
+
+// DisplayTime returns when the event starts for short listings, or the dates it covers for an
+// all day event, "-" when its start is not known.
+func (s EventSummary) DisplayTime() string {
+	if s.StartLocal.IsZero() {
+		return "-"
+	}
+	if s.IsAllDay {
+		return formatAllDay(s.StartLocal, s.EndLocal)
+	}
+	return s.StartLocal.Format(DisplayLayout)
+}
+
+// joinUrl returns the link to join the event's online meeting, or "" when it is not one.
+func (s EventSummary) joinUrl() string {
+	if s.OnlineMeeting == nil {
+		return ""
+	}
+	return s.OnlineMeeting.JoinUrl
+}
+
+// WriteEventsJSON writes the summaries as an indented JSON array.
+func WriteEventsJSON(w io.Writer, summaries []EventSummary) error {
+	if summaries == nil {
+		summaries = []EventSummary{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(summaries)
+}
+
+// eventCSVHeader names the columns written by WriteEventsCSV.
+var eventCSVHeader = []string{"id", "subject", "start", "end", "timeZone", "startUTC", "endUTC", "startLocal", "endLocal", "organiser", "isCancelled", "isOnlineMeeting", "isOrganizer", "isAllDay", "joinUrl", "categories", "sensitivity"}
+
+// WriteEventsCSV writes the summaries as CSV with a header row. Unset fields are left empty,
+// times are written in RFC 3339 and categories are separated by semicolons.
+func WriteEventsCSV(w io.Writer, summaries []EventSummary) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(eventCSVHeader); err != nil {
+		return err
+	}
+	for _, s := range summaries {
+		err := writer.Write([]string{
+			s.Id, s.Subject, s.Start, s.End, s.TimeZone,
+			csvTime(s.StartUTC), csvTime(s.EndUTC), csvTime(s.StartLocal), csvTime(s.EndLocal),
+			s.Organiser, boolOrDefault(s.IsCancelled, ""), boolOrDefault(s.IsOnlineMeeting, ""), boolOrDefault(s.IsOrganizer, ""),
+			strconv.FormatBool(s.IsAllDay), s.joinUrl(), strings.Join(s.Categories, ";"), s.Sensitivity,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// EventFormats are the file formats events can be exported in.
+var EventFormats = []string{"json", "csv"}
+
+// eventWriter returns the function writing events in one of EventFormats.
+func eventWriter(format string) (func(io.Writer, []EventSummary) error, error) {
+	switch format {
+	case "json":
+		return WriteEventsJSON, nil
+	case "csv":
+		return WriteEventsCSV, nil
+	}
+	return nil, fmt.Errorf("format %q is not one of %s", format, strings.Join(EventFormats, ", "))
+}
+
+// ExportRoom7DaysBookings writes the next 7 days of events in a room's or user's calendar to a
+// file as JSON or CSV.
+//
+// Parameters:
+//   - path: The file to write, replaced if it exists.
+//   - roomId: The ID or email of the room or user.
+//   - format: One of EventFormats.
+//
+// Returns:
+//   - int: The number of events written.
+//   - error: An error object if the format is unknown, the events cannot be listed or the file
+//     cannot be written, otherwise nil.
+func (g *GraphHelper) ExportRoom7DaysBookings(path string, roomId string, format string) (int, error) {
+	write, err := eventWriter(format)
+	if err != nil {
+		return 0, err
+	}
+
+	bookings, err := g.ListRoom7DaysBookings(roomId)
+	if err != nil {
+		return 0, err
+	}
+	summaries := make([]EventSummary, 0, len(bookings.Events))
+	for _, event := range bookings.Events {
+		summaries = append(summaries, g.NewEventSummary(event))
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create %s: %v", path, err)
+	}
+	if err := write(file, summaries); err != nil {
+		file.Close()
+		return 0, fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	if err := file.Close(); err != nil {
+		return 0, fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	return len(summaries), nil
+}
+
+// orDefault returns value, or the fallback when it is empty.
+func orDefault(value string, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// timeOrDash formats a time the way fmt's %v does, or returns "-" when it is zero.
+func timeOrDash(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.String()
+}
+
+// csvTime formats a time in RFC 3339, or returns an empty string when it is zero.
+func csvTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
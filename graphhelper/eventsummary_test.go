@@ -0,0 +1,282 @@
+package graphhelper
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+func TestNewEventSummary(t *testing.T) {
+	melbourne, err := time.LoadLocation("Australia/Melbourne")
+	if err != nil {
+		t.Skipf("time zone database not available: %v", err)
+	}
+	g := NewGraphHelper(&Config{TimeZone: melbourne})
+
+	id, subject, organiser, cancelled := "event-1", "Weekly sync", "alice@example.com", true
+	start, end := "2024-03-01T09:00:00.0000000", "2024-03-01T09:30:00.0000000"
+	event := models.NewEvent()
+	event.SetId(&id)
+	event.SetSubject(&subject)
+	event.SetIsCancelled(&cancelled)
+	event.SetStart(models.NewDateTimeTimeZone())
+	event.GetStart().SetDateTime(&start)
+	event.SetEnd(models.NewDateTimeTimeZone())
+	event.GetEnd().SetDateTime(&end)
+	event.SetOrganizer(models.NewRecipient())
+	event.GetOrganizer().SetEmailAddress(models.NewEmailAddress())
+	event.GetOrganizer().GetEmailAddress().SetAddress(&organiser)
+
+	summary := g.NewEventSummary(event)
+
+	if summary.Id != id || summary.Subject != subject || summary.Organiser != organiser {
+		t.Errorf("summary = %+v, want id %q, subject %q and organiser %q", summary, id, subject, organiser)
+	}
+	if want := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC); !summary.StartUTC.Equal(want) {
+		t.Errorf("StartUTC = %v, want %v", summary.StartUTC, want)
+	}
+	if got := summary.StartLocal.Format(InputLayout); got != "2024-03-01T20:00" {
+		t.Errorf("StartLocal = %s, want 2024-03-01T20:00 in Melbourne", got)
+	}
+	if got := summary.EndLocal.Format(InputLayout); got != "2024-03-01T20:30" {
+		t.Errorf("EndLocal = %s, want 2024-03-01T20:30 in Melbourne", got)
+	}
+	if summary.IsCancelled == nil || !*summary.IsCancelled || summary.IsOnlineMeeting != nil {
+		t.Errorf("summary booleans = %v, %v, want only isCancelled set", summary.IsCancelled, summary.IsOnlineMeeting)
+	}
+}
+
+func TestNewEventSummaryHidesPrivateEvents(t *testing.T) {
+	subject, online, joinUrl := "Performance review", true, "https://teams.example.com/join"
+	event := func(sensitivity models.Sensitivity) models.Eventable {
+		e := models.NewEvent()
+		e.SetSubject(&subject)
+		e.SetSensitivity(&sensitivity)
+		e.SetIsOnlineMeeting(&online)
+		e.SetOnlineMeetingUrl(&joinUrl)
+		e.SetCategories([]string{"HR"})
+		return e
+	}
+
+	shown := (&GraphHelper{}).NewEventSummary(event(models.PRIVATE_SENSITIVITY))
+	if shown.Subject != subject || shown.Sensitivity != "private" || shown.joinUrl() != joinUrl || len(shown.Categories) != 1 {
+		t.Errorf("summary = %+v, want the subject, sensitivity, categories and join URL", shown)
+	}
+
+	g := NewGraphHelper(&Config{HidePrivateEvents: true})
+	for _, sensitivity := range []models.Sensitivity{models.PRIVATE_SENSITIVITY, models.CONFIDENTIAL_SENSITIVITY} {
+		hidden := g.NewEventSummary(event(sensitivity))
+		if hidden.Subject != privateSubject || hidden.OnlineMeeting != nil {
+			t.Errorf("%s summary = %+v, want the subject and join details hidden", sensitivity, hidden)
+		}
+	}
+	if normal := g.NewEventSummary(event(models.NORMAL_SENSITIVITY)); normal.Subject != subject {
+		t.Errorf("normal summary subject = %q, want %q", normal.Subject, subject)
+	}
+}
+
+func TestNewEventSummaryInWindowsTimeZone(t *testing.T) {
+	g := &GraphHelper{}
+	start, zone := "2024-03-01T09:00:00.0000000", "AUS Eastern Standard Time"
+	event := models.NewEvent()
+	event.SetStart(models.NewDateTimeTimeZone())
+	event.GetStart().SetDateTime(&start)
+	event.GetStart().SetTimeZone(&zone)
+
+	summary := g.NewEventSummary(event)
+
+	if summary.Start != start || summary.TimeZone != zone {
+		t.Errorf("summary start = %q in %q, want %q in %q", summary.Start, summary.TimeZone, start, zone)
+	}
+	if want := time.Date(2024, 2, 29, 22, 0, 0, 0, time.UTC); !summary.StartUTC.Equal(want) {
+		t.Errorf("summary StartUTC = %v, want %v", summary.StartUTC, want)
+	}
+}
+
+func TestNewEventSummaryAllDay(t *testing.T) {
+	melbourne, err := time.LoadLocation("Australia/Melbourne")
+	if err != nil {
+		t.Skipf("time zone database not available: %v", err)
+	}
+	g := NewGraphHelper(&Config{TimeZone: melbourne})
+
+	tests := []struct {
+		name string
+		end  string
+		want string
+	}{
+		{"one day", "2024-03-02T00:00:00.0000000", "Fri 01 Mar, all day"},
+		{"several days", "2024-03-04T00:00:00.0000000", "Fri 01 Mar to Sun 03 Mar, all day"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, zone, allDay := "2024-03-01T00:00:00.0000000", "UTC", true
+			event := models.NewEvent()
+			event.SetIsAllDay(&allDay)
+			event.SetStart(models.NewDateTimeTimeZone())
+			event.GetStart().SetDateTime(&start)
+			event.GetStart().SetTimeZone(&zone)
+			event.SetEnd(models.NewDateTimeTimeZone())
+			event.GetEnd().SetDateTime(&tt.end)
+			event.GetEnd().SetTimeZone(&zone)
+
+			summary := g.NewEventSummary(event)
+
+			if !summary.IsAllDay {
+				t.Errorf("IsAllDay = false, want true")
+			}
+			// The date is not moved into the next day by Melbourne being ahead of UTC
+			if got := summary.StartLocal.Format(InputLayout); got != "2024-03-01T00:00" {
+				t.Errorf("StartLocal = %s, want 2024-03-01T00:00", got)
+			}
+			if got := summary.DisplayTime(); got != tt.want {
+				t.Errorf("DisplayTime() = %q, want %q", got, tt.want)
+			}
+
+			item := newAgendaItem("Room", "room@example.com", event)
+			if got := item.When(melbourne); got != tt.want {
+				t.Errorf("When() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewEventSummaryMinimalCreatedEvent(t *testing.T) {
+	g := NewGraphHelper(&Config{})
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	// a create answered with little more than the id, as a room mailbox may return it
+	id, online := "event-1", true
+	event := models.NewEvent()
+	event.SetId(&id)
+	event.SetIsOnlineMeeting(&online)
+	event.SetOrganizer(models.NewRecipient())
+
+	for _, created := range []models.Eventable{event, nil} {
+		var out bytes.Buffer
+		g.NewEventSummary(created).WriteText(&out)
+		g.CheckEventTimes(created, start, start.Add(time.Hour)).Write(&out, time.UTC, false)
+		g.PrintEvent(created)
+
+		for _, want := range []string{"(no subject)", "isOrganiser: -", "Organiser: -", "Returned:  - - -"} {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("created event printed %q, want it to contain %q", out.String(), want)
+			}
+		}
+	}
+	if g.LastId() != id {
+		t.Errorf("LastId() = %q, want %q", g.LastId(), id)
+	}
+}
+
+func TestOnlineMeetingDetails(t *testing.T) {
+	online, offline := true, false
+	joinUrl, legacyUrl, conferenceId, tollNumber := "https://teams.example.com/join/1", "https://meet.example.com/old", "123456789", "+61 2 5550 0100"
+	withInfo := func() models.Eventable {
+		event := models.NewEvent()
+		event.SetIsOnlineMeeting(&online)
+		event.SetOnlineMeeting(models.NewOnlineMeetingInfo())
+		event.GetOnlineMeeting().SetJoinUrl(&joinUrl)
+		event.GetOnlineMeeting().SetConferenceId(&conferenceId)
+		event.GetOnlineMeeting().SetTollNumber(&tollNumber)
+		return event
+	}
+	legacy := models.NewEvent()
+	legacy.SetIsOnlineMeeting(&online)
+	legacy.SetOnlineMeetingUrl(&legacyUrl)
+	inPerson := models.NewEvent()
+	inPerson.SetIsOnlineMeeting(&offline)
+
+	tests := []struct {
+		name  string
+		event models.Eventable
+		want  []string
+	}{
+		{"join details", withInfo(), []string{"    Join URL: " + joinUrl, "    Conference Id: " + conferenceId, "    Dial-in: " + tollNumber}},
+		{"only the old url", legacy, []string{"    Join URL: " + legacyUrl}},
+		{"not online", inPerson, nil},
+		{"not set", models.NewEvent(), nil},
+	}
+
+	g := &GraphHelper{}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var out bytes.Buffer
+			g.NewEventSummary(test.event).WriteText(&out)
+
+			var got []string
+			for _, line := range strings.Split(out.String(), "\n") {
+				if strings.HasPrefix(line, "    ") {
+					got = append(got, line)
+				}
+			}
+			if strings.Join(got, "\n") != strings.Join(test.want, "\n") {
+				t.Errorf("meeting lines = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestWriteEventsJSON(t *testing.T) {
+	cancelled := false
+	summaries := []EventSummary{{Id: "event-1", Subject: "Weekly sync", IsCancelled: &cancelled}}
+
+	var out bytes.Buffer
+	if err := WriteEventsJSON(&out, summaries); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var decoded []map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out.String())
+	}
+	if len(decoded) != 1 || decoded[0]["id"] != "event-1" || decoded[0]["isCancelled"] != false || decoded[0]["isOnlineMeeting"] != nil {
+		t.Errorf("WriteEventsJSON() = %s", out.String())
+	}
+
+	out.Reset()
+	if err := WriteEventsJSON(&out, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.TrimSpace(out.String()); got != "[]" {
+		t.Errorf("WriteEventsJSON(nil) = %s, want []", got)
+	}
+}
+
+func TestWriteEventsCSV(t *testing.T) {
+	summaries := []EventSummary{{
+		Id:       "event-1",
+		Subject:  "Sync, weekly",
+		Start:    "2024-03-01T09:00:00.0000000",
+		TimeZone: "UTC",
+		StartUTC: time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC),
+	}}
+
+	var out bytes.Buffer
+	if err := WriteEventsCSV(&out, summaries); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || lines[0] != strings.Join(eventCSVHeader, ",") {
+		t.Fatalf("WriteEventsCSV() = %q, want a header and one row", out.String())
+	}
+	if want := `event-1,"Sync, weekly",2024-03-01T09:00:00.0000000,,UTC,2024-03-01T09:00:00Z,,,,,,,,false,,,`; lines[1] != want {
+		t.Errorf("row = %q, want %q", lines[1], want)
+	}
+}
+
+func TestEventWriter(t *testing.T) {
+	for _, format := range EventFormats {
+		if _, err := eventWriter(format); err != nil {
+			t.Errorf("eventWriter(%q) = %v", format, err)
+		}
+	}
+	if _, err := eventWriter("xml"); err == nil {
+		t.Error("eventWriter(\"xml\") accepted an unknown format")
+	}
+}
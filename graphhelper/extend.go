@@ -0,0 +1,134 @@
+package graphhelper
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+)
+
+// ExtendEvent moves the end of an event later, for when a meeting runs long. The new end is
+// clamped to the start of the next booking in the organiser's calendar or in any room booked
+// for the event, so the extension never double books. Graph sends the attendees an update.
+//
+// Parameters:
+//   - ctx: Cancels the requests.
+//   - userId: The ID or email of the event's organiser.
+//   - eventId: The ID of the event in the organiser's calendar.
+//   - by: How much later the event should end.
+//
+// Returns:
+//   - time.Time: The new end of the event.
+//   - bool: Whether the end was clamped short of the full extension.
+//   - error: An error object if the event cannot be extended or the request fails, otherwise nil.
+func (g *GraphHelper) ExtendEvent(ctx context.Context, userId string, eventId string, by time.Duration) (time.Time, bool, error) {
+	client, err := g.graphClient()
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	if err := validateUserId("organiser", userId); err != nil {
+		return time.Time{}, false, err
+	}
+	if by <= 0 {
+		return time.Time{}, false, fmt.Errorf("the extension %v is not positive", by)
+	}
+
+	item := client.Users().ByUserId(userId).Events().ByEventId(eventId)
+	event, err := item.Get(ctx, &users.ItemEventsEventItemRequestBuilderGetRequestConfiguration{
+		QueryParameters: &users.ItemEventsEventItemRequestBuilderGetQueryParameters{
+			Select: []string{"id", "iCalUId", "end", "isOrganizer", "isAllDay", "attendees"},
+		},
+	})
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to get event: %v", err)
+	}
+	if event.GetIsOrganizer() != nil && !*event.GetIsOrganizer() {
+		return time.Time{}, false, fmt.Errorf("event %s is not organised by %s, only the organiser can change it", eventId, userId)
+	}
+	if event.GetIsAllDay() != nil && *event.GetIsAllDay() {
+		return time.Time{}, false, fmt.Errorf("event %s is an all day event", eventId)
+	}
+	endValue := dateTimeOf(event.GetEnd())
+	if endValue == nil {
+		return time.Time{}, false, fmt.Errorf("event %s has no end", eventId)
+	}
+	end, err := parseFromGraph(*endValue)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to read the end of event %s: %v", eventId, err)
+	}
+
+	// Stop at the next booking of the organiser or of any room the event books
+	newEnd := end.Add(by)
+	iCalUId := StringOrDefault(event.GetICalUId(), "")
+	for _, mailbox := range append([]string{userId}, resourceAttendees(event)...) {
+		bookings, err := g.GetCalendarView(mailbox, end, newEnd)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("failed to check %s is free: %v", mailbox, err)
+		}
+		newEnd = clampEnd(end, newEnd, iCalUId, bookings)
+	}
+	if !newEnd.After(end) {
+		return time.Time{}, false, fmt.Errorf("event %s cannot be extended, the next booking starts when it ends", eventId)
+	}
+
+	update := models.NewEvent()
+	update.SetEnd(newDateTimeTimeZone(newEnd))
+	patchCtx, audited := g.audit(ctx, "extend event", userId+"/events/"+eventId)
+	_, err = item.Patch(patchCtx, update, nil)
+	audited(err)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to extend event: %v", err)
+	}
+	return newEnd, newEnd.Before(end.Add(by)), nil
+}
+
+// resourceAttendees returns the emails of the rooms and equipment invited to an event.
+func resourceAttendees(event models.Eventable) []string {
+	var emails []string
+	for _, attendee := range event.GetAttendees() {
+		if attendee.GetTypeEscaped() == nil || *attendee.GetTypeEscaped() != models.RESOURCE_ATTENDEETYPE {
+			continue
+		}
+		if attendee.GetEmailAddress() != nil && attendee.GetEmailAddress().GetAddress() != nil {
+			emails = append(emails, *attendee.GetEmailAddress().GetAddress())
+		}
+	}
+	return emails
+}
+
+// clampEnd returns the earlier of newEnd and the start of the first booking after end, ignoring
+// the event being extended (matched by iCalUId, as its id differs between mailboxes), cancelled
+// bookings and bookings shown as free.
+func clampEnd(end time.Time, newEnd time.Time, iCalUId string, bookings []models.Eventable) time.Time {
+	for _, booking := range bookings {
+		if iCalUId != "" && strings.EqualFold(StringOrDefault(booking.GetICalUId(), ""), iCalUId) {
+			continue
+		}
+		if booking.GetIsCancelled() != nil && *booking.GetIsCancelled() {
+			continue
+		}
+		if booking.GetShowAs() != nil && *booking.GetShowAs() == models.FREE_FREEBUSYSTATUS {
+			continue
+		}
+		startValue := dateTimeOf(booking.GetStart())
+		if startValue == nil {
+			continue
+		}
+		start, err := parseFromGraph(*startValue)
+		if err != nil {
+			continue
+		}
+		// a booking already under way when the event ends leaves no room to extend
+		if start.Before(end) {
+			start = end
+		}
+		if start.Before(newEnd) {
+			newEnd = start
+		}
+	}
+	return newEnd
+}
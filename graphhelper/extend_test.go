@@ -0,0 +1,43 @@
+package graphhelper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+func newTestBooking(iCalUId string, start string, showAs models.FreeBusyStatus) models.Eventable {
+	event := newTestEvent("booking", start)
+	event.SetICalUId(&iCalUId)
+	event.SetShowAs(&showAs)
+	return event
+}
+
+func TestClampEnd(t *testing.T) {
+	end := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	newEnd := end.Add(time.Hour)
+
+	tests := []struct {
+		name     string
+		bookings []models.Eventable
+		want     time.Time
+	}{
+		{"free", nil, newEnd},
+		{"next booking", []models.Eventable{
+			newTestBooking("other", "2024-03-01T10:45:00.0000000", models.BUSY_FREEBUSYSTATUS),
+			newTestBooking("later", "2024-03-01T10:30:00.0000000", models.BUSY_FREEBUSYSTATUS),
+		}, end.Add(30 * time.Minute)},
+		{"the event itself", []models.Eventable{newTestBooking("self", "2024-03-01T09:00:00.0000000", models.BUSY_FREEBUSYSTATUS)}, newEnd},
+		{"shown as free", []models.Eventable{newTestBooking("other", "2024-03-01T10:15:00.0000000", models.FREE_FREEBUSYSTATUS)}, newEnd},
+		{"already under way", []models.Eventable{newTestBooking("other", "2024-03-01T09:30:00.0000000", models.BUSY_FREEBUSYSTATUS)}, end},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := clampEnd(end, newEnd, "self", test.bookings); !got.Equal(test.want) {
+				t.Errorf("clampEnd() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -2,238 +2,722 @@ package graphhelper
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"net/http"
 	"os"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	abstractions "github.com/microsoft/kiota-abstractions-go"
 	auth "github.com/microsoft/kiota-authentication-azure-go"
 	msgraphsdk "github.com/microsoftgraph/msgraph-sdk-go"
+	msgraphcore "github.com/microsoftgraph/msgraph-sdk-go-core"
 	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/models/odataerrors"
 	"github.com/microsoftgraph/msgraph-sdk-go/users"
 )
 
 type GraphHelper struct {
-	clientSecretCredential *azidentity.ClientSecretCredential
-	appClient              *msgraphsdk.GraphServiceClient
+	credential              azcore.TokenCredential
+	appClient               *msgraphsdk.GraphServiceClient
+	cache                   *cache
+	mu                      sync.RWMutex // guards the client, credential, config, lastId, resourceAccountsOnly, notificationCertificate and deltaTokens
+	config                  Config
+	lastId                  string
+	resourceAccountsOnly    bool
+	progress                ProgressFunc
+	requests                *requestHistory
+	notificationCertificate *NotificationCertificate
+	limiter                 *rateLimiter
+	deltaTokens             map[string]string // by lower case room id
+	auditMu                 sync.Mutex        // serialises writes to the audit log
 }
 
-func NewGraphHelper() *GraphHelper {
-	g := &GraphHelper{}
+func NewGraphHelper(config *Config) *GraphHelper {
+	limit, burst := config.RateLimit, config.RateBurst
+	if limit <= 0 || burst <= 0 {
+		limit, burst = DefaultRateLimit, DefaultRateBurst
+	}
+	g := &GraphHelper{
+		config:   *config,
+		cache:    newCache(config.CacheTTL),
+		requests: &requestHistory{},
+		limiter:  newRateLimiter(limit, burst),
+	}
 	return g
 }
 
-// GetPort retrieves the port number from the environment variable "PORT".
-// If the "PORT" environment variable is not set, it logs a fatal error message
-// and returns the default port ":8080".
-func (g *GraphHelper) GetPort() string {
-	port := os.Getenv("PORT")
-	if port == "" {
-		log.Fatal("PORT is not set in .env file, returning default port 8080")
-		port = "8080"
-	}
-	return ":" + port
+// LastId returns the most recently printed primary id (event, subscription, room or user).
+func (g *GraphHelper) LastId() string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.lastId
 }
 
-// GetRoomEmail retrieves the room email address from the environment variable "ROOM_EMAIL".
-// If the environment variable is not set, the function logs a fatal error and terminates the program.
-// Returns the room email address as a string.
-func (g *GraphHelper) GetRoomEmail() string {
-	roomEmail := os.Getenv("ROOM_EMAIL")
-	if roomEmail == "" {
-		log.Fatal("ROOM_EMAIL is not set in .env file")
-		return ""
-
+// SetLastId records an id that has just been printed so it can be copied later.
+func (g *GraphHelper) SetLastId(id *string) {
+	if id != nil {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		g.lastId = *id
 	}
-	return roomEmail
 }
 
-// GetOrganiserEmail retrieves the organizer's email address from the environment variable "ORGANISER_EMAIL".
-// If the environment variable is not set, the function logs a fatal error and terminates the program.
-// Returns the organizer's email address as a string.
-func (g *GraphHelper) GetOrganiserEmail() string {
-	organiserEmail := os.Getenv("ORGANISER_EMAIL")
+// Config returns a copy of the configuration in use. It is safe to call while the
+// configuration is being reloaded from another goroutine.
+func (g *GraphHelper) Config() Config {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.config
+}
 
-	if organiserEmail == "" {
-		log.Fatal("ORGANISER_EMAIL is not set in .env file")
-		return ""
+// ReloadConfig replaces the configuration, for example after .env has been edited.
+// The room chosen from the menu is dropped in favour of the new "ROOM_EMAIL".
+// Call InitializeGraphForAppAuth afterwards for changed credentials to take effect.
+func (g *GraphHelper) ReloadConfig(config *Config) {
+	g.mu.Lock()
+	g.config = *config
+	g.mu.Unlock()
+	g.cache.setTTL(config.CacheTTL)
+	g.cache.clear()
+	if config.RateLimit > 0 && config.RateBurst > 0 {
+		g.limiter.set(config.RateLimit, config.RateBurst)
 	}
-	return organiserEmail
 }
 
-// GetNotificationUrl retrieves the notification URL from the environment variable "ENDPOINT".
-// If the "ENDPOINT" variable is not set, the function logs a fatal error and terminates the program.
-// Returns the notification URL as a string.
-func (g *GraphHelper) GetNotificationUrl() string {
-	notificationURL := os.Getenv("ENDPOINT")
-
-	if notificationURL == "" {
-		log.Fatal("ENDPOINT is not set in .env file")
-		return ""
-	}
-	return notificationURL
+// SetRoomEmail makes the given room the one used by the room actions instead of "ROOM_EMAIL".
+func (g *GraphHelper) SetRoomEmail(roomEmail string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.config.RoomEmail = roomEmail
 }
 
 // InitializeGraphForAppAuth initializes the Microsoft Graph client for application authentication.
-// It retrieves the client ID, tenant ID, and client secret from environment variables,
-// creates a client secret credential, and uses it to create an authentication provider.
+// It takes the client ID, tenant ID, client secret and cloud from the configuration, reading
+// the client secret from Key Vault when "KEYVAULT_URL" is set. It creates a client secret credential,
+// or a DefaultAzureCredential when "AUTH_MODE" is default, and uses it to create an authentication provider.
 // The authentication provider is then used to create a request adapter, which is used to
 // create a Graph client. The initialized Graph client is stored in the GraphHelper struct.
+// Calls already in flight keep using the previous client, so it is safe to re-initialize
+// after the configuration has been reloaded.
 //
 // Returns an error if any of the steps fail.
 func (g *GraphHelper) InitializeGraphForAppAuth() error {
-	clientId := os.Getenv("CLIENT_ID")
-	tenantId := os.Getenv("TENANT_ID")
-	clientSecret := os.Getenv("CLIENT_SECRET")
-	credential, err := azidentity.NewClientSecretCredential(tenantId, clientId, clientSecret, nil)
+	config := g.Config()
+	credential, err := g.newCredential(config)
 	if err != nil {
 		return err
 	}
 
-	g.clientSecretCredential = credential
-
 	// Create an auth provider using the credential
-	authProvider, err := auth.NewAzureIdentityAuthenticationProviderWithScopes(g.clientSecretCredential, []string{
-		"https://graph.microsoft.com/.default",
-	})
+	authProvider, err := auth.NewAzureIdentityAuthenticationProviderWithScopesAndValidHosts(credential, []string{
+		config.graphScope(),
+	}, []string{config.graphHost()})
 	if err != nil {
 		return err
 	}
 
+	// Create an HTTP client that records the request ids of every call and keeps to the rate limit
+	clientOptions := msgraphsdk.GetDefaultClientOptions()
+	httpClient := msgraphcore.GetDefaultClient(&clientOptions)
+	httpClient.Timeout = config.GraphTimeout
+	httpClient.Transport = &requestIdTransport{
+		next:    &rateLimitTransport{next: httpClient.Transport, limiter: g.limiter},
+		history: g.requests,
+	}
+
 	// Create a request adapter using the auth provider
-	adapter, err := msgraphsdk.NewGraphRequestAdapter(authProvider)
+	adapter, err := msgraphsdk.NewGraphRequestAdapterWithParseNodeFactoryAndSerializationWriterFactoryAndHttpClient(authProvider, nil, nil, httpClient)
 	if err != nil {
 		return err
 	}
+	adapter.SetBaseUrl("https://" + config.graphHost() + "/v1.0")
 
 	// Create a Graph client using request adapter
 	client := msgraphsdk.NewGraphServiceClient(adapter)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.credential = credential
 	g.appClient = client
 
 	return nil
 }
 
-// GetAppToken retrieves an application token using the client secret credential.
-// It requests a token with the scope "https://graph.microsoft.com/.default".
+// newCredential creates the credential "AUTH_MODE" asks for. DefaultAzureCredential tries, in
+// order, the AZURE_* environment variables, workload identity, managed identity, the Azure CLI
+// and the Azure Developer CLI, and uses the first that signs in.
+func (g *GraphHelper) newCredential(config Config) (azcore.TokenCredential, error) {
+	clientOptions := azcore.ClientOptions{Cloud: clouds[config.Cloud].authority}
+	if config.AuthMode == AuthModeDefault {
+		return azidentity.NewDefaultAzureCredential(&azidentity.DefaultAzureCredentialOptions{
+			ClientOptions: clientOptions,
+			TenantID:      config.TenantId,
+		})
+	}
+
+	secret, err := g.clientSecret(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return azidentity.NewClientSecretCredential(config.TenantId, config.ClientId, secret, &azidentity.ClientSecretCredentialOptions{
+		ClientOptions: clientOptions,
+	})
+}
+
+// graphClient returns the Graph client, or an error when InitializeGraphForAppAuth has not
+// succeeded, so Graph calls fail with a clear message instead of a nil pointer panic.
+func (g *GraphHelper) graphClient() (*msgraphsdk.GraphServiceClient, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.appClient == nil || g.credential == nil {
+		return nil, errors.New("Graph client not initialized; check credentials")
+	}
+	return g.appClient, nil
+}
+
+// GetAppToken retrieves an access token using the configured credential.
+// It requests a token for "GRAPH_SCOPE", by default the ".default" scope of the configured cloud's Graph endpoint.
 // Returns a pointer to the token string if successful, or an error if the token request fails.
 func (g *GraphHelper) GetAppToken() (*string, error) {
-	token, err := g.clientSecretCredential.GetToken(context.Background(), policy.TokenRequestOptions{
-		Scopes: []string{
-			"https://graph.microsoft.com/.default",
-		},
+	token, err := g.appToken()
+	if err != nil {
+		return nil, err
+	}
+	return &token.Token, nil
+}
+
+// appToken returns the app-only access token with its expiry. The credential caches the token
+// and requests a new one shortly before it expires.
+func (g *GraphHelper) appToken() (azcore.AccessToken, error) {
+	g.mu.RLock()
+	credential := g.credential
+	scope := g.config.graphScope()
+	g.mu.RUnlock()
+	if credential == nil {
+		return azcore.AccessToken{}, errors.New("Graph client not initialized; check credentials")
+	}
+
+	return credential.GetToken(context.Background(), policy.TokenRequestOptions{
+		Scopes: []string{scope},
 	})
+}
+
+// GetUsers returns every user in the tenant, following @odata.nextLink page by page,
+// served from the cache while it is fresh.
+// When the resource account filter is on, only room and equipment mailboxes are returned.
+func (g *GraphHelper) GetUsers() ([]models.Userable, error) {
+	client, err := g.graphClient()
 	if err != nil {
 		return nil, err
 	}
 
-	return &token.Token, nil
+	if users, ok := g.cache.getUsers(); ok {
+		return users, nil
+	}
+
+	pageSize, orderBy := g.UserListing()
+	config := usersRequest(pageSize, orderBy, g.ResourceAccountsOnly())
+
+	result, err := client.Users().
+		Get(context.Background(), config)
+
+	var all []models.Userable
+	for page := 1; ; page++ {
+		if err != nil {
+			g.reportProgress("users", page, len(all), true)
+			return nil, err
+		}
+		all = append(all, result.GetValue()...)
+
+		nextLink := result.GetOdataNextLink()
+		if nextLink == nil {
+			g.reportProgress("users", page, len(all), true)
+			break
+		}
+		g.reportProgress("users", page, len(all), false)
+
+		// the next link already carries the query, only the headers are needed again
+		result, err = client.Users().WithUrl(*nextLink).
+			Get(context.Background(), &users.UsersRequestBuilderGetRequestConfiguration{
+				Headers: config.Headers,
+			})
+	}
+
+	g.cache.setUsers(all)
+	return all, nil
 }
 
-func (g *GraphHelper) GetUsers() (models.UserCollectionResponseable, error) {
-	var topValue int32 = 25
+// usersRequest returns the request for a page of the user listing. Filtering combined with
+// ordering, and ordering by mail, are advanced queries, which need $count and the
+// ConsistencyLevel header; only the resource account filter limits which users are returned.
+func usersRequest(pageSize int, orderBy string, resourceAccountsOnly bool) *users.UsersRequestBuilderGetRequestConfiguration {
+	topValue := int32(pageSize)
 	query := users.UsersRequestBuilderGetQueryParameters{
 		// Only request specific properties
-		Select: []string{"displayName", "id", "mail"},
-		// Get at most 25 results
+		Select: []string{"displayName", "id", "mail", "isResourceAccount"},
+		// Get at most USERS_PAGE_SIZE results per page
 		Top: &topValue,
-		// Sort by display name
-		Orderby: []string{"displayName"},
+		// Sort by USERS_ORDER_BY, display name by default
+		Orderby: []string{orderBy},
+	}
+	config := &users.UsersRequestBuilderGetRequestConfiguration{
+		QueryParameters: &query,
 	}
 
-	return g.appClient.Users().
-		Get(context.Background(),
-			&users.UsersRequestBuilderGetRequestConfiguration{
-				QueryParameters: &query,
-			})
+	if resourceAccountsOnly {
+		filter := "isResourceAccount eq true"
+		query.Filter = &filter
+	}
+	if resourceAccountsOnly || orderBy == "mail" {
+		count := true
+		query.Count = &count
+		config.Headers = countHeaders()
+	}
+	return config
+}
+
+// UserListing returns the page size and sort order of user listings, the defaults when not configured.
+func (g *GraphHelper) UserListing() (int, string) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	pageSize, orderBy := g.config.UsersPageSize, g.config.UsersOrderBy
+	if pageSize <= 0 {
+		pageSize = DefaultUsersPageSize
+	}
+	if orderBy == "" {
+		orderBy = "displayName"
+	}
+	return pageSize, orderBy
+}
+
+// SetUserListing sets the page size and sort order of user listings until the config is reloaded.
+//
+// Parameters:
+//   - pageSize: How many users to request per page, from 1 to MaxUsersPageSize.
+//   - orderBy: The property to sort by, one of UserOrderFields.
+//
+// Returns:
+//   - error: An error object if either value is not allowed, otherwise nil.
+func (g *GraphHelper) SetUserListing(pageSize int, orderBy string) error {
+	if pageSize < 1 || pageSize > MaxUsersPageSize {
+		return fmt.Errorf("page size %d is not from 1 to %d", pageSize, MaxUsersPageSize)
+	}
+	if !slices.Contains(UserOrderFields, orderBy) {
+		return fmt.Errorf("users cannot be sorted by %q, only by one of %s", orderBy, strings.Join(UserOrderFields, ", "))
+	}
+
+	g.mu.Lock()
+	g.config.UsersPageSize, g.config.UsersOrderBy = pageSize, orderBy
+	g.mu.Unlock()
+	g.cache.clearUsers()
+	return nil
+}
+
+// SetHidePrivateEvents shows private and confidential events without their subject or join
+// details, for when the screen is shared, until the configuration is reloaded.
+func (g *GraphHelper) SetHidePrivateEvents(enabled bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.config.HidePrivateEvents = enabled
+}
+
+// SetShowRawJSON also shows the raw JSON of the room, event or subscription in detail views,
+// for debugging unexpected field values, until the configuration is reloaded.
+func (g *GraphHelper) SetShowRawJSON(enabled bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.config.ShowRawJSON = enabled
+}
+
+// ResourceAccountsOnly reports whether user listings are limited to room and equipment mailboxes.
+func (g *GraphHelper) ResourceAccountsOnly() bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.resourceAccountsOnly
+}
+
+// SetResourceAccountsOnly limits user listings to room and equipment mailboxes.
+// The cached users are dropped because they were fetched with the previous filter.
+func (g *GraphHelper) SetResourceAccountsOnly(enabled bool) {
+	g.mu.Lock()
+	g.resourceAccountsOnly = enabled
+	g.mu.Unlock()
+	g.cache.clearUsers()
 }
 
-func (g *GraphHelper) ListSubscriptions() (models.SubscriptionCollectionResponseable, error) {
+// ListSubscriptions returns every subscription of the app, following @odata.nextLink page by page.
+func (g *GraphHelper) ListSubscriptions() ([]models.Subscriptionable, error) {
+	client, err := g.graphClient()
+	if err != nil {
+		return nil, err
+	}
 
-	return g.appClient.Subscriptions().
+	result, err := client.Subscriptions().
 		Get(context.Background(), nil)
 
+	var subscriptions []models.Subscriptionable
+	for page := 1; ; page++ {
+		if err != nil {
+			g.reportProgress("subscriptions", page, len(subscriptions), true)
+			return nil, err
+		}
+		subscriptions = append(subscriptions, result.GetValue()...)
+
+		nextLink := result.GetOdataNextLink()
+		if nextLink == nil {
+			g.reportProgress("subscriptions", page, len(subscriptions), true)
+			return subscriptions, nil
+		}
+		g.reportProgress("subscriptions", page, len(subscriptions), false)
+
+		result, err = client.Subscriptions().WithUrl(*nextLink).
+			Get(context.Background(), nil)
+	}
 }
 
-// ListRooms
-func (g *GraphHelper) ListRooms() {
-	// (places.GraphRoomRequestBuilder, error) {
+// ErrSubscriptionNotFound is returned when Graph has no subscription with the id, because it
+// expired, was deleted or was dropped by Graph.
+var ErrSubscriptionNotFound = errors.New("subscription not found")
 
-	rooms, err := g.appClient.Places().GraphRoom().Get(context.Background(), nil)
+// GetSubscription fetches one subscription, to check it is still active on Graph.
+//
+// Parameters:
+//   - ctx: Cancels the request.
+//   - subscriptionId: The ID of the subscription.
+//
+// Returns:
+//   - models.Subscriptionable: The subscription as Graph holds it.
+//   - error: ErrSubscriptionNotFound if Graph answers 404, another error object if the request fails, otherwise nil.
+func (g *GraphHelper) GetSubscription(ctx context.Context, subscriptionId string) (models.Subscriptionable, error) {
+	client, err := g.graphClient()
+	if err != nil {
+		return nil, err
+	}
+
+	subscription, err := client.Subscriptions().BySubscriptionId(subscriptionId).Get(ctx, nil)
+	if err != nil {
+		var odataError *odataerrors.ODataError
+		if errors.As(err, &odataError) && odataError.GetStatusCode() == http.StatusNotFound {
+			return nil, fmt.Errorf("%s: %w", subscriptionId, ErrSubscriptionNotFound)
+		}
+		return nil, fmt.Errorf("failed to get subscription: %v", err)
+	}
+	return subscription, nil
+}
+
+// GetRooms returns all rooms in the tenant, following @odata.nextLink page by page,
+// served from the cache while it is fresh.
+func (g *GraphHelper) GetRooms() ([]models.Roomable, error) {
+	client, err := g.graphClient()
+	if err != nil {
+		return nil, err
+	}
+
+	if rooms, ok := g.cache.getRooms(); ok {
+		return rooms, nil
+	}
+
+	result, err := client.Places().GraphRoom().Get(context.Background(), nil)
+
+	var rooms []models.Roomable
+	for page := 1; ; page++ {
+		if err != nil {
+			g.reportProgress("rooms", page, len(rooms), true)
+			return nil, err
+		}
+		rooms = append(rooms, result.GetValue()...)
+
+		nextLink := result.GetOdataNextLink()
+		if nextLink == nil {
+			g.reportProgress("rooms", page, len(rooms), true)
+			break
+		}
+		g.reportProgress("rooms", page, len(rooms), false)
+
+		result, err = client.Places().GraphRoom().WithUrl(*nextLink).Get(context.Background(), nil)
+	}
+
+	g.cache.setRooms(rooms)
+	return rooms, nil
+}
+
+// RoomListing is every room in the tenant, with the number of rooms Graph counts.
+type RoomListing struct {
+	Rooms []models.Roomable
+	Total int64 // -1 when Graph cannot count them, as advanced queries are not available in every tenant
+}
+
+// ListRooms returns every room in the tenant, with the total number of rooms when Graph can count them.
+//
+// Returns:
+//   - RoomListing: The rooms and their total.
+//   - error: An error object if the rooms cannot be listed, otherwise nil.
+func (g *GraphHelper) ListRooms() (RoomListing, error) {
+	rooms, err := g.GetRooms()
+	if err != nil {
+		return RoomListing{}, err
+	}
+
+	listing := RoomListing{Rooms: rooms, Total: -1}
+	if len(rooms) > 0 {
+		if total, err := g.CountRooms(); err == nil {
+			listing.Total = total
+		}
+	}
+	return listing, nil
+}
+
+// PrintRooms writes every room in the tenant to w, followed by the total number of rooms.
+// A failure is written to w as well as returned.
+func (g *GraphHelper) PrintRooms(w io.Writer) error {
+	listing, err := g.ListRooms()
 	if err != nil {
-		fmt.Println("Failed to list rooms:", err)
-		return
+		fmt.Fprintln(w, "Failed to list rooms:", err)
+		return err
+	}
+	if len(listing.Rooms) == 0 {
+		fmt.Fprintln(w, "No rooms found")
+		return nil
 	}
 
-	for _, room := range rooms.GetValue() {
-		fmt.Printf("Room ID: %s\n", *room.GetId())
-		fmt.Printf("  Name: %s\n", *room.GetDisplayName())
-		fmt.Printf("  Capacity: %d\n", *room.GetCapacity())
-		fmt.Printf("  Email: %s\n", *room.GetEmailAddress())
+	for _, room := range listing.Rooms {
+		g.printPlace(w, "Room", roomPlace(room))
 	}
 
-	return
+	fmt.Fprintln(w)
+	if listing.Total < 0 {
+		fmt.Fprintf(w, "Rooms listed: %d\n", len(listing.Rooms))
+	} else {
+		fmt.Fprintf(w, "Total rooms: %d\n", listing.Total)
+	}
+	return nil
+}
+
+// StringOrDefault returns the value of a Graph string field, or the fallback when the field is not set.
+func StringOrDefault(value *string, fallback string) string {
+	if value == nil {
+		return fallback
+	}
+	return *value
+}
 
+// RoomBookings is the next 7 days of events in a room's or user's calendar.
+type RoomBookings struct {
+	RoomId   string
+	TimeZone string // the mailbox's time zone the times are in, "" for UTC when its settings cannot be read
+	Events   []models.Eventable
 }
 
-func (g *GraphHelper) ListRoom7DaysBookings(roomId string) {
+// ListRoom7DaysBookings returns the next 7 days of events in a room's or user's calendar.
+// The times are in the mailbox's own time zone when its mailbox settings can be read.
+//
+// Parameters:
+//   - roomId: The ID or email of the room or user.
+//
+// Returns:
+//   - RoomBookings: The events, ordered by start, and the time zone of their times.
+//   - error: An error object if the calendar view cannot be read, otherwise nil.
+func (g *GraphHelper) ListRoom7DaysBookings(roomId string) (RoomBookings, error) {
+	bookings := RoomBookings{RoomId: roomId}
+	if settings, err := g.GetMailboxSettings(roomId); err == nil {
+		bookings.TimeZone = StringOrDefault(settings.GetTimeZone(), "")
+	}
+
 	now := time.Now()
-	startDateTime := now.Format(time.RFC3339)
-	endDateTime := now.Add(7 * 24 * time.Hour).Format(time.RFC3339) // Next 7 days for example
+	events, err := g.calendarView(roomId, now, now.Add(7*24*time.Hour), bookings.TimeZone)
+	if err != nil {
+		return RoomBookings{}, err
+	}
+	bookings.Events = events
+	return bookings, nil
+}
+
+// PrintRoom7DaysBookings writes the next 7 days of events in a room's or user's calendar to w.
+// A failure is written to w as well as returned.
+func (g *GraphHelper) PrintRoom7DaysBookings(w io.Writer, roomId string) error {
+	bookings, err := g.ListRoom7DaysBookings(roomId)
+	if err != nil {
+		fmt.Fprintln(w, "Failed to get calendar view:", err)
+		return err
+	}
+	if bookings.TimeZone != "" {
+		fmt.Fprintf(w, "Times are in the mailbox time zone: %s\n", bookings.TimeZone)
+	}
+	if len(bookings.Events) == 0 {
+		fmt.Fprintf(w, "No events found for %s in the next 7 days\n", roomId)
+		return nil
+	}
+
+	for _, event := range bookings.Events {
+		g.SetLastId(event.GetId())
+		g.NewEventSummary(event).WriteText(w)
+	}
+	return nil
+}
+
+// GetCalendarView retrieves the events in a user's or room's calendar between start and end,
+// ordered by start, following @odata.nextLink page by page.
+//
+// Parameters:
+//   - roomId: The ID or email of the room or user.
+//   - start: The start of the window.
+//   - end: The end of the window.
+//
+// Returns:
+//   - []models.Eventable: The events in the window, including occurrences of recurring events.
+//   - error: An error object if the request fails, otherwise nil.
+func (g *GraphHelper) GetCalendarView(roomId string, start time.Time, end time.Time) ([]models.Eventable, error) {
+	return g.calendarView(roomId, start, end, "")
+}
 
-	// Query parameters for fetching calendar events
-	queryParams := &users.ItemCalendarViewRequestBuilderGetQueryParameters{
+// calendarViewPageSize is how many events are requested per page of a calendar view,
+// instead of Graph's default of 10.
+const calendarViewPageSize = 100
+
+// calendarViewQuery returns the query for the events between start and end, ordered by their
+// start so the pages join up in order.
+func calendarViewQuery(start time.Time, end time.Time) *users.ItemCalendarViewRequestBuilderGetQueryParameters {
+	startDateTime := formatQueryTime(start)
+	endDateTime := formatQueryTime(end)
+	top := int32(calendarViewPageSize)
+	return &users.ItemCalendarViewRequestBuilderGetQueryParameters{
 		EndDateTime:   &endDateTime,
 		StartDateTime: &startDateTime,
+		Orderby:       []string{"start/dateTime"},
+		Top:           &top,
+	}
+}
+
+// calendarView is GetCalendarView with the event times returned in the given time zone,
+// a Windows or IANA name as found in mailbox settings. An empty time zone means UTC.
+func (g *GraphHelper) calendarView(roomId string, start time.Time, end time.Time, timeZone string) ([]models.Eventable, error) {
+	client, err := g.graphClient()
+	if err != nil {
+		return nil, err
 	}
 
 	// Configuration for the request
 	requestConfig := &users.ItemCalendarViewRequestBuilderGetRequestConfiguration{
-		QueryParameters: queryParams,
+		QueryParameters: calendarViewQuery(start, end),
 	}
-
-	// Get the calendar view of the room
-	events, err := g.appClient.Users().ByUserId(roomId).CalendarView().Get(context.Background(), requestConfig)
-	if err != nil {
-		fmt.Println("Failed to get calendar view:", err)
-		return
+	if timeZone != "" {
+		requestConfig.Headers = abstractions.NewRequestHeaders()
+		requestConfig.Headers.Add("Prefer", fmt.Sprintf("outlook.timezone=%q", timeZone))
 	}
 
-	for _, event := range events.GetValue() {
-		fmt.Printf("Event Id : %s\n", *event.GetId())
-		fmt.Printf("  Subject: %s\n", *event.GetSubject())
-		fmt.Printf("  Start: %s, End: %s\n",
-			*event.GetStart().GetDateTime(),
-			*event.GetEnd().GetDateTime())
-		// Print start and end in local time
+	// Get the calendar view of the room, one page at a time
+	result, err := client.Users().ByUserId(roomId).CalendarView().Get(context.Background(), requestConfig)
 
-		localStart, err := ConvertToLocalTime(*event.GetStart().GetDateTime())
+	var events []models.Eventable
+	for page := 1; ; page++ {
 		if err != nil {
-			fmt.Println("Failed to convert start time to local:", err)
-			continue
-		} else {
-			fmt.Printf("  Local Start: %v\n", localStart)
+			g.reportProgress("events", page, len(events), true)
+			return nil, err
 		}
-		localEnd, err := ConvertToLocalTime(*event.GetEnd().GetDateTime())
-		if err != nil {
-			fmt.Println("Failed to convert end time to local:", err)
-			continue
-		} else {
-			fmt.Printf("  Local End: %v\n", localEnd)
+		events = append(events, result.GetValue()...)
+
+		nextLink := result.GetOdataNextLink()
+		if nextLink == nil {
+			g.reportProgress("events", page, len(events), true)
+			return events, nil
 		}
-		fmt.Printf("  OnlineMeeting: %t\n", *event.GetIsOnlineMeeting())
-		fmt.Printf("  isOrganiser: %t\n", *event.GetIsOrganizer())
-		fmt.Printf("  isCancelled: %t\n", *event.GetIsCancelled())
-		fmt.Printf("  Organiser: %v\n", *event.GetOrganizer().GetEmailAddress().GetAddress())
+		g.reportProgress("events", page, len(events), false)
+
+		// the next link already carries the start and end of the window, only the headers are needed again
+		result, err = client.Users().ByUserId(roomId).CalendarView().WithUrl(*nextLink).
+			Get(context.Background(), &users.ItemCalendarViewRequestBuilderGetRequestConfiguration{
+				Headers: requestConfig.Headers,
+			})
 	}
 }
 
+// FindRoomEvents returns the events in a room's calendar between start and end whose
+// subject contains the search text, ignoring case.
+//
+// Parameters:
+//   - roomId: The ID or email of the room or user.
+//   - subjectContains: The text to look for in each event's subject.
+//   - start: The start of the window.
+//   - end: The end of the window.
+//
+// Returns:
+//   - []models.Eventable: The matching events.
+//   - error: An error object if the request fails, otherwise nil.
+func (g *GraphHelper) FindRoomEvents(roomId string, subjectContains string, start time.Time, end time.Time) ([]models.Eventable, error) {
+
+	events, err := g.GetCalendarView(roomId, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get calendar view: %v", err)
+	}
+
+	// calendarView does not support $filter or $search, so match here
+	search := strings.ToLower(subjectContains)
+	var matches []models.Eventable
+	for _, event := range events {
+		if event.GetSubject() != nil && strings.Contains(strings.ToLower(*event.GetSubject()), search) {
+			matches = append(matches, event)
+		}
+	}
+	return matches, nil
+}
+
+// PrintEvent prints an event's id, subject, times in UTC and local time, and organiser.
+// Times fetched in a mailbox time zone are printed in that zone instead of being converted.
+// Fields Graph leaves unset are shown as "-".
+func (g *GraphHelper) PrintEvent(event models.Eventable) {
+	if event != nil {
+		g.SetLastId(event.GetId())
+	}
+	g.NewEventSummary(event).WriteText(os.Stdout)
+}
+
+// timeZoneOf returns the time zone of a Graph date, time and zone, which is UTC unless
+// another zone was asked for.
+func timeZoneOf(value models.DateTimeTimeZoneable) string {
+	if value == nil || value.GetTimeZone() == nil || *value.GetTimeZone() == "" {
+		return "UTC"
+	}
+	return *value.GetTimeZone()
+}
+
+// dateTimeOf returns the date and time of a Graph date, time and zone, or nil when it is not set.
+func dateTimeOf(value models.DateTimeTimeZoneable) *string {
+	if value == nil {
+		return nil
+	}
+	return value.GetDateTime()
+}
+
+// boolOrDefault formats a Graph boolean field, or returns the fallback when the field is not set.
+func boolOrDefault(value *bool, fallback string) string {
+	if value == nil {
+		return fallback
+	}
+	return fmt.Sprintf("%t", *value)
+}
+
+// LocalTime converts a UTC date and time returned by Graph to the configured "TIME_ZONE".
+func (g *GraphHelper) LocalTime(timeString string) (time.Time, error) {
+	t, err := ConvertToLocalTime(timeString)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.In(g.Config().TimeZone), nil
+}
+
+// ConvertToLocalTime converts a UTC date and time returned by Graph to the system time zone.
 func ConvertToLocalTime(timeString string) (time.Time, error) {
 
-	// Parse the input string in RFC3339Nano format
-	//t, err := time.Parse(time.RFC3339Nano, timeString) // this fromat does not work, as it exptects the timezone added.
-	t, err := time.Parse("2006-01-02T15:04:05.999999999", timeString)
+	t, err := parseFromGraph(timeString)
 
 	if err != nil {
 		return time.Time{}, err
@@ -245,49 +729,198 @@ func ConvertToLocalTime(timeString string) (time.Time, error) {
 	return localTime, nil
 }
 
-// Function to create a Microsoft Graph subscription for room events
-func (g *GraphHelper) CreateRoomSubscription(roomID string) error {
+// eventsResource returns the subscription resource for a user's or room's events.
+func eventsResource(userId string) string {
+	return fmt.Sprintf("/users/%s/events", userId)
+}
 
-	println("CreateRoomSubscription" + roomID)
+// groupEventsResource returns the subscription resource for the events of a group's calendar.
+func groupEventsResource(groupId string) string {
+	return fmt.Sprintf("/groups/%s/events", groupId)
+}
 
-	// Define subscription parameters
-	subscription := models.NewSubscription()
-	changeType := "created,updated,deleted"
-	subscription.SetChangeType(&changeType)
-	notificationURL := g.GetNotificationUrl()
-	if notificationURL == "" {
-		log.Fatal("ENDPOINT is not set in .env file")
-		return fmt.Errorf("ENDPOINT is not set in .env file")
+// sameResource compares two subscription resources. Graph echoes resources back
+// without the leading slash and may change the case of the mailbox.
+func sameResource(a string, b string) bool {
+	return strings.EqualFold(strings.Trim(a, "/"), strings.Trim(b, "/"))
+}
+
+// findSubscription returns the subscription delivering notifications for resource to notificationURL, or nil.
+func (g *GraphHelper) findSubscription(resource string, notificationURL string) (models.Subscriptionable, error) {
+
+	subscriptions, err := g.ListSubscriptions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions: %v", err)
 	}
-	subscription.SetNotificationUrl(&notificationURL)
+
+	for _, subscription := range subscriptions {
+		if subscription.GetId() == nil || subscription.GetResource() == nil || subscription.GetNotificationUrl() == nil {
+			continue
+		}
+		if sameResource(*subscription.GetResource(), resource) && *subscription.GetNotificationUrl() == notificationURL {
+			return subscription, nil
+		}
+	}
+	return nil, nil
+}
+
+// FindEventsSubscription looks for an existing subscription to the events of the given user or room.
+//
+// Parameters:
+//   - userId: The ID or email of the user or room.
+//
+// Returns:
+//   - models.Subscriptionable: The matching subscription, or nil if there is none.
+//   - error: An error object if listing subscriptions fails, otherwise nil.
+func (g *GraphHelper) FindEventsSubscription(userId string) (models.Subscriptionable, error) {
+
+	subscriptions, err := g.ListSubscriptions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions: %v", err)
+	}
+
+	resource := eventsResource(userId)
+	for _, subscription := range subscriptions {
+		if subscription.GetResource() != nil && sameResource(*subscription.GetResource(), resource) {
+			return subscription, nil
+		}
+	}
+	return nil, nil
+}
+
+// Function to create a Microsoft Graph subscription for room events.
+// If a subscription for the same resource and notification URL already exists it is renewed
+// for another day instead of creating a duplicate, unless force is set.
+// Returns the id of the renewed or created subscription.
+func (g *GraphHelper) CreateRoomSubscription(roomID string, force bool) (string, error) {
+	if err := validateUserId("room", roomID); err != nil {
+		return "", err
+	}
+
 	//subResource := fmt.Sprintf("/places/microsoft.graph.room/%s", roomID)
-	subResource := fmt.Sprintf("/users/%s/events", roomID)
-	subscription.SetResource(&subResource)
-	// End time is today.
-	//expirationDateTime, err := time.Now().Format(time.RFC3339)
+	return g.createEventsSubscription(context.Background(), eventsResource(roomID), force)
+}
+
+// CreateGroupEventsSubscription creates a subscription for the events of a Microsoft 365 group's calendar.
+// One subscription covers every event in the group calendar, whoever books it, so rooms that are
+// booked through a group calendar need no subscription of their own. As with CreateRoomSubscription
+// an existing subscription for the group and notification URL is renewed unless force is set.
+//
+// Parameters:
+//   - ctx: Cancels the requests.
+//   - groupId: The object ID of the Microsoft 365 group.
+//   - force: Create a new subscription even if one already exists.
+//
+// Returns:
+//   - string: The id of the renewed or created subscription.
+//   - error: An error object if the group id is invalid or the request fails, otherwise nil.
+func (g *GraphHelper) CreateGroupEventsSubscription(ctx context.Context, groupId string, force bool) (string, error) {
+	groupId = strings.TrimSpace(groupId)
+	if !objectIdPattern.MatchString(groupId) {
+		return "", fmt.Errorf("group id %q is not an object id, Graph only accepts a group's id in a subscription", groupId)
+	}
+	return g.createEventsSubscription(ctx, groupEventsResource(groupId), force)
+}
+
+// createEventsSubscription creates, or unless force is set renews, a one day subscription to
+// changes of the events in resource, delivered to "ENDPOINT".
+func (g *GraphHelper) createEventsSubscription(ctx context.Context, subResource string, force bool) (string, error) {
+	client, err := g.graphClient()
+	if err != nil {
+		return "", err
+	}
+
+	notificationURL := g.Config().Endpoint
+	// Expires a day from now
 	tomorrow := time.Now().Add(24 * time.Hour)
-	tomorrow.Format("2016-11-20T18:23:45.9356913Z")
-	//expirationDateTime, err := time.Parse(tomorrow, "2016-11-20T18:23:45.9356913Z")
-	//expirationDateTime, err := time.Parse(time.RFC3339, "2016-11-20T18:23:45.9356913Z")
-	//if err != nil {
-	//	return fmt.Errorf("failed to parse expiration datetime: %v", err)
-	//}
-	subscription.SetExpirationDateTime(&tomorrow)
-	//subscription.SetExpirationDateTime(&expirationDateTime)
-
-	//	clientState := "secretClientValue"
-	//	subscription.SetClientState(&clientState)
-	//	latestSupportedTlsVersion := "v1_2"
-	//	subscription.SetLatestSupportedTlsVersion(&latestSupportedTlsVersion)
+	subscription := g.newSubscription(subResource, eventChangeTypes, notificationURL, tomorrow)
+	subResource = *subscription.GetResource()
+
+	if !force {
+		existing, err := g.findSubscription(subResource, notificationURL)
+		if err != nil {
+			return "", err
+		}
+		if existing != nil {
+			if err := g.RenewSubscription(*existing.GetId(), tomorrow); err != nil {
+				return "", err
+			}
+			log.Printf("Subscription renewed with ID: %s", *existing.GetId())
+			g.SetLastId(existing.GetId())
+			return *existing.GetId(), nil
+		}
+	}
 
 	// Create the subscription
-	result, err := g.appClient.Subscriptions().Post(context.Background(), subscription, nil)
+	ctx, audited := g.audit(ctx, "create subscription", subResource)
+	result, err := client.Subscriptions().Post(ctx, subscription, nil)
+	audited(err)
 	if err != nil {
-		fmt.Printf("failed to create subscription: %v", err.Error())
-		return fmt.Errorf("failed to create subscription: %v", err)
+		return "", fmt.Errorf("failed to create subscription: %v", err)
 	}
 
 	log.Printf("Subscription created with ID: %s", *result.GetId())
+	g.SetLastId(result.GetId())
+	return *result.GetId(), nil
+}
+
+// eventChangeTypes are the changes event subscriptions are notified of.
+const eventChangeTypes = "created,updated,deleted"
+
+// newSubscription returns a subscription to changeType changes of resource delivered to
+// notificationURL, with the client state, TLS version and rich notification certificate from
+// the configuration. Rich notifications add a $select to the resource.
+func (g *GraphHelper) newSubscription(resource string, changeType string, notificationURL string, expiration time.Time) models.Subscriptionable {
+	config := g.Config()
+	subscription := models.NewSubscription()
+	subscription.SetChangeType(&changeType)
+	subscription.SetNotificationUrl(&notificationURL)
+	if certificate := g.getNotificationCertificate(); certificate != nil {
+		// rich notifications carry the event itself, encrypted with our certificate
+		resource += "?$select=" + richNotificationFields
+		includeResourceData := true
+		encryptionCertificate := certificate.encodedCertificate()
+		subscription.SetIncludeResourceData(&includeResourceData)
+		subscription.SetEncryptionCertificate(&encryptionCertificate)
+		subscription.SetEncryptionCertificateId(&certificate.Id)
+	}
+	subscription.SetResource(&resource)
+	subscription.SetExpirationDateTime(&expiration)
+
+	// Notifications echo the client state, so the webhook can tell them from forged ones
+	if clientState := config.WebhookClientState; clientState != "" {
+		subscription.SetClientState(&clientState)
+	}
+
+	// Some tenants reject subscriptions that do not declare the TLS version of the endpoint
+	latestSupportedTlsVersion := config.SubscriptionTLSVersion
+	subscription.SetLatestSupportedTlsVersion(&latestSupportedTlsVersion)
+	return subscription
+}
+
+// RenewSubscription moves the expiry of a subscription to the given time.
+//
+// Parameters:
+//   - subscriptionId: The ID of the subscription to renew.
+//   - expiration: The new expiry, which Graph limits to a few days for most resources.
+//
+// Returns:
+//   - error: An error object if the renewal fails, otherwise nil.
+func (g *GraphHelper) RenewSubscription(subscriptionId string, expiration time.Time) error {
+	client, err := g.graphClient()
+	if err != nil {
+		return err
+	}
+
+	subscription := models.NewSubscription()
+	subscription.SetExpirationDateTime(&expiration)
+
+	ctx, audited := g.audit(context.Background(), "renew subscription", subscriptionId)
+	_, err = client.Subscriptions().BySubscriptionId(subscriptionId).Patch(ctx, subscription, nil)
+	audited(err)
+	if err != nil {
+		return fmt.Errorf("failed to renew subscription: %v", err)
+	}
 	return nil
 }
 
@@ -300,11 +933,16 @@ func (g *GraphHelper) CreateRoomSubscription(roomID string) error {
 // Returns:
 //   - error: An error object if the deletion fails, otherwise nil.
 func (g *GraphHelper) DeleteSubscription(subscriptionId string) error {
+	client, err := g.graphClient()
+	if err != nil {
+		return err
+	}
 
-	err := g.appClient.Subscriptions().BySubscriptionId(subscriptionId).Delete(context.Background(), nil)
+	ctx, audited := g.audit(context.Background(), "delete subscription", subscriptionId)
+	err = client.Subscriptions().BySubscriptionId(subscriptionId).Delete(ctx, nil)
+	audited(err)
 	if err != nil {
-		fmt.Printf("failed to delete subscription: %v", err.Error())
-		return fmt.Errorf("failed to create subscription: %v", err)
+		return fmt.Errorf("failed to delete subscription: %v", err)
 	}
 	return nil
 }
@@ -318,15 +956,140 @@ func (g *GraphHelper) DeleteSubscription(subscriptionId string) error {
 // Returns:
 //   - error: An error object if the deletion fails, otherwise nil.
 func (g *GraphHelper) DeleteEvent(userId string, eventId string) error {
+	client, err := g.graphClient()
+	if err != nil {
+		return err
+	}
+
+	// an email that is not the user's UPN is not accepted in the path
+	userId, err = g.mailboxId(context.Background(), userId)
+	if err != nil {
+		return err
+	}
 
 	requestBody := users.NewItemEventsItemCancelPostRequestBody()
 	comment := "System Canceled Event"
 	requestBody.SetComment(&comment) // Initialize a new Graph client
 
-	err := g.appClient.Users().ByUserId(userId).Events().ByEventId(eventId).Delete(context.Background(), nil)
+	ctx, audited := g.audit(context.Background(), "delete event", userId+"/events/"+eventId)
+	err = client.Users().ByUserId(userId).Events().ByEventId(eventId).Delete(ctx, nil)
+	audited(err)
 	if err != nil {
-		fmt.Printf("failed to delete event: %v", err.Error())
 		return fmt.Errorf("failed to delete event: %v", err)
 	}
 	return nil
 }
+
+// ListCalendars retrieves the calendars owned by the given user or room mailbox.
+//
+// Parameters:
+//   - userId: The ID or email of the user whose calendars are listed.
+//
+// Returns:
+//   - []models.Calendarable: The user's calendars, including the primary one.
+//   - error: An error object if the request fails, otherwise nil.
+func (g *GraphHelper) ListCalendars(userId string) ([]models.Calendarable, error) {
+	client, err := g.graphClient()
+	if err != nil {
+		return nil, err
+	}
+
+	calendars, err := client.Users().ByUserId(userId).Calendars().Get(context.Background(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list calendars: %v", err)
+	}
+	return calendars.GetValue(), nil
+}
+
+// newDateTimeTimeZone converts a time to the UTC date and time pair Graph expects in requests.
+func newDateTimeTimeZone(t time.Time) models.DateTimeTimeZoneable {
+	timeZone := "UTC"
+	dateTime := formatForGraph(t)
+	value := models.NewDateTimeTimeZone()
+	value.SetDateTime(&dateTime)
+	value.SetTimeZone(&timeZone)
+	return value
+}
+
+// eventDateTimeTimeZone converts a time to a date and time in the configured "TIME_ZONE", so the
+// event keeps the organiser's zone across daylight saving changes. Without a zone that maps to a
+// Windows time zone it falls back to UTC.
+func (g *GraphHelper) eventDateTimeTimeZone(t time.Time) models.DateTimeTimeZoneable {
+	timeZone, err := g.windowsTimeZone()
+	if err != nil {
+		return newDateTimeTimeZone(t)
+	}
+	dateTime := t.In(g.Config().TimeZone).Format(graphDateTimeLayout)
+	value := models.NewDateTimeTimeZone()
+	value.SetDateTime(&dateTime)
+	value.SetTimeZone(&timeZone)
+	return value
+}
+
+// CreateEvent creates an event in the organiser's calendar and invites the room as a resource attendee.
+// Creating the same booking again within TransactionWindow does not create a second event.
+//
+// Parameters:
+//   - organiser: The ID or email of the user who owns the event.
+//   - roomEmail: The email of the room to book.
+//   - calendarId: The calendar to create the event in. An empty string uses the primary calendar.
+//   - subject: The subject of the event.
+//   - start: The start of the event.
+//   - end: The end of the event.
+//   - attendees: The people and resources to invite besides the room.
+//
+// Returns:
+//   - models.Eventable: The event as created by Graph.
+//   - error: An error object if the creation fails, otherwise nil.
+func (g *GraphHelper) CreateEvent(organiser string, roomEmail string, calendarId string, subject string, start time.Time, end time.Time, attendees []Attendee) (models.Eventable, error) {
+	client, err := g.graphClient()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateUserId("organiser", organiser); err != nil {
+		return nil, err
+	}
+	if err := validateEmail("room", roomEmail); err != nil {
+		return nil, err
+	}
+	organiser, err = g.mailboxId(context.Background(), organiser)
+	if err != nil {
+		return nil, err
+	}
+
+	event := models.NewEvent()
+	event.SetSubject(&subject)
+	transactionId := eventTransactionId(roomEmail, start, subject, time.Now())
+	event.SetTransactionId(&transactionId)
+
+	event.SetStart(g.eventDateTimeTimeZone(start))
+	event.SetEnd(g.eventDateTimeTimeZone(end))
+
+	// Book the room by inviting it as a resource
+	eventAttendees := []models.Attendeeable{newAttendee(roomEmail, models.RESOURCE_ATTENDEETYPE)}
+	for _, attendee := range attendees {
+		eventAttendees = append(eventAttendees, newAttendee(attendee.Email, attendee.Type))
+	}
+	event.SetAttendees(eventAttendees)
+
+	location := models.NewLocation()
+	location.SetDisplayName(&roomEmail)
+	location.SetLocationEmailAddress(&roomEmail)
+	event.SetLocation(location)
+
+	var createdEvent models.Eventable
+	if calendarId == "" {
+		ctx, audited := g.audit(context.Background(), "create event", organiser+"/events in "+roomEmail)
+		createdEvent, err = client.Users().ByUserId(organiser).Events().Post(ctx, event, nil)
+		audited(err)
+	} else {
+		ctx, audited := g.audit(context.Background(), "create event", organiser+"/calendars/"+calendarId+"/events in "+roomEmail)
+		createdEvent, err = client.Users().ByUserId(organiser).Calendars().ByCalendarId(calendarId).Events().Post(ctx, event, nil)
+		audited(err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create event: %v", err)
+	}
+	return createdEvent, nil
+}
@@ -0,0 +1,98 @@
+package graphhelper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+// keyVaultAPIVersion is the Key Vault REST API version used to read secrets.
+const keyVaultAPIVersion = "7.4"
+
+// secretNamePattern matches the names Key Vault allows for secrets.
+var secretNamePattern = regexp.MustCompile(`^[0-9A-Za-z-]{1,127}$`)
+
+// clientSecret returns the secret the app signs in with: read from "KEYVAULT_URL" using the
+// managed identity of the host when a Key Vault is configured, otherwise "CLIENT_SECRET".
+func (g *GraphHelper) clientSecret(ctx context.Context) (string, error) {
+	config := g.Config()
+	if config.KeyVaultURL == "" {
+		return config.ClientSecret, nil
+	}
+
+	identity, err := azidentity.NewManagedIdentityCredential(&azidentity.ManagedIdentityCredentialOptions{
+		ClientOptions: azcore.ClientOptions{Cloud: clouds[config.Cloud].authority},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create managed identity credential: %v", err)
+	}
+	token, err := identity.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{config.vaultScope()}})
+	if err != nil {
+		return "", fmt.Errorf("failed to get Key Vault token: %v", err)
+	}
+
+	secret, err := fetchKeyVaultSecret(ctx, http.DefaultClient, config.KeyVaultURL, config.SecretName, token.Token)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret %s from Key Vault: %v", config.SecretName, err)
+	}
+	return secret, nil
+}
+
+// fetchKeyVaultSecret reads the current version of a secret from a Key Vault.
+//
+// Parameters:
+//   - ctx: The context of the request.
+//   - client: The HTTP client to send the request with.
+//   - vaultURL: The vault's URL, such as https://my-vault.vault.azure.net.
+//   - name: The name of the secret.
+//   - token: A bearer token for the vault.
+//
+// Returns:
+//   - string: The value of the secret.
+//   - error: An error object if the request fails or the vault refuses it, otherwise nil.
+func fetchKeyVaultSecret(ctx context.Context, client *http.Client, vaultURL string, name string, token string) (string, error) {
+
+	secretURL := strings.TrimRight(vaultURL, "/") + "/secrets/" + url.PathEscape(name) + "?api-version=" + keyVaultAPIVersion
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, secretURL, nil)
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Authorization", "Bearer "+token)
+
+	response, err := client.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %v", err)
+	}
+	var secret struct {
+		Value string `json:"value"`
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return "", fmt.Errorf("failed to parse response (%s): %v", response.Status, err)
+	}
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s: %s %s", response.Status, secret.Error.Code, secret.Error.Message)
+	}
+	if secret.Value == "" {
+		return "", fmt.Errorf("secret %s is empty", name)
+	}
+	return secret.Value, nil
+}
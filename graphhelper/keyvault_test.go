@@ -0,0 +1,58 @@
+package graphhelper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFetchKeyVaultSecret(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    string
+		wantErr string
+	}{
+		{"found", http.StatusOK, `{"value":"s3cret","id":"https://vault/secrets/graph/1"}`, "s3cret", ""},
+		{"forbidden", http.StatusForbidden, `{"error":{"code":"Forbidden","message":"Caller is not authorized"}}`, "", "403 Forbidden: Forbidden Caller is not authorized"},
+		{"empty", http.StatusOK, `{"value":""}`, "", "secret graph-secret is empty"},
+		{"not json", http.StatusBadGateway, `bad gateway`, "", "failed to parse response (502 Bad Gateway)"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/secrets/graph-secret" || r.URL.Query().Get("api-version") != keyVaultAPIVersion {
+					t.Errorf("request = %s, want the graph-secret secret", r.URL)
+				}
+				if got := r.Header.Get("Authorization"); got != "Bearer token" {
+					t.Errorf("Authorization = %q, want the bearer token", got)
+				}
+				w.WriteHeader(test.status)
+				w.Write([]byte(test.body))
+			}))
+			defer server.Close()
+
+			got, err := fetchKeyVaultSecret(context.Background(), server.Client(), server.URL+"/", "graph-secret", "token")
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("err = %v, want %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil || got != test.want {
+				t.Errorf("fetchKeyVaultSecret() = %q, %v, want %q", got, err, test.want)
+			}
+		})
+	}
+}
+
+func TestClientSecretWithoutKeyVault(t *testing.T) {
+	g := NewGraphHelper(&Config{ClientSecret: "from-env"})
+	if got, err := g.clientSecret(context.Background()); err != nil || got != "from-env" {
+		t.Errorf("clientSecret() = %q, %v, want the CLIENT_SECRET value", got, err)
+	}
+}
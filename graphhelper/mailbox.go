@@ -0,0 +1,34 @@
+package graphhelper
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// GetMailboxSettings retrieves the mailbox settings of a user or room, including its
+// time zone, working hours and automatic replies.
+//
+// Parameters:
+//   - userId: The ID or email of the user or room.
+//
+// Returns:
+//   - models.MailboxSettingsable: The mailbox settings.
+//   - error: An error object if the request fails, otherwise nil.
+func (g *GraphHelper) GetMailboxSettings(userId string) (models.MailboxSettingsable, error) {
+	client, err := g.graphClient()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateUserId("user", userId); err != nil {
+		return nil, err
+	}
+
+	settings, err := client.Users().ByUserId(userId).MailboxSettings().Get(context.Background(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mailbox settings: %v", err)
+	}
+	return settings, nil
+}
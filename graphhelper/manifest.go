@@ -0,0 +1,262 @@
+package graphhelper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// DefaultSubscriptionManifest is the file the desired subscriptions are saved to.
+const DefaultSubscriptionManifest = "subscriptions.json"
+
+// SubscriptionSpec is one subscription the tenant should have.
+type SubscriptionSpec struct {
+	Resource        string `json:"resource"`
+	ChangeType      string `json:"changeType"`
+	NotificationUrl string `json:"notificationUrl"`
+}
+
+// key identifies the spec when matching it to subscriptions. Resources are compared like
+// sameResource does, and change types as a set.
+func (s SubscriptionSpec) key() string {
+	changeTypes := strings.Split(strings.ToLower(strings.ReplaceAll(s.ChangeType, " ", "")), ",")
+	slices.Sort(changeTypes)
+	return strings.ToLower(strings.Trim(s.Resource, "/")) + " " + strings.Join(changeTypes, ",") + " " + s.NotificationUrl
+}
+
+// SubscriptionManifest is the set of subscriptions the tenant should have, saved as JSON.
+type SubscriptionManifest struct {
+	Subscriptions []SubscriptionSpec `json:"subscriptions"`
+}
+
+// specOf returns the spec a subscription meets. The $select rich notifications add is left
+// out, as it is added again whenever the subscription is created.
+func specOf(subscription models.Subscriptionable) SubscriptionSpec {
+	return SubscriptionSpec{
+		Resource:        strings.SplitN(StringOrDefault(subscription.GetResource(), ""), "?", 2)[0],
+		ChangeType:      StringOrDefault(subscription.GetChangeType(), ""),
+		NotificationUrl: StringOrDefault(subscription.GetNotificationUrl(), ""),
+	}
+}
+
+// CurrentSubscriptionManifest returns the subscriptions the tenant has now as a manifest.
+//
+// Returns:
+//   - SubscriptionManifest: A spec for each subscription, without duplicates.
+//   - error: An error object if listing the subscriptions fails, otherwise nil.
+func (g *GraphHelper) CurrentSubscriptionManifest() (SubscriptionManifest, error) {
+
+	subscriptions, err := g.ListSubscriptions()
+	if err != nil {
+		return SubscriptionManifest{}, fmt.Errorf("failed to list subscriptions: %v", err)
+	}
+
+	var manifest SubscriptionManifest
+	seen := map[string]bool{}
+	for _, subscription := range subscriptions {
+		spec := specOf(subscription)
+		if spec.Resource == "" || seen[spec.key()] {
+			continue
+		}
+		seen[spec.key()] = true
+		manifest.Subscriptions = append(manifest.Subscriptions, spec)
+	}
+	return manifest, nil
+}
+
+// LoadSubscriptionManifest reads a manifest saved by SaveSubscriptionManifest.
+//
+// Parameters:
+//   - path: The manifest file.
+//
+// Returns:
+//   - SubscriptionManifest: The desired subscriptions.
+//   - error: An error object if the file cannot be read or a spec is incomplete, otherwise nil.
+func LoadSubscriptionManifest(path string) (SubscriptionManifest, error) {
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return SubscriptionManifest{}, fmt.Errorf("failed to read manifest: %v", err)
+	}
+	var manifest SubscriptionManifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return SubscriptionManifest{}, fmt.Errorf("failed to parse manifest %s: %v", path, err)
+	}
+	for i, spec := range manifest.Subscriptions {
+		if spec.Resource == "" || spec.ChangeType == "" || spec.NotificationUrl == "" {
+			return SubscriptionManifest{}, fmt.Errorf("subscription %d in manifest %s needs a resource, changeType and notificationUrl", i+1, path)
+		}
+	}
+	return manifest, nil
+}
+
+// SaveSubscriptionManifest writes a manifest to a temporary file beside path, then renames it
+// into place, so an interrupted save leaves the previous manifest intact.
+//
+// Parameters:
+//   - path: The manifest file. An existing file is replaced.
+//   - manifest: The desired subscriptions.
+//
+// Returns:
+//   - error: An error object if the file cannot be written, otherwise nil.
+func SaveSubscriptionManifest(path string, manifest SubscriptionManifest) error {
+
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %v", err)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), ".subscriptions-*")
+	if err != nil {
+		return fmt.Errorf("failed to write manifest: %v", err)
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(append(content, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write manifest: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write manifest: %v", err)
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("failed to write manifest: %v", err)
+	}
+	return nil
+}
+
+// The kinds of ReconcileAction.
+const (
+	ReconcileCreate = "create"
+	ReconcileRenew  = "renew"
+	ReconcileDelete = "delete"
+)
+
+// ReconcileAction is one change that makes the tenant's subscriptions match a manifest.
+type ReconcileAction struct {
+	Kind           string // ReconcileCreate, ReconcileRenew or ReconcileDelete
+	Spec           SubscriptionSpec
+	SubscriptionId string // empty for ReconcileCreate until the subscription is created
+	Err            error  // why the action failed, nil if it succeeded or has not been taken
+}
+
+// String describes the action for the report.
+func (a ReconcileAction) String() string {
+	description := fmt.Sprintf("%-6s %s (%s) -> %s", a.Kind, a.Spec.Resource, a.Spec.ChangeType, a.Spec.NotificationUrl)
+	if a.SubscriptionId != "" {
+		description += " [" + a.SubscriptionId + "]"
+	}
+	if a.Err != nil {
+		description += ": " + a.Err.Error()
+	}
+	return description
+}
+
+// planReconcile works out the actions that make subscriptions match the manifest: each spec
+// is met by renewing the first subscription meeting it or, when none does, by creating one.
+// Every other subscription, including a second one meeting the same spec, is deleted.
+func planReconcile(manifest SubscriptionManifest, subscriptions []models.Subscriptionable) []ReconcileAction {
+	var actions []ReconcileAction
+	kept := map[string]bool{}
+	for _, spec := range manifest.Subscriptions {
+		if kept[spec.key()] {
+			continue
+		}
+		kept[spec.key()] = true
+		action := ReconcileAction{Kind: ReconcileCreate, Spec: spec}
+		for _, subscription := range subscriptions {
+			if subscription.GetId() != nil && specOf(subscription).key() == spec.key() {
+				action = ReconcileAction{Kind: ReconcileRenew, Spec: spec, SubscriptionId: *subscription.GetId()}
+				break
+			}
+		}
+		actions = append(actions, action)
+	}
+
+	renewed := map[string]bool{}
+	for _, action := range actions {
+		renewed[action.SubscriptionId] = action.Kind == ReconcileRenew
+	}
+	for _, subscription := range subscriptions {
+		if subscription.GetId() == nil || renewed[*subscription.GetId()] {
+			continue
+		}
+		actions = append(actions, ReconcileAction{Kind: ReconcileDelete, Spec: specOf(subscription), SubscriptionId: *subscription.GetId()})
+	}
+	return actions
+}
+
+// PlanReconcile lists the subscriptions and works out the actions that make them match the
+// manifest, without taking them.
+//
+// Parameters:
+//   - manifest: The desired subscriptions.
+//
+// Returns:
+//   - []ReconcileAction: The creations and renewals in manifest order, then the deletions.
+//   - error: An error object if listing the subscriptions fails, otherwise nil.
+func (g *GraphHelper) PlanReconcile(manifest SubscriptionManifest) ([]ReconcileAction, error) {
+
+	subscriptions, err := g.ListSubscriptions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions: %v", err)
+	}
+	return planReconcile(manifest, subscriptions), nil
+}
+
+// ApplyReconcile takes the actions from PlanReconcile, renewing and creating subscriptions
+// for a day like the menu does. Each action's outcome is recorded in it, and a failed action
+// does not stop the others.
+//
+// Parameters:
+//   - ctx: Cancels the requests.
+//   - actions: The actions to take, updated with the id of each created subscription and any error.
+//
+// Returns:
+//   - error: An error object if any action failed, otherwise nil.
+func (g *GraphHelper) ApplyReconcile(ctx context.Context, actions []ReconcileAction) error {
+
+	client, err := g.graphClient()
+	if err != nil {
+		return err
+	}
+
+	expiration := time.Now().Add(24 * time.Hour)
+	failed := 0
+	for i := range actions {
+		action := &actions[i]
+		switch action.Kind {
+		case ReconcileRenew:
+			action.Err = g.RenewSubscription(action.SubscriptionId, expiration)
+		case ReconcileDelete:
+			action.Err = g.DeleteSubscription(action.SubscriptionId)
+		case ReconcileCreate:
+			subscription := g.newSubscription(action.Spec.Resource, action.Spec.ChangeType, action.Spec.NotificationUrl, expiration)
+			createCtx, audited := g.audit(ctx, "create subscription", action.Spec.Resource)
+			result, err := client.Subscriptions().Post(createCtx, subscription, nil)
+			audited(err)
+			if err != nil {
+				action.Err = fmt.Errorf("failed to create subscription: %v", err)
+			} else if result.GetId() != nil {
+				action.SubscriptionId = *result.GetId()
+			}
+		default:
+			action.Err = errors.New("unknown action")
+		}
+		if action.Err != nil {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to take %d of %d actions", failed, len(actions))
+	}
+	return nil
+}
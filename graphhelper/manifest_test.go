@@ -0,0 +1,80 @@
+package graphhelper
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+func TestPlanReconcile(t *testing.T) {
+	subscription := func(id string, resource string, changeType string, notificationURL string) models.Subscriptionable {
+		s := models.NewSubscription()
+		s.SetId(&id)
+		s.SetResource(&resource)
+		s.SetChangeType(&changeType)
+		s.SetNotificationUrl(&notificationURL)
+		return s
+	}
+	endpoint := "https://example.com/webhook"
+	room, organiser := eventsResource("room@example.com"), eventsResource("organiser@example.com")
+	manifest := SubscriptionManifest{Subscriptions: []SubscriptionSpec{
+		{Resource: room, ChangeType: eventChangeTypes, NotificationUrl: endpoint},
+		{Resource: organiser, ChangeType: eventChangeTypes, NotificationUrl: endpoint},
+	}}
+
+	actions := planReconcile(manifest, []models.Subscriptionable{
+		// Graph's echo of the resource, with the $select of rich notifications
+		subscription("sub-1", "Users/Room@example.com/Events?$select=subject", "deleted,created,updated", endpoint),
+		subscription("sub-2", room, eventChangeTypes, endpoint),
+		subscription("sub-3", organiser, eventChangeTypes, "https://old.example.com/webhook"),
+	})
+
+	want := []string{
+		"renew  " + room + " (" + eventChangeTypes + ") -> " + endpoint + " [sub-1]",
+		"create " + organiser + " (" + eventChangeTypes + ") -> " + endpoint,
+		"delete " + room + " (" + eventChangeTypes + ") -> " + endpoint + " [sub-2]",
+		"delete " + organiser + " (" + eventChangeTypes + ") -> https://old.example.com/webhook [sub-3]",
+	}
+	if len(actions) != len(want) {
+		t.Fatalf("actions = %v, want %v", actions, want)
+	}
+	for i, action := range actions {
+		if got := action.String(); got != want[i] {
+			t.Errorf("action %d = %q, want %q", i, got, want[i])
+		}
+	}
+}
+
+func TestSubscriptionManifestRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultSubscriptionManifest)
+	manifest := SubscriptionManifest{Subscriptions: []SubscriptionSpec{
+		{Resource: "/users/room@example.com/events", ChangeType: eventChangeTypes, NotificationUrl: "https://example.com/webhook"},
+	}}
+
+	if err := SaveSubscriptionManifest(path, manifest); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	loaded, err := LoadSubscriptionManifest(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(loaded.Subscriptions) != 1 || loaded.Subscriptions[0] != manifest.Subscriptions[0] {
+		t.Errorf("loaded = %+v, want %+v", loaded, manifest)
+	}
+}
+
+func TestLoadSubscriptionManifestRejectsIncompleteSpecs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultSubscriptionManifest)
+	content := `{"subscriptions": [{"resource": "/users/room@example.com/events", "changeType": "created"}]}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := LoadSubscriptionManifest(path)
+	if err == nil || !strings.Contains(err.Error(), "subscription 1") {
+		t.Errorf("LoadSubscriptionManifest() = %v, want an error naming subscription 1", err)
+	}
+}
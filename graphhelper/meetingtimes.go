@@ -0,0 +1,90 @@
+package graphhelper
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/microsoft/kiota-abstractions-go/serialization"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+)
+
+// FindMeetingTimes asks Graph to suggest times when the attendees and the room are all free.
+//
+// Parameters:
+//   - organiser: The email of the user organising the meeting.
+//   - attendees: The emails of the required attendees.
+//   - roomEmail: The email of the room the meeting must be held in.
+//   - start: The start of the window to search.
+//   - end: The end of the window to search.
+//   - duration: The length of the meeting.
+//
+// Returns:
+//   - models.MeetingTimeSuggestionsResultable: The suggestions, or the reason there are none.
+//   - error: An error object if the request fails, otherwise nil.
+func (g *GraphHelper) FindMeetingTimes(organiser string, attendees []string, roomEmail string, start time.Time, end time.Time, duration time.Duration) (models.MeetingTimeSuggestionsResultable, error) {
+	client, err := g.graphClient()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateEmail("organiser", organiser); err != nil {
+		return nil, err
+	}
+	if err := validateEmail("room", roomEmail); err != nil {
+		return nil, err
+	}
+
+	var attendeeList []models.AttendeeBaseable
+	for _, attendee := range attendees {
+		if err := validateEmail("attendee", attendee); err != nil {
+			return nil, err
+		}
+		address := models.NewEmailAddress()
+		address.SetAddress(&attendee)
+		required := models.REQUIRED_ATTENDEETYPE
+		attendeeBase := models.NewAttendeeBase()
+		attendeeBase.SetEmailAddress(address)
+		attendeeBase.SetTypeEscaped(&required)
+		attendeeList = append(attendeeList, attendeeBase)
+	}
+
+	// The room must be free as well
+	resolveAvailability := true
+	room := models.NewLocationConstraintItem()
+	room.SetDisplayName(&roomEmail)
+	room.SetLocationEmailAddress(&roomEmail)
+	room.SetResolveAvailability(&resolveAvailability)
+
+	isRequired := true
+	suggestLocation := false
+	location := models.NewLocationConstraint()
+	location.SetIsRequired(&isRequired)
+	location.SetSuggestLocation(&suggestLocation)
+	location.SetLocations([]models.LocationConstraintItemable{room})
+
+	slot := models.NewTimeSlot()
+	slot.SetStart(newDateTimeTimeZone(start))
+	slot.SetEnd(newDateTimeTimeZone(end))
+	activityDomain := models.WORK_ACTIVITYDOMAIN
+	timeConstraint := models.NewTimeConstraint()
+	timeConstraint.SetActivityDomain(&activityDomain)
+	timeConstraint.SetTimeSlots([]models.TimeSlotable{slot})
+
+	meetingDuration := serialization.NewDuration(0, 0, 0, 0, int(duration.Minutes()), 0, 0)
+	returnReasons := true
+
+	requestBody := users.NewItemFindMeetingTimesPostRequestBody()
+	requestBody.SetAttendees(attendeeList)
+	requestBody.SetLocationConstraint(location)
+	requestBody.SetTimeConstraint(timeConstraint)
+	requestBody.SetMeetingDuration(meetingDuration)
+	requestBody.SetReturnSuggestionReasons(&returnReasons)
+
+	result, err := client.Users().ByUserId(organiser).FindMeetingTimes().Post(context.Background(), requestBody, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find meeting times: %v", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,71 @@
+package graphhelper
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RoomResult is the outcome of an operation on one room.
+type RoomResult struct {
+	Room string
+	Err  error
+}
+
+// RoomSummary collects the outcome of an operation run over several rooms, so one
+// mailbox being unavailable does not abort the others.
+type RoomSummary struct {
+	Results []RoomResult
+}
+
+// ForEachRoom runs fn for every room in turn, recording each outcome instead of stopping
+// at the first failure.
+//
+// Parameters:
+//   - rooms: The IDs or emails of the rooms.
+//   - fn: The operation to run for one room.
+//
+// Returns:
+//   - RoomSummary: The outcome for every room, in the order given.
+func ForEachRoom(rooms []string, fn func(room string) error) RoomSummary {
+	var summary RoomSummary
+	for _, room := range rooms {
+		summary.Results = append(summary.Results, RoomResult{Room: room, Err: fn(room)})
+	}
+	return summary
+}
+
+// Succeeded returns the rooms the operation worked for.
+func (s RoomSummary) Succeeded() []string {
+	var rooms []string
+	for _, result := range s.Results {
+		if result.Err == nil {
+			rooms = append(rooms, result.Room)
+		}
+	}
+	return rooms
+}
+
+// Failed returns the rooms the operation failed for, ready to be retried.
+func (s RoomSummary) Failed() []string {
+	var rooms []string
+	for _, result := range s.Results {
+		if result.Err != nil {
+			rooms = append(rooms, result.Room)
+		}
+	}
+	return rooms
+}
+
+// Err returns an error listing every failed room, or nil when all of them succeeded.
+func (s RoomSummary) Err() error {
+	var problems []string
+	for _, result := range s.Results {
+		if result.Err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", result.Room, result.Err))
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("failed for %d of %d rooms: %s", len(problems), len(s.Results), strings.Join(problems, "; "))
+}
@@ -0,0 +1,54 @@
+package graphhelper
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestForEachRoomCarriesOnAfterFailures(t *testing.T) {
+	var visited []string
+	summary := ForEachRoom([]string{"a@example.com", "b@example.com", "c@example.com"}, func(room string) error {
+		visited = append(visited, room)
+		if room == "b@example.com" {
+			return errors.New("mailbox unavailable")
+		}
+		return nil
+	})
+
+	if len(visited) != 3 {
+		t.Errorf("visited %v, want every room", visited)
+	}
+	if got, want := summary.Succeeded(), []string{"a@example.com", "c@example.com"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Succeeded() = %v, want %v", got, want)
+	}
+	if got, want := summary.Failed(), []string{"b@example.com"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Failed() = %v, want %v", got, want)
+	}
+	err := summary.Err()
+	if err == nil || !strings.Contains(err.Error(), "failed for 1 of 3 rooms: b@example.com: mailbox unavailable") {
+		t.Errorf("Err() = %v", err)
+	}
+}
+
+func TestForEachRoomRetryFailedOnly(t *testing.T) {
+	attempts := map[string]int{}
+	fn := func(room string) error {
+		attempts[room]++
+		if room == "b@example.com" && attempts[room] == 1 {
+			return errors.New("throttled")
+		}
+		return nil
+	}
+
+	summary := ForEachRoom([]string{"a@example.com", "b@example.com"}, fn)
+	retry := ForEachRoom(summary.Failed(), fn)
+
+	if retry.Err() != nil {
+		t.Errorf("retry Err() = %v, want nil", retry.Err())
+	}
+	if attempts["a@example.com"] != 1 || attempts["b@example.com"] != 2 {
+		t.Errorf("attempts = %v, want only the failed room retried", attempts)
+	}
+}
@@ -0,0 +1,74 @@
+package graphhelper
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// The organiser helpers work on the calendar of the configured "ORGANISER_EMAIL". The organiser
+// owns and creates events, rooms are only ever invited to them as resources, so callers do not
+// pass a mailbox to functions that accept either and risk creating an event as the room.
+
+// ListOrganiserEvents returns the events in the organiser's calendar between start and end.
+//
+// Parameters:
+//   - start: The start of the window.
+//   - end: The end of the window.
+//
+// Returns:
+//   - []models.Eventable: The events in the window, including occurrences of recurring events.
+//   - error: An error object if the request fails, otherwise nil.
+func (g *GraphHelper) ListOrganiserEvents(start time.Time, end time.Time) ([]models.Eventable, error) {
+	return g.GetCalendarView(g.Config().OrganiserEmail, start, end)
+}
+
+// GetOrganiserEvent returns one event from the organiser's calendar.
+//
+// Parameters:
+//   - eventId: The ID of the event in the organiser's calendar.
+//
+// Returns:
+//   - models.Eventable: The event.
+//   - error: An error object if the request fails, otherwise nil.
+func (g *GraphHelper) GetOrganiserEvent(eventId string) (models.Eventable, error) {
+	client, err := g.graphClient()
+	if err != nil {
+		return nil, err
+	}
+
+	organiser := g.Config().OrganiserEmail
+	if err := validateUserId("organiser", organiser); err != nil {
+		return nil, err
+	}
+
+	event, err := client.Users().ByUserId(organiser).Events().ByEventId(eventId).Get(context.Background(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event: %v", err)
+	}
+	return event, nil
+}
+
+// CreateOrganiserEvent creates an event in the organiser's calendar and invites the room as a resource.
+//
+// Parameters:
+//   - roomEmail: The email of the room to book.
+//   - calendarId: The organiser's calendar to create the event in. An empty string uses the primary calendar.
+//   - subject: The subject of the event.
+//   - start: The start of the event.
+//   - end: The end of the event.
+//   - attendees: The people and resources to invite besides the room.
+//
+// Returns:
+//   - models.Eventable: The event as created by Graph.
+//   - error: An error object if the creation fails, otherwise nil.
+func (g *GraphHelper) CreateOrganiserEvent(roomEmail string, calendarId string, subject string, start time.Time, end time.Time, attendees []Attendee) (models.Eventable, error) {
+	organiser := g.Config().OrganiserEmail
+	if strings.EqualFold(roomEmail, organiser) {
+		return nil, fmt.Errorf("room %s is the organiser, invite a room to the organiser's event instead", roomEmail)
+	}
+	return g.CreateEvent(organiser, roomEmail, calendarId, subject, start, end, attendees)
+}
@@ -0,0 +1,17 @@
+package graphhelper
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCreateOrganiserEventRefusesTheOrganiserAsRoom(t *testing.T) {
+	g := NewGraphHelper(&Config{OrganiserEmail: "organiser@example.com"})
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	_, err := g.CreateOrganiserEvent("Organiser@example.com", "", "Room booking", start, start.Add(30*time.Minute), nil)
+	if err == nil || !strings.Contains(err.Error(), "is the organiser") {
+		t.Errorf("CreateOrganiserEvent() = %v, want an error naming the organiser", err)
+	}
+}
@@ -0,0 +1,157 @@
+package graphhelper
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models/odataerrors"
+	"github.com/microsoftgraph/msgraph-sdk-go/serviceprincipals"
+)
+
+// AppPermission is an application permission (app role) granted to the app registration.
+type AppPermission struct {
+	Resource   string // the API granting it, such as "Microsoft Graph"
+	Permission string // such as "Calendars.ReadWrite"
+}
+
+// ErrPermissionsNotReadable is returned by GetAppPermissions when the app may not read its own
+// service principal, which needs Application.Read.All or Directory.Read.All.
+var ErrPermissionsNotReadable = errors.New("the app registration cannot read its service principal, grant Application.Read.All or Directory.Read.All to list its permissions from Graph")
+
+// GetAppPermissions lists the application permissions granted to the app registration,
+// from the app role assignments of its service principal.
+//
+// Returns:
+//   - []AppPermission: The granted permissions, sorted by resource and name.
+//   - error: ErrPermissionsNotReadable, wrapped, when Graph refuses to show them, or another
+//     error object if the request fails, otherwise nil.
+func (g *GraphHelper) GetAppPermissions() ([]AppPermission, error) {
+	client, err := g.graphClient()
+	if err != nil {
+		return nil, err
+	}
+
+	filter := fmt.Sprintf("appId eq '%s'", strings.ReplaceAll(g.Config().ClientId, "'", "''"))
+	principals, err := client.ServicePrincipals().Get(context.Background(), &serviceprincipals.ServicePrincipalsRequestBuilderGetRequestConfiguration{
+		QueryParameters: &serviceprincipals.ServicePrincipalsRequestBuilderGetQueryParameters{
+			Filter: &filter,
+			Select: []string{"id", "appId", "displayName"},
+		},
+	})
+	if err != nil {
+		return nil, permissionsError("failed to find the app's service principal", err)
+	}
+	if len(principals.GetValue()) == 0 || principals.GetValue()[0].GetId() == nil {
+		return nil, fmt.Errorf("no service principal found for CLIENT_ID %s, the app may not be consented in this tenant", g.Config().ClientId)
+	}
+	principalId := *principals.GetValue()[0].GetId()
+
+	assignments, err := client.ServicePrincipals().ByServicePrincipalId(principalId).AppRoleAssignments().Get(context.Background(), nil)
+	if err != nil {
+		return nil, permissionsError("failed to list app role assignments", err)
+	}
+
+	// assignments only carry the id of each role, the names come from the resource's service principal
+	roleNames := map[string]map[string]string{}
+	var permissions []AppPermission
+	for _, assignment := range assignments.GetValue() {
+		if assignment.GetResourceId() == nil || assignment.GetAppRoleId() == nil {
+			continue
+		}
+		resourceId := assignment.GetResourceId().String()
+		names, ok := roleNames[resourceId]
+		if !ok {
+			names, err = g.appRoleNames(resourceId)
+			if err != nil {
+				return nil, err
+			}
+			roleNames[resourceId] = names
+		}
+
+		roleId := assignment.GetAppRoleId().String()
+		name, ok := names[roleId]
+		if !ok {
+			name = roleId
+		}
+		permissions = append(permissions, AppPermission{
+			Resource:   StringOrDefault(assignment.GetResourceDisplayName(), resourceId),
+			Permission: name,
+		})
+	}
+
+	sort.Slice(permissions, func(i, j int) bool {
+		if permissions[i].Resource != permissions[j].Resource {
+			return permissions[i].Resource < permissions[j].Resource
+		}
+		return permissions[i].Permission < permissions[j].Permission
+	})
+	return permissions, nil
+}
+
+// appRoleNames returns the names of the app roles a resource's service principal defines, by role id.
+func (g *GraphHelper) appRoleNames(resourceId string) (map[string]string, error) {
+	client, err := g.graphClient()
+	if err != nil {
+		return nil, err
+	}
+
+	resource, err := client.ServicePrincipals().ByServicePrincipalId(resourceId).Get(context.Background(), &serviceprincipals.ServicePrincipalItemRequestBuilderGetRequestConfiguration{
+		QueryParameters: &serviceprincipals.ServicePrincipalItemRequestBuilderGetQueryParameters{
+			Select: []string{"id", "appRoles"},
+		},
+	})
+	if err != nil {
+		return nil, permissionsError("failed to read the app roles of "+resourceId, err)
+	}
+
+	names := map[string]string{}
+	for _, role := range resource.GetAppRoles() {
+		if role.GetId() != nil && role.GetValue() != nil {
+			names[role.GetId().String()] = *role.GetValue()
+		}
+	}
+	return names, nil
+}
+
+// permissionsError wraps err, recognising when Graph refused because the app lacks directory read permission.
+func permissionsError(what string, err error) error {
+	var odataError *odataerrors.ODataError
+	if errors.As(err, &odataError) && (odataError.GetStatusCode() == http.StatusForbidden || odataError.GetStatusCode() == http.StatusUnauthorized) {
+		return fmt.Errorf("%s: %w", what, ErrPermissionsNotReadable)
+	}
+	return fmt.Errorf("%s: %v", what, err)
+}
+
+// TokenRoles returns the application permissions in the roles claim of an app-only access
+// token, or the delegated permissions in the scp claim of a user's token. It needs no
+// directory permission, so it still works when GetAppPermissions cannot.
+func TokenRoles(token string) ([]string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("the access token is not a JWT")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid access token payload: %v", err)
+	}
+
+	var claims struct {
+		Roles []string `json:"roles"`
+		Scp   string   `json:"scp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("invalid access token claims: %v", err)
+	}
+	// A token signed in as a user, such as from the Azure CLI, has delegated scopes instead
+	if len(claims.Roles) == 0 && claims.Scp != "" {
+		claims.Roles = strings.Fields(claims.Scp)
+	}
+	sort.Strings(claims.Roles)
+	return claims.Roles, nil
+}
@@ -0,0 +1,49 @@
+package graphhelper
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models/odataerrors"
+)
+
+func TestTokenRoles(t *testing.T) {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(`{"aud":"https://graph.microsoft.com","roles":["User.Read.All","Calendars.ReadWrite"]}`))
+	token := "eyJhbGciOiJub25lIn0." + payload + ".signature"
+
+	roles, err := TokenRoles(token)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"Calendars.ReadWrite", "User.Read.All"}; !reflect.DeepEqual(roles, want) {
+		t.Errorf("TokenRoles() = %v, want %v", roles, want)
+	}
+
+	// a user's token from the Azure CLI has delegated scopes instead of roles
+	payload = base64.RawURLEncoding.EncodeToString([]byte(`{"aud":"https://graph.microsoft.com","scp":"User.Read Calendars.Read"}`))
+	roles, err = TokenRoles("eyJhbGciOiJub25lIn0." + payload + ".signature")
+	if want := []string{"Calendars.Read", "User.Read"}; err != nil || !reflect.DeepEqual(roles, want) {
+		t.Errorf("TokenRoles() = %v, %v, want the delegated scopes %v", roles, err, want)
+	}
+
+	if _, err := TokenRoles("not-a-jwt"); err == nil {
+		t.Error("TokenRoles accepted a token that is not a JWT")
+	}
+}
+
+func TestPermissionsErrorRecognisesForbidden(t *testing.T) {
+	forbidden := odataerrors.NewODataError()
+	forbidden.SetStatusCode(http.StatusForbidden)
+	if err := permissionsError("failed", forbidden); !errors.Is(err, ErrPermissionsNotReadable) {
+		t.Errorf("permissionsError(403) = %v, want ErrPermissionsNotReadable", err)
+	}
+
+	notFound := odataerrors.NewODataError()
+	notFound.SetStatusCode(http.StatusNotFound)
+	if err := permissionsError("failed", notFound); errors.Is(err, ErrPermissionsNotReadable) {
+		t.Errorf("permissionsError(404) = %v, want another error", err)
+	}
+}
@@ -0,0 +1,166 @@
+package graphhelper
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	_ "image/jpeg" // Graph serves photos as JPEG
+	_ "image/png"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models/odataerrors"
+)
+
+// ErrNoPhoto is returned when the user or room has no photo.
+var ErrNoPhoto = errors.New("no photo")
+
+// PhotoWidth is the widest a photo is drawn, in pixels.
+const PhotoWidth = 160
+
+// GetPhoto fetches the photo of a user or room, served from the cache while it is fresh.
+//
+// Parameters:
+//   - ctx: The context of the request.
+//   - userId: The ID or email of the user or room.
+//
+// Returns:
+//   - []byte: The photo, usually a JPEG.
+//   - error: ErrNoPhoto if there is no photo, another error object if the request fails, otherwise nil.
+func (g *GraphHelper) GetPhoto(ctx context.Context, userId string) ([]byte, error) {
+
+	if err := validateUserId("user", userId); err != nil {
+		return nil, err
+	}
+	key := strings.ToLower(userId)
+	if photo, ok := g.cache.getPhoto(key); ok {
+		return photo, nil
+	}
+	client, err := g.graphClient()
+	if err != nil {
+		return nil, err
+	}
+
+	photo, err := client.Users().ByUserId(userId).Photo().Content().Get(ctx, nil)
+	if err != nil {
+		var odataError *odataerrors.ODataError
+		if errors.As(err, &odataError) && odataError.GetStatusCode() == http.StatusNotFound {
+			return nil, fmt.Errorf("%s: %w", userId, ErrNoPhoto)
+		}
+		return nil, fmt.Errorf("failed to get photo: %v", err)
+	}
+	g.cache.setPhoto(key, photo)
+	return photo, nil
+}
+
+// WritePhotoPlaceholder describes a photo instead of drawing it, for terminals without sixel graphics.
+func WritePhotoPlaceholder(w io.Writer, photo []byte) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(photo))
+	if err != nil {
+		fmt.Fprintf(w, "[photo, %d KB]\n", (len(photo)+1023)/1024)
+		return
+	}
+	fmt.Fprintf(w, "[photo %dx%d %s, %d KB]\n", config.Width, config.Height, format, (len(photo)+1023)/1024)
+}
+
+// WriteSixel draws a photo with sixel graphics, scaled down to at most maxWidth pixels wide
+// and reduced to the web safe palette.
+func WriteSixel(w io.Writer, photo []byte, maxWidth int) error {
+	decoded, _, err := image.Decode(bytes.NewReader(photo))
+	if err != nil {
+		return fmt.Errorf("failed to decode photo: %v", err)
+	}
+
+	// nearest neighbour scaling is plenty for a thumbnail
+	bounds := decoded.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > maxWidth {
+		width, height = maxWidth, height*maxWidth/width
+	}
+	if width == 0 || height == 0 {
+		return errors.New("failed to decode photo: the image is empty")
+	}
+	scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			scaled.Set(x, y, decoded.At(bounds.Min.X+x*bounds.Dx()/width, bounds.Min.Y+y*bounds.Dy()/height))
+		}
+	}
+	paletted := image.NewPaletted(scaled.Bounds(), palette.WebSafe)
+	draw.FloydSteinberg.Draw(paletted, paletted.Bounds(), scaled, image.Point{})
+
+	_, err = io.WriteString(w, encodeSixel(paletted))
+	return err
+}
+
+// encodeSixel encodes a paletted image as a sixel sequence: the palette, then bands six
+// pixels high, each drawn once per colour it uses, with runs of the same sixel compressed.
+func encodeSixel(img *image.Paletted) string {
+	var out strings.Builder
+	bounds := img.Bounds()
+	out.WriteString("\033Pq")
+	fmt.Fprintf(&out, "\"1;1;%d;%d", bounds.Dx(), bounds.Dy())
+	for i, c := range img.Palette {
+		r, g, b, _ := color.RGBAModel.Convert(c).RGBA()
+		fmt.Fprintf(&out, "#%d;2;%d;%d;%d", i, r*100/0xffff, g*100/0xffff, b*100/0xffff)
+	}
+
+	for top := bounds.Min.Y; top < bounds.Max.Y; top += 6 {
+		used := map[uint8]bool{}
+		var colours []uint8
+		for y := top; y < top+6 && y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				if index := img.ColorIndexAt(x, y); !used[index] {
+					used[index] = true
+					colours = append(colours, index)
+				}
+			}
+		}
+
+		for n, index := range colours {
+			if n > 0 {
+				out.WriteByte('$') // back to the start of the band for the next colour
+			}
+			fmt.Fprintf(&out, "#%d", index)
+			var run byte
+			count := 0
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				var bits byte
+				for bit := 0; bit < 6 && top+bit < bounds.Max.Y; bit++ {
+					if img.ColorIndexAt(x, top+bit) == index {
+						bits |= 1 << bit
+					}
+				}
+				sixel := '?' + bits
+				if count > 0 && sixel != run {
+					writeSixelRun(&out, run, count)
+					count = 0
+				}
+				run = sixel
+				count++
+			}
+			writeSixelRun(&out, run, count)
+		}
+		out.WriteByte('-')
+	}
+
+	out.WriteString("\033\\")
+	return out.String()
+}
+
+// writeSixelRun writes count copies of a sixel, using a repeat introducer for longer runs.
+func writeSixelRun(out *strings.Builder, sixel byte, count int) {
+	if count > 3 {
+		fmt.Fprintf(out, "!%d%c", count, sixel)
+		return
+	}
+	for i := 0; i < count; i++ {
+		out.WriteByte(sixel)
+	}
+}
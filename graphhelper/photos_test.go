@@ -0,0 +1,91 @@
+package graphhelper
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+// testPhoto returns a PNG of the given size, red on the left half and blue on the right.
+func testPhoto(t *testing.T, width int, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if x < width/2 {
+				img.Set(x, y, color.RGBA{R: 255, A: 255})
+			} else {
+				img.Set(x, y, color.RGBA{B: 255, A: 255})
+			}
+		}
+	}
+	var out bytes.Buffer
+	if err := png.Encode(&out, img); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return out.Bytes()
+}
+
+func TestEncodeSixel(t *testing.T) {
+	img := image.NewPaletted(image.Rect(0, 0, 5, 2), color.Palette{color.Black, color.White})
+	img.SetColorIndex(4, 0, 1)
+	img.SetColorIndex(4, 1, 1)
+
+	got := encodeSixel(img)
+
+	// two rows set the lowest two bits of each sixel, "?" + 3
+	want := "\033Pq\"1;1;5;2#0;2;0;0;0#1;2;100;100;100#0!4B?$#1!4?B-\033\\"
+	if got != want {
+		t.Errorf("encodeSixel() = %q, want %q", got, want)
+	}
+}
+
+func TestWriteSixel(t *testing.T) {
+	var out bytes.Buffer
+	if err := WriteSixel(&out, testPhoto(t, 400, 100), PhotoWidth); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// scaled to PhotoWidth, keeping the shape
+	if !strings.HasPrefix(out.String(), "\033Pq\"1;1;160;40#") || !strings.HasSuffix(out.String(), "-\033\\") {
+		t.Errorf("WriteSixel() = %.40q...", out.String())
+	}
+
+	if err := WriteSixel(&out, []byte("not an image"), PhotoWidth); err == nil {
+		t.Error("WriteSixel() accepted data that is not an image")
+	}
+}
+
+func TestWritePhotoPlaceholder(t *testing.T) {
+	tests := []struct {
+		name  string
+		photo []byte
+		want  string
+	}{
+		{"image", testPhoto(t, 48, 32), "[photo 48x32 png, 1 KB]\n"},
+		{"not an image", []byte("garbage"), "[photo, 1 KB]\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var out bytes.Buffer
+			WritePhotoPlaceholder(&out, test.photo)
+			if out.String() != test.want {
+				t.Errorf("WritePhotoPlaceholder() = %q, want %q", out.String(), test.want)
+			}
+		})
+	}
+}
+
+func TestGetPhotoFromCache(t *testing.T) {
+	g := NewGraphHelper(&Config{CacheTTL: DefaultCacheTTL})
+	g.cache.setPhoto("room@example.com", []byte("jpeg"))
+
+	// served without a Graph client
+	photo, err := g.GetPhoto(context.Background(), "Room@Example.com")
+	if err != nil || string(photo) != "jpeg" {
+		t.Errorf("GetPhoto() = %q, %v; want the cached photo", photo, err)
+	}
+}
@@ -0,0 +1,295 @@
+package graphhelper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	abstractions "github.com/microsoft/kiota-abstractions-go"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/models/odataerrors"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+)
+
+// workspacesPath is the Places endpoint for workspaces. Workspaces are only exposed on the
+// beta endpoint, which the v1.0 SDK has no builder or model for, so they are requested directly.
+const workspacesPath = "/beta/places/microsoft.graph.workspace"
+
+// PlaceTypes are the place types that can be listed.
+var PlaceTypes = []string{"room", "workspace", "equipment"}
+
+// Workspace is a bookable desk or shared space, as returned by the beta Places endpoint.
+type Workspace struct {
+	Id           *string `json:"id"`
+	DisplayName  *string `json:"displayName"`
+	EmailAddress *string `json:"emailAddress"`
+	Capacity     *int32  `json:"capacity"`
+	Building     *string `json:"building"`
+	FloorLabel   *string `json:"floorLabel"`
+}
+
+// workspacePage is one page of the workspaces collection.
+type workspacePage struct {
+	Value    []Workspace `json:"value"`
+	NextLink *string     `json:"@odata.nextLink"`
+}
+
+// place is the display form shared by rooms, workspaces and equipment, any field may be unset.
+type place struct {
+	id       *string
+	name     *string
+	email    *string
+	capacity *int32
+}
+
+func roomPlace(room models.Roomable) place {
+	return place{room.GetId(), room.GetDisplayName(), room.GetEmailAddress(), room.GetCapacity()}
+}
+
+func workspacePlace(workspace Workspace) place {
+	return place{workspace.Id, workspace.DisplayName, workspace.EmailAddress, workspace.Capacity}
+}
+
+func equipmentPlace(user models.Userable) place {
+	return place{user.GetId(), user.GetDisplayName(), user.GetMail(), nil}
+}
+
+// GetWorkspaces returns all bookable workspaces (desks and shared spaces) in the tenant,
+// following @odata.nextLink page by page.
+func (g *GraphHelper) GetWorkspaces() ([]Workspace, error) {
+	client, err := g.graphClient()
+	if err != nil {
+		return nil, err
+	}
+
+	next := "https://" + g.Config().graphHost() + workspacesPath
+	var workspaces []Workspace
+	for page := 1; ; page++ {
+		uri, err := url.Parse(next)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list workspaces: %v", err)
+		}
+		requestInfo := abstractions.NewRequestInformation()
+		requestInfo.Method = abstractions.GET
+		requestInfo.SetUri(*uri)
+		requestInfo.Headers.TryAdd("Accept", "application/json")
+
+		body, err := client.GetAdapter().SendPrimitive(context.Background(), requestInfo, "[]byte", abstractions.ErrorMappings{
+			"XXX": odataerrors.CreateODataErrorFromDiscriminatorValue,
+		})
+		if err != nil {
+			g.reportProgress("workspaces", page, len(workspaces), true)
+			return nil, err
+		}
+
+		var result workspacePage
+		if content, ok := body.([]byte); ok {
+			if err := json.Unmarshal(content, &result); err != nil {
+				return nil, fmt.Errorf("failed to read workspaces: %v", err)
+			}
+		}
+		workspaces = append(workspaces, result.Value...)
+
+		if result.NextLink == nil {
+			g.reportProgress("workspaces", page, len(workspaces), true)
+			return workspaces, nil
+		}
+		g.reportProgress("workspaces", page, len(workspaces), false)
+		next = *result.NextLink
+	}
+}
+
+// GetEquipment returns the equipment mailboxes in the tenant. Graph has no Places type for
+// equipment, so these are the resource accounts that are neither a room nor a workspace.
+func (g *GraphHelper) GetEquipment() ([]models.Userable, error) {
+	client, err := g.graphClient()
+	if err != nil {
+		return nil, err
+	}
+
+	places := map[string]bool{}
+	rooms, err := g.GetRooms()
+	if err != nil {
+		return nil, err
+	}
+	for _, room := range rooms {
+		places[strings.ToLower(StringOrDefault(room.GetEmailAddress(), ""))] = true
+	}
+	// workspaces are beta only, without them some workspaces may be listed as equipment
+	if workspaces, err := g.GetWorkspaces(); err == nil {
+		for _, workspace := range workspaces {
+			places[strings.ToLower(StringOrDefault(workspace.EmailAddress, ""))] = true
+		}
+	}
+
+	filter := "isResourceAccount eq true"
+	count := true
+	config := &users.UsersRequestBuilderGetRequestConfiguration{
+		Headers: countHeaders(),
+		QueryParameters: &users.UsersRequestBuilderGetQueryParameters{
+			Select: []string{"displayName", "id", "mail"},
+			Filter: &filter,
+			Count:  &count,
+		},
+	}
+
+	result, err := client.Users().Get(context.Background(), config)
+
+	var equipment []models.Userable
+	for page := 1; ; page++ {
+		if err != nil {
+			g.reportProgress("resource accounts", page, len(equipment), true)
+			return nil, err
+		}
+		for _, user := range result.GetValue() {
+			if !places[strings.ToLower(StringOrDefault(user.GetMail(), ""))] {
+				equipment = append(equipment, user)
+			}
+		}
+
+		nextLink := result.GetOdataNextLink()
+		if nextLink == nil {
+			g.reportProgress("resource accounts", page, len(equipment), true)
+			return equipment, nil
+		}
+		g.reportProgress("resource accounts", page, len(equipment), false)
+
+		result, err = client.Users().WithUrl(*nextLink).
+			Get(context.Background(), &users.UsersRequestBuilderGetRequestConfiguration{
+				Headers: config.Headers,
+			})
+	}
+}
+
+// ListPlaces writes every place of the given type, "room", "workspace" or "equipment", to w.
+func (g *GraphHelper) ListPlaces(w io.Writer, placeType string) {
+
+	switch placeType {
+	case "room":
+		g.PrintRooms(w)
+	case "workspace":
+		workspaces, err := g.GetWorkspaces()
+		if err != nil {
+			fmt.Fprintln(w, "Failed to list workspaces:", err)
+			return
+		}
+		if len(workspaces) == 0 {
+			fmt.Fprintln(w, "No workspaces found")
+			return
+		}
+		for _, workspace := range workspaces {
+			g.printPlace(w, "Workspace", workspacePlace(workspace))
+		}
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "Workspaces listed: %d\n", len(workspaces))
+	case "equipment":
+		equipment, err := g.GetEquipment()
+		if err != nil {
+			fmt.Fprintln(w, "Failed to list equipment:", err)
+			return
+		}
+		if len(equipment) == 0 {
+			fmt.Fprintln(w, "No equipment found")
+			return
+		}
+		for _, user := range equipment {
+			g.printPlace(w, "Equipment", equipmentPlace(user))
+		}
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "Equipment listed: %d\n", len(equipment))
+	default:
+		fmt.Fprintf(w, "Unknown place type %q, expected one of %v\n", placeType, PlaceTypes)
+	}
+}
+
+// printPlace writes the fields shared by rooms, workspaces and equipment, any of which may be unset.
+func (g *GraphHelper) printPlace(w io.Writer, label string, place place) {
+	fmt.Fprintf(w, "%s ID: %s\n", label, StringOrDefault(place.id, "-"))
+	g.SetLastId(place.id)
+	fmt.Fprintf(w, "  Name: %s\n", StringOrDefault(place.name, "(unknown)"))
+	capacity := "-"
+	if place.capacity != nil {
+		capacity = fmt.Sprintf("%d", *place.capacity)
+	}
+	fmt.Fprintf(w, "  Capacity: %s\n", capacity)
+	fmt.Fprintf(w, "  Email: %s\n", StringOrDefault(place.email, "-"))
+}
+
+// GetRoom returns one room from Places, with all its facility details.
+//
+// Parameters:
+//   - roomId: The Places ID or the email of the room.
+//
+// Returns:
+//   - models.Roomable: The room.
+//   - error: An error object if the request fails, otherwise nil.
+func (g *GraphHelper) GetRoom(roomId string) (models.Roomable, error) {
+	client, err := g.graphClient()
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(roomId) == "" {
+		return nil, fmt.Errorf("no room id or email given")
+	}
+
+	room, err := client.Places().ByPlaceId(roomId).GraphRoom().Get(context.Background(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room: %v", err)
+	}
+	return room, nil
+}
+
+// PrintRoomDetails writes every facility field Places holds for a room, "-" for those not set.
+func (g *GraphHelper) PrintRoomDetails(w io.Writer, room models.Roomable) {
+	g.printPlace(w, "Room", roomPlace(room))
+
+	optionalInt := func(value *int32) string {
+		if value == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%d", *value)
+	}
+	optionalBool := func(value *bool) string {
+		if value == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%t", *value)
+	}
+
+	fmt.Fprintf(w, "  Nickname: %s\n", StringOrDefault(room.GetNickname(), "-"))
+	fmt.Fprintf(w, "  Building: %s\n", StringOrDefault(room.GetBuilding(), "-"))
+	fmt.Fprintf(w, "  Floor: %s (number %s)\n", StringOrDefault(room.GetFloorLabel(), "-"), optionalInt(room.GetFloorNumber()))
+	fmt.Fprintf(w, "  Label: %s\n", StringOrDefault(room.GetLabel(), "-"))
+	address := "-"
+	if a := room.GetAddress(); a != nil {
+		var parts []string
+		for _, part := range []*string{a.GetStreet(), a.GetCity(), a.GetState(), a.GetPostalCode(), a.GetCountryOrRegion()} {
+			if value := StringOrDefault(part, ""); value != "" {
+				parts = append(parts, value)
+			}
+		}
+		if len(parts) > 0 {
+			address = strings.Join(parts, ", ")
+		}
+	}
+	fmt.Fprintf(w, "  Address: %s\n", address)
+	fmt.Fprintf(w, "  Phone: %s\n", StringOrDefault(room.GetPhone(), "-"))
+	bookingType := "-"
+	if room.GetBookingType() != nil {
+		bookingType = room.GetBookingType().String()
+	}
+	fmt.Fprintf(w, "  Booking type: %s\n", bookingType)
+	fmt.Fprintf(w, "  Audio device: %s\n", StringOrDefault(room.GetAudioDeviceName(), "-"))
+	fmt.Fprintf(w, "  Video device: %s\n", StringOrDefault(room.GetVideoDeviceName(), "-"))
+	fmt.Fprintf(w, "  Display device: %s\n", StringOrDefault(room.GetDisplayDeviceName(), "-"))
+	fmt.Fprintf(w, "  Wheelchair accessible: %s\n", optionalBool(room.GetIsWheelChairAccessible()))
+	tags := "-"
+	if len(room.GetTags()) > 0 {
+		tags = strings.Join(room.GetTags(), ", ")
+	}
+	fmt.Fprintf(w, "  Tags: %s\n", tags)
+}
@@ -0,0 +1,135 @@
+package graphhelper
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+func TestPrintPlaceWithoutOptionalFields(t *testing.T) {
+	g := &GraphHelper{}
+	// rooms often have no capacity, and may be missing a name or email
+	room := models.NewRoom()
+
+	var out bytes.Buffer
+	g.printPlace(&out, "Room", roomPlace(room))
+
+	want := "Room ID: -\n  Name: (unknown)\n  Capacity: -\n  Email: -\n"
+	if out.String() != want {
+		t.Errorf("printPlace wrote\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestPrintPlace(t *testing.T) {
+	g := &GraphHelper{}
+	id, name, email := "room-id", "Boardroom", "boardroom@example.com"
+	var capacity int32 = 12
+	room := models.NewRoom()
+	room.SetId(&id)
+	room.SetDisplayName(&name)
+	room.SetEmailAddress(&email)
+	room.SetCapacity(&capacity)
+
+	var out bytes.Buffer
+	g.printPlace(&out, "Room", roomPlace(room))
+
+	want := "Room ID: room-id\n  Name: Boardroom\n  Capacity: 12\n  Email: boardroom@example.com\n"
+	if out.String() != want {
+		t.Errorf("printPlace wrote\n%s\nwant\n%s", out.String(), want)
+	}
+	if g.LastId() != "room-id" {
+		t.Errorf("LastId() = %q, want room-id", g.LastId())
+	}
+}
+
+func TestListRoomsReportsFailure(t *testing.T) {
+	g := &GraphHelper{cache: newCache(0)}
+
+	if _, err := g.ListRooms(); err == nil {
+		t.Error("ListRooms() returned no error without a Graph client")
+	}
+
+	var out bytes.Buffer
+	if err := g.PrintRooms(&out); err == nil {
+		t.Error("PrintRooms() returned no error without a Graph client")
+	}
+	if !strings.HasPrefix(out.String(), "Failed to list rooms:") {
+		t.Errorf("PrintRooms wrote %q, want a failure message", out.String())
+	}
+}
+
+func TestListRoom7DaysBookingsReportsFailure(t *testing.T) {
+	g := &GraphHelper{cache: newCache(0)}
+
+	var out bytes.Buffer
+	if err := g.PrintRoom7DaysBookings(&out, "room@example.com"); err == nil {
+		t.Error("PrintRoom7DaysBookings() returned no error without a Graph client")
+	}
+	if !strings.HasPrefix(out.String(), "Failed to get calendar view:") {
+		t.Errorf("PrintRoom7DaysBookings wrote %q, want a failure message", out.String())
+	}
+}
+
+func TestWorkspacePageDecodes(t *testing.T) {
+	body := `{"@odata.nextLink":"https://graph.microsoft.com/beta/places/microsoft.graph.workspace?$skiptoken=x",
+		"value":[{"id":"desk-id","displayName":"Desk 1","emailAddress":"desk1@example.com","capacity":1},{"id":"hot-desks"}]}`
+
+	var page workspacePage
+	if err := json.Unmarshal([]byte(body), &page); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if page.NextLink == nil {
+		t.Error("next link not decoded")
+	}
+	if len(page.Value) != 2 {
+		t.Fatalf("got %d workspaces, want 2", len(page.Value))
+	}
+
+	g := &GraphHelper{}
+	var out bytes.Buffer
+	for _, workspace := range page.Value {
+		g.printPlace(&out, "Workspace", workspacePlace(workspace))
+	}
+	want := "Workspace ID: desk-id\n  Name: Desk 1\n  Capacity: 1\n  Email: desk1@example.com\n" +
+		"Workspace ID: hot-desks\n  Name: (unknown)\n  Capacity: -\n  Email: -\n"
+	if out.String() != want {
+		t.Errorf("printPlace wrote\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestPrintRoomDetails(t *testing.T) {
+	g := &GraphHelper{}
+	id, name, building, floor, video := "room-id", "Boardroom", "HQ", "Level 3", "Surface Hub"
+	var floorNumber int32 = 3
+	accessible := true
+	room := models.NewRoom()
+	room.SetId(&id)
+	room.SetDisplayName(&name)
+	room.SetBuilding(&building)
+	room.SetFloorLabel(&floor)
+	room.SetFloorNumber(&floorNumber)
+	room.SetVideoDeviceName(&video)
+	room.SetIsWheelChairAccessible(&accessible)
+	room.SetTags([]string{"quiet", "whiteboard"})
+
+	var out bytes.Buffer
+	g.PrintRoomDetails(&out, room)
+
+	for _, want := range []string{
+		"  Building: HQ\n",
+		"  Floor: Level 3 (number 3)\n",
+		"  Video device: Surface Hub\n",
+		"  Audio device: -\n",
+		"  Address: -\n",
+		"  Booking type: -\n",
+		"  Wheelchair accessible: true\n",
+		"  Tags: quiet, whiteboard\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("PrintRoomDetails wrote\n%s\nwant it to contain %q", out.String(), want)
+		}
+	}
+}
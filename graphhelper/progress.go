@@ -0,0 +1,16 @@
+package graphhelper
+
+// ProgressFunc is told after each page of a paged listing is fetched. what names the items
+// ("users", "rooms"), fetched is the running total and done is true once paging stops.
+type ProgressFunc func(what string, page int, fetched int, done bool)
+
+// SetProgress registers a function to report progress of paged listings. Nil disables reporting.
+func (g *GraphHelper) SetProgress(progress ProgressFunc) {
+	g.progress = progress
+}
+
+func (g *GraphHelper) reportProgress(what string, page int, fetched int, done bool) {
+	if g.progress != nil {
+		g.progress(what, page, fetched, done)
+	}
+}
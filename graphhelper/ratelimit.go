@@ -0,0 +1,105 @@
+package graphhelper
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// rateLimiter is a token bucket spacing out Graph requests, so bulk operations over many
+// rooms do not run into Graph's throttling. It is shared by every client the GraphHelper
+// creates, so it survives re-initialization after a config reload.
+type rateLimiter struct {
+	mu     sync.Mutex
+	limit  float64 // tokens added per second
+	burst  int     // most tokens held
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(limit float64, burst int) *rateLimiter {
+	return &rateLimiter{limit: limit, burst: burst, tokens: float64(burst), last: time.Now()}
+}
+
+// refill adds the tokens earned since the last call. Only call with mu held.
+func (l *rateLimiter) refill(now time.Time) {
+	l.tokens = math.Min(float64(l.burst), l.tokens+now.Sub(l.last).Seconds()*l.limit)
+	l.last = now
+}
+
+// set changes the rate and burst, keeping the tokens already earned up to the new burst.
+func (l *rateLimiter) set(limit float64, burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill(time.Now())
+	l.limit = limit
+	l.burst = burst
+	l.tokens = math.Min(float64(burst), l.tokens)
+}
+
+// reserve takes a token and returns how long to wait before it may be used.
+func (l *rateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill(time.Now())
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.limit * float64(time.Second))
+}
+
+// cancel returns a reserved token that was not used.
+func (l *rateLimiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens++
+}
+
+// rateLimitTransport holds each request back until the limiter allows it.
+type rateLimitTransport struct {
+	next    http.RoundTripper
+	limiter *rateLimiter
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	delay := t.limiter.reserve()
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			t.limiter.cancel()
+			return nil, req.Context().Err()
+		}
+	}
+	return t.next.RoundTrip(req)
+}
+
+// SetRateLimit changes how many Graph requests are sent per second, and how many may be
+// sent at once after a quiet spell, for calls made from now on.
+//
+// Parameters:
+//   - limit: The requests per second, greater than zero.
+//   - burst: The requests sent at once, at least one.
+//
+// Returns:
+//   - error: An error object if either value is not positive, otherwise nil.
+func (g *GraphHelper) SetRateLimit(limit float64, burst int) error {
+	if !(limit > 0) || math.IsInf(limit, 1) {
+		return fmt.Errorf("rate limit %v is not a positive number of requests per second", limit)
+	}
+	if burst <= 0 {
+		return fmt.Errorf("rate burst %d is not a positive count", burst)
+	}
+	g.limiter.set(limit, burst)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.config.RateLimit = limit
+	g.config.RateBurst = burst
+	return nil
+}
@@ -0,0 +1,75 @@
+package graphhelper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterSpacesRequestsAfterBurst(t *testing.T) {
+	limiter := newRateLimiter(100, 2)
+
+	for i := 0; i < 2; i++ {
+		if delay := limiter.reserve(); delay != 0 {
+			t.Fatalf("request %d within the burst waited %v", i+1, delay)
+		}
+	}
+	if delay := limiter.reserve(); delay <= 0 || delay > 10*time.Millisecond {
+		t.Errorf("request after the burst waits %v, want about 10ms", delay)
+	}
+}
+
+func TestRateLimiterSet(t *testing.T) {
+	limiter := newRateLimiter(100, 5)
+	limiter.set(1, 1)
+
+	if delay := limiter.reserve(); delay != 0 {
+		t.Fatalf("first request waited %v", delay)
+	}
+	if delay := limiter.reserve(); delay < 900*time.Millisecond {
+		t.Errorf("second request waits %v, want about a second at 1 request per second", delay)
+	}
+}
+
+func TestRateLimitTransportHonoursCancellation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	limiter := newRateLimiter(0.001, 1)
+	transport := &rateLimitTransport{next: http.DefaultTransport, limiter: limiter}
+
+	request, _ := http.NewRequest("GET", server.URL, nil)
+	response, err := transport.RoundTrip(request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	response.Body.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := transport.RoundTrip(request.WithContext(ctx)); err != context.DeadlineExceeded {
+		t.Errorf("RoundTrip() over the limit = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if limiter.tokens < -0.01 {
+		t.Errorf("cancelled request kept its token, %v tokens left", limiter.tokens)
+	}
+}
+
+func TestSetRateLimitRejectsNonPositiveValues(t *testing.T) {
+	g := NewGraphHelper(&Config{})
+
+	if err := g.SetRateLimit(0, 10); err == nil {
+		t.Error("SetRateLimit accepted a zero rate")
+	}
+	if err := g.SetRateLimit(5, 0); err == nil {
+		t.Error("SetRateLimit accepted a zero burst")
+	}
+	if err := g.SetRateLimit(5, 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config := g.Config(); config.RateLimit != 5 || config.RateBurst != 2 {
+		t.Errorf("Config() rate = %v, %d, want 5, 2", config.RateLimit, config.RateBurst)
+	}
+}
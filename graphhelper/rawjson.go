@@ -0,0 +1,63 @@
+package graphhelper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/microsoft/kiota-abstractions-go/serialization"
+	jsonserialization "github.com/microsoft/kiota-serialization-json-go"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// RawJSONWarning is written before raw JSON, which is shown without any redaction.
+const RawJSONWarning = "Raw JSON, not redacted, it may contain personal information:"
+
+// RawJSON serializes a Graph object with the SDK's JSON serialization writer, indented for
+// reading. Every field the object holds is written, not only those changed since it was fetched.
+//
+// Parameters:
+//   - object: The user, room, event, subscription or other Graph object.
+//
+// Returns:
+//   - []byte: The object's JSON.
+//   - error: An error object if the object cannot be serialized, otherwise nil.
+func RawJSON(object serialization.Parsable) ([]byte, error) {
+	writer := jsonserialization.NewJsonSerializationWriter()
+	defer writer.Close()
+	if err := writer.WriteObjectValue("", object); err != nil {
+		return nil, fmt.Errorf("failed to serialize: %v", err)
+	}
+	content, err := writer.GetSerializedContent()
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize: %v", err)
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, content, "", "  "); err != nil {
+		return nil, fmt.Errorf("failed to indent: %v", err)
+	}
+	return indented.Bytes(), nil
+}
+
+// WriteRawJSON writes a Graph object's raw JSON to w after RawJSONWarning, when "SHOW_RAW_JSON"
+// is set. While "HIDE_PRIVATE_EVENTS" is set, private and confidential events are left out.
+func (g *GraphHelper) WriteRawJSON(w io.Writer, object serialization.Parsable) {
+	config := g.Config()
+	if !config.ShowRawJSON || object == nil {
+		return
+	}
+	if event, ok := object.(models.Eventable); ok && config.HidePrivateEvents && event.GetSensitivity() != nil && isPrivate(event.GetSensitivity().String()) {
+		fmt.Fprintln(w, "Raw JSON is not shown for private events while private event details are hidden")
+		return
+	}
+
+	content, err := RawJSON(object)
+	if err != nil {
+		fmt.Fprintln(w, "Failed to show raw JSON:", err)
+		return
+	}
+	fmt.Fprintln(w, RawJSONWarning)
+	fmt.Fprintf(w, "%s\n", content)
+}
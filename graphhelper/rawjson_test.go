@@ -0,0 +1,44 @@
+package graphhelper
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+func TestWriteRawJSON(t *testing.T) {
+	name, capacity := "Boardroom", int32(12)
+	room := models.NewRoom()
+	room.SetDisplayName(&name)
+	room.SetCapacity(&capacity)
+
+	g := NewGraphHelper(&Config{})
+	var out strings.Builder
+	g.WriteRawJSON(&out, room)
+	if out.Len() != 0 {
+		t.Errorf("WriteRawJSON() without SHOW_RAW_JSON wrote %q", out.String())
+	}
+
+	g = NewGraphHelper(&Config{ShowRawJSON: true})
+	g.WriteRawJSON(&out, room)
+	for _, want := range []string{RawJSONWarning, `"displayName": "Boardroom"`, `"capacity": 12`, `"@odata.type": "#microsoft.graph.room"`} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("WriteRawJSON() = %q, want it to contain %q", out.String(), want)
+		}
+	}
+}
+
+func TestWriteRawJSONLeavesOutHiddenPrivateEvents(t *testing.T) {
+	subject, private := "Performance review", models.PRIVATE_SENSITIVITY
+	event := models.NewEvent()
+	event.SetSubject(&subject)
+	event.SetSensitivity(&private)
+
+	g := NewGraphHelper(&Config{ShowRawJSON: true, HidePrivateEvents: true})
+	var out strings.Builder
+	g.WriteRawJSON(&out, event)
+	if strings.Contains(out.String(), subject) {
+		t.Errorf("WriteRawJSON() = %q, want the private event left out", out.String())
+	}
+}
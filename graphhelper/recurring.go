@@ -0,0 +1,326 @@
+package graphhelper
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+)
+
+// RecurringSeries is a recurring booking in a calendar and its occurrences in a window.
+type RecurringSeries struct {
+	Id        string // the series master
+	Subject   string
+	Organiser string
+	Pattern   string // the recurrence in words, such as "every week on monday, until 2024-06-30"
+	Instances []EventSummary
+}
+
+// CalendarSeries are the events of a calendar in a window, single events apart from recurring series.
+type CalendarSeries struct {
+	Single []EventSummary
+	Series []RecurringSeries
+}
+
+// recurringSeriesFields are read from each series master.
+var recurringSeriesFields = []string{"id", "subject", "organizer", "recurrence", "sensitivity"}
+
+// GetRecurringSeries reads the events of a calendar between start and end and groups the
+// occurrences of recurring series under their series, with the recurrence of each.
+//
+// Parameters:
+//   - ctx: Cancels the requests.
+//   - userId: The ID or email of the mailbox.
+//   - start: The start of the window.
+//   - end: The end of the window.
+//
+// Returns:
+//   - CalendarSeries: The single events and the series, in the order they first occur.
+//   - error: An error object if a request fails, otherwise nil.
+func (g *GraphHelper) GetRecurringSeries(ctx context.Context, userId string, start time.Time, end time.Time) (CalendarSeries, error) {
+	client, err := g.graphClient()
+	if err != nil {
+		return CalendarSeries{}, err
+	}
+
+	if err := validateUserId("mailbox", userId); err != nil {
+		return CalendarSeries{}, err
+	}
+
+	events, err := g.GetCalendarView(userId, start, end)
+	if err != nil {
+		return CalendarSeries{}, err
+	}
+
+	// calendar views expand series, so only occurrences and exceptions name their master
+	var calendar CalendarSeries
+	var seriesIds []string
+	seen := map[string]bool{}
+	for _, event := range events {
+		id := seriesIdOf(event)
+		if id == "" {
+			calendar.Single = append(calendar.Single, g.NewEventSummary(event))
+			continue
+		}
+		if !seen[id] {
+			seen[id] = true
+			seriesIds = append(seriesIds, id)
+		}
+	}
+
+	hidePrivate := g.Config().HidePrivateEvents
+	for _, id := range seriesIds {
+		master, err := client.Users().ByUserId(userId).Events().ByEventId(id).Get(ctx, &users.ItemEventsEventItemRequestBuilderGetRequestConfiguration{
+			QueryParameters: &users.ItemEventsEventItemRequestBuilderGetQueryParameters{
+				Select: recurringSeriesFields,
+			},
+		})
+		if err != nil {
+			return CalendarSeries{}, fmt.Errorf("failed to get series %s: %v", id, err)
+		}
+		instances, err := g.GetEventInstances(ctx, userId, id, start, end)
+		if err != nil {
+			return CalendarSeries{}, err
+		}
+
+		series := RecurringSeries{
+			Id:      id,
+			Subject: StringOrDefault(master.GetSubject(), ""),
+			Pattern: recurrenceSummary(master.GetRecurrence()),
+		}
+		if master.GetSensitivity() != nil && hidePrivate && isPrivate(master.GetSensitivity().String()) {
+			series.Subject = privateSubject
+		}
+		if master.GetOrganizer() != nil && master.GetOrganizer().GetEmailAddress() != nil {
+			series.Organiser = StringOrDefault(master.GetOrganizer().GetEmailAddress().GetAddress(), "")
+		}
+		for _, instance := range instances {
+			series.Instances = append(series.Instances, g.NewEventSummary(instance))
+		}
+		calendar.Series = append(calendar.Series, series)
+	}
+	return calendar, nil
+}
+
+// recurrenceSummary describes a recurrence in words, such as "every 2 weeks on monday, friday, 10 times".
+func recurrenceSummary(recurrence models.PatternedRecurrenceable) string {
+	if recurrence == nil || recurrence.GetPattern() == nil || recurrence.GetPattern().GetTypeEscaped() == nil {
+		return "unknown recurrence"
+	}
+	pattern := recurrence.GetPattern()
+	interval := 1
+	if pattern.GetInterval() != nil && *pattern.GetInterval() > 1 {
+		interval = int(*pattern.GetInterval())
+	}
+	every := func(unit string) string {
+		if interval == 1 {
+			return "every " + unit
+		}
+		return fmt.Sprintf("every %d %ss", interval, unit)
+	}
+	var days []string
+	for _, day := range pattern.GetDaysOfWeek() {
+		days = append(days, day.String())
+	}
+	dayOfMonth, month := int32(0), ""
+	if pattern.GetDayOfMonth() != nil {
+		dayOfMonth = *pattern.GetDayOfMonth()
+	}
+	if pattern.GetMonth() != nil && *pattern.GetMonth() >= 1 && *pattern.GetMonth() <= 12 {
+		month = time.Month(*pattern.GetMonth()).String()
+	}
+	index := models.FIRST_WEEKINDEX.String()
+	if pattern.GetIndex() != nil {
+		index = pattern.GetIndex().String()
+	}
+
+	var summary string
+	switch *pattern.GetTypeEscaped() {
+	case models.DAILY_RECURRENCEPATTERNTYPE:
+		summary = every("day")
+	case models.WEEKLY_RECURRENCEPATTERNTYPE:
+		summary = every("week") + " on " + strings.Join(days, ", ")
+	case models.ABSOLUTEMONTHLY_RECURRENCEPATTERNTYPE:
+		summary = fmt.Sprintf("%s on day %d", every("month"), dayOfMonth)
+	case models.RELATIVEMONTHLY_RECURRENCEPATTERNTYPE:
+		summary = fmt.Sprintf("%s on the %s %s", every("month"), index, strings.Join(days, ", "))
+	case models.ABSOLUTEYEARLY_RECURRENCEPATTERNTYPE:
+		summary = fmt.Sprintf("%s on %d %s", every("year"), dayOfMonth, month)
+	case models.RELATIVEYEARLY_RECURRENCEPATTERNTYPE:
+		summary = fmt.Sprintf("%s on the %s %s of %s", every("year"), index, strings.Join(days, ", "), month)
+	default:
+		summary = pattern.GetTypeEscaped().String()
+	}
+
+	span := recurrence.GetRangeEscaped()
+	if span == nil || span.GetTypeEscaped() == nil {
+		return summary
+	}
+	switch *span.GetTypeEscaped() {
+	case models.ENDDATE_RECURRENCERANGETYPE:
+		if span.GetEndDate() != nil {
+			summary += ", until " + span.GetEndDate().String()
+		}
+	case models.NUMBERED_RECURRENCERANGETYPE:
+		if span.GetNumberOfOccurrences() != nil {
+			summary += fmt.Sprintf(", %d times", *span.GetNumberOfOccurrences())
+		}
+	case models.NOEND_RECURRENCERANGETYPE:
+		summary += ", with no end"
+	}
+	return summary
+}
+
+// CancelInstance cancels one occurrence of a recurring series, leaving the rest of the series
+// as it is. In the organiser's calendar the occurrence is cancelled and the attendees are sent
+// the cancellation; in an attendee's calendar, such as a room's, it is removed, freeing the room.
+//
+// Parameters:
+//   - ctx: Cancels the requests.
+//   - userId: The ID or email of the mailbox.
+//   - instanceId: The ID of the occurrence, as listed in CalendarSeries.
+//   - comment: Sent to the attendees with a cancellation, may be empty.
+//
+// Returns:
+//   - bool: Whether the attendees were sent a cancellation.
+//   - error: An error object if the occurrence cannot be read or cancelled, otherwise nil.
+func (g *GraphHelper) CancelInstance(ctx context.Context, userId string, instanceId string, comment string) (bool, error) {
+	client, err := g.graphClient()
+	if err != nil {
+		return false, err
+	}
+
+	if err := validateUserId("mailbox", userId); err != nil {
+		return false, err
+	}
+
+	item := client.Users().ByUserId(userId).Events().ByEventId(instanceId)
+	instance, err := item.Get(ctx, &users.ItemEventsEventItemRequestBuilderGetRequestConfiguration{
+		QueryParameters: &users.ItemEventsEventItemRequestBuilderGetQueryParameters{
+			Select: []string{"id", "type", "isOrganizer"},
+		},
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to get occurrence: %v", err)
+	}
+	if instance.GetTypeEscaped() == nil || (*instance.GetTypeEscaped() != models.OCCURRENCE_EVENTTYPE && *instance.GetTypeEscaped() != models.EXCEPTION_EVENTTYPE) {
+		return false, fmt.Errorf("event %s is not an occurrence of a series", instanceId)
+	}
+
+	target := userId + "/events/" + instanceId
+	if instance.GetIsOrganizer() == nil || !*instance.GetIsOrganizer() {
+		ctx, audited := g.audit(ctx, "remove occurrence", target)
+		err = item.Delete(ctx, nil)
+		audited(err)
+		if err != nil {
+			return false, fmt.Errorf("failed to remove occurrence: %v", err)
+		}
+		return false, nil
+	}
+
+	requestBody := users.NewItemEventsItemCancelPostRequestBody()
+	if comment != "" {
+		requestBody.SetComment(&comment)
+	}
+	ctx, audited := g.audit(ctx, "cancel occurrence", target)
+	err = item.Cancel().Post(ctx, requestBody, nil)
+	audited(err)
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel occurrence: %v", err)
+	}
+	return true, nil
+}
+
+// CancelOccurrence cancels the occurrence of a recurring series on a day, in the configured
+// "TIME_ZONE", leaving the rest of the series as it is. See CancelInstance for how it is cancelled.
+//
+// Parameters:
+//   - ctx: Cancels the requests.
+//   - userId: The ID or email of the mailbox.
+//   - seriesMasterId: The ID of the series master.
+//   - occurrenceDate: Any time on the day of the occurrence.
+//
+// Returns:
+//   - bool: Whether the attendees were sent a cancellation.
+//   - error: An error object if the series has no occurrence that day, it is already cancelled,
+//     or a request fails, otherwise nil.
+func (g *GraphHelper) CancelOccurrence(ctx context.Context, userId string, seriesMasterId string, occurrenceDate time.Time) (bool, error) {
+	local := g.Config().TimeZone
+	if local == nil {
+		local = time.Local
+	}
+	day := occurrenceDate.In(local)
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, local)
+
+	instances, err := g.GetEventInstances(ctx, userId, seriesMasterId, start, start.AddDate(0, 0, 1))
+	if err != nil {
+		return false, err
+	}
+	var summaries []EventSummary
+	for _, instance := range instances {
+		summaries = append(summaries, g.NewEventSummary(instance))
+	}
+	occurrence, err := occurrenceOn(summaries, start)
+	if err != nil {
+		return false, fmt.Errorf("series %s: %v", seriesMasterId, err)
+	}
+	return g.CancelInstance(ctx, userId, occurrence.Id, "")
+}
+
+// occurrenceOn picks the occurrence starting on the day, leaving out ones that only run into it
+// from the day before.
+func occurrenceOn(instances []EventSummary, day time.Time) (EventSummary, error) {
+	var found []EventSummary
+	for _, instance := range instances {
+		start := instance.StartLocal.In(day.Location())
+		if instance.StartLocal.IsZero() || start.Year() != day.Year() || start.YearDay() != day.YearDay() {
+			continue
+		}
+		found = append(found, instance)
+	}
+	date := day.Format("2006-01-02")
+	switch {
+	case len(found) == 0:
+		return EventSummary{}, fmt.Errorf("no occurrence on %s", date)
+	case len(found) > 1:
+		return EventSummary{}, fmt.Errorf("%d occurrences on %s, cancel one of them by its id", len(found), date)
+	case found[0].IsCancelled != nil && *found[0].IsCancelled:
+		return EventSummary{}, fmt.Errorf("the occurrence on %s is already cancelled", date)
+	}
+	return found[0], nil
+}
+
+// WriteText writes the single events as a count and each series with its occurrences, numbered
+// from 1 across the series for choosing one.
+func (c CalendarSeries) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "Single events: %d, recurring series: %d\n", len(c.Single), len(c.Series))
+	n := 1
+	for _, series := range c.Series {
+		fmt.Fprintf(w, "\nSeries Id : %s\n", series.Id)
+		fmt.Fprintf(w, "  Subject: %s\n", orDefault(series.Subject, "(no subject)"))
+		fmt.Fprintf(w, "  Organiser: %s\n", orDefault(series.Organiser, "-"))
+		fmt.Fprintf(w, "  Recurs: %s\n", series.Pattern)
+		fmt.Fprintf(w, "  Occurrences: %d\n", len(series.Instances))
+		for _, instance := range series.Instances {
+			cancelled := ""
+			if instance.IsCancelled != nil && *instance.IsCancelled {
+				cancelled = " (cancelled)"
+			}
+			fmt.Fprintf(w, "    %d. %s  %s%s\n", n, instance.DisplayTime(), orDefault(instance.Id, "-"), cancelled)
+			n++
+		}
+	}
+}
+
+// Instances returns the occurrences of every series in the order WriteText numbers them.
+func (c CalendarSeries) Instances() []EventSummary {
+	var instances []EventSummary
+	for _, series := range c.Series {
+		instances = append(instances, series.Instances...)
+	}
+	return instances
+}
@@ -0,0 +1,108 @@
+package graphhelper
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/microsoft/kiota-abstractions-go/serialization"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+func TestRecurrenceSummary(t *testing.T) {
+	recurrence := func(patternType models.RecurrencePatternType, interval int32, days []models.DayOfWeek, configure func(models.RecurrencePatternable, models.RecurrenceRangeable)) models.PatternedRecurrenceable {
+		pattern := models.NewRecurrencePattern()
+		pattern.SetTypeEscaped(&patternType)
+		pattern.SetInterval(&interval)
+		pattern.SetDaysOfWeek(days)
+		span := models.NewRecurrenceRange()
+		configure(pattern, span)
+		r := models.NewPatternedRecurrence()
+		r.SetPattern(pattern)
+		r.SetRangeEscaped(span)
+		return r
+	}
+	endDate := models.ENDDATE_RECURRENCERANGETYPE
+	numbered := models.NUMBERED_RECURRENCERANGETYPE
+	noEnd := models.NOEND_RECURRENCERANGETYPE
+	third := models.THIRD_WEEKINDEX
+	occurrences, day, march := int32(10), int32(15), int32(3)
+	monday, friday := models.MONDAY_DAYOFWEEK, models.FRIDAY_DAYOFWEEK
+
+	tests := []struct {
+		name       string
+		recurrence models.PatternedRecurrenceable
+		want       string
+	}{
+		{"none", nil, "unknown recurrence"},
+		{"daily with no end", recurrence(models.DAILY_RECURRENCEPATTERNTYPE, 1, nil, func(p models.RecurrencePatternable, r models.RecurrenceRangeable) {
+			r.SetTypeEscaped(&noEnd)
+		}), "every day, with no end"},
+		{"fortnightly until a date", recurrence(models.WEEKLY_RECURRENCEPATTERNTYPE, 2, []models.DayOfWeek{monday, friday}, func(p models.RecurrencePatternable, r models.RecurrenceRangeable) {
+			r.SetTypeEscaped(&endDate)
+			r.SetEndDate(serialization.NewDateOnly(time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)))
+		}), "every 2 weeks on monday, friday, until 2024-06-30"},
+		{"monthly on a day", recurrence(models.ABSOLUTEMONTHLY_RECURRENCEPATTERNTYPE, 1, nil, func(p models.RecurrencePatternable, r models.RecurrenceRangeable) {
+			p.SetDayOfMonth(&day)
+			r.SetTypeEscaped(&numbered)
+			r.SetNumberOfOccurrences(&occurrences)
+		}), "every month on day 15, 10 times"},
+		{"yearly on a weekday", recurrence(models.RELATIVEYEARLY_RECURRENCEPATTERNTYPE, 1, []models.DayOfWeek{monday}, func(p models.RecurrencePatternable, r models.RecurrenceRangeable) {
+			p.SetIndex(&third)
+			p.SetMonth(&march)
+		}), "every year on the third monday of March"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := recurrenceSummary(test.recurrence); got != test.want {
+				t.Errorf("recurrenceSummary() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCalendarSeriesWriteText(t *testing.T) {
+	cancelled := true
+	calendar := CalendarSeries{
+		Single: []EventSummary{{Id: "event-1"}},
+		Series: []RecurringSeries{
+			{Id: "series-1", Subject: "Stand-up", Pattern: "every day", Instances: []EventSummary{{Id: "occurrence-1"}, {Id: "occurrence-2", IsCancelled: &cancelled}}},
+			{Id: "series-2", Pattern: "every week on friday", Instances: []EventSummary{{Id: "occurrence-3"}}},
+		},
+	}
+
+	var out strings.Builder
+	calendar.WriteText(&out)
+	for _, want := range []string{"Single events: 1, recurring series: 2", "Recurs: every day", "2. -  occurrence-2 (cancelled)", "3. -  occurrence-3"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("WriteText() = %q, want it to contain %q", out.String(), want)
+		}
+	}
+	if instances := calendar.Instances(); len(instances) != 3 || instances[2].Id != "occurrence-3" {
+		t.Errorf("Instances() = %+v, want the 3 occurrences in order", instances)
+	}
+}
+
+func TestOccurrenceOn(t *testing.T) {
+	day := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	cancelled := true
+	overnight := EventSummary{Id: "overnight", StartLocal: day.Add(-time.Hour)}
+	morning := EventSummary{Id: "morning", StartLocal: day.Add(9 * time.Hour)}
+	gone := EventSummary{Id: "gone", StartLocal: day.Add(9 * time.Hour), IsCancelled: &cancelled}
+
+	if got, err := occurrenceOn([]EventSummary{overnight, morning}, day); err != nil || got.Id != "morning" {
+		t.Errorf("occurrenceOn() = %q, %v; want the occurrence starting that day", got.Id, err)
+	}
+	for _, test := range []struct {
+		instances []EventSummary
+		want      string
+	}{
+		{[]EventSummary{overnight}, "no occurrence on 2024-03-04"},
+		{[]EventSummary{gone}, "already cancelled"},
+		{[]EventSummary{morning, morning}, "2 occurrences"},
+	} {
+		if _, err := occurrenceOn(test.instances, day); err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("occurrenceOn() = %v, want an error containing %q", err, test.want)
+		}
+	}
+}
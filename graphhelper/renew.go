@@ -0,0 +1,52 @@
+package graphhelper
+
+import (
+	"fmt"
+	"time"
+)
+
+// MaxSubscriptionLifetime is the longest Graph lets an Outlook events subscription live,
+// just under 7 days (10080 minutes).
+const MaxSubscriptionLifetime = 10070 * time.Minute
+
+// RenewAllSubscriptions extends every subscription the app can see to now+extendBy,
+// clamped to MaxSubscriptionLifetime, and prints the outcome for each one.
+//
+// Parameters:
+//   - extendBy: How long from now each subscription should live.
+//
+// Returns:
+//   - int: The number of subscriptions renewed.
+//   - error: An error object if listing fails or any renewal fails, otherwise nil.
+func (g *GraphHelper) RenewAllSubscriptions(extendBy time.Duration) (int, error) {
+
+	if extendBy > MaxSubscriptionLifetime {
+		extendBy = MaxSubscriptionLifetime
+	}
+	expiration := time.Now().Add(extendBy)
+
+	subscriptions, err := g.ListSubscriptions()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list subscriptions: %v", err)
+	}
+
+	renewed, failed := 0, 0
+	for _, subscription := range subscriptions {
+		if subscription.GetId() == nil {
+			continue
+		}
+		id := *subscription.GetId()
+		if err := g.RenewSubscription(id, expiration); err != nil {
+			fmt.Printf("SubscriptionId: %s\n  Renew failed: %v\n", id, err)
+			failed++
+			continue
+		}
+		fmt.Printf("SubscriptionId: %s\n  Renewed until: %s\n", id, formatQueryTime(expiration))
+		renewed++
+	}
+
+	if failed > 0 {
+		return renewed, fmt.Errorf("failed to renew %d of %d subscriptions", failed, renewed+failed)
+	}
+	return renewed, nil
+}
@@ -0,0 +1,90 @@
+package graphhelper
+
+import (
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// requestIdHistory is how many Graph requests are remembered for RecentRequests.
+const requestIdHistory = 20
+
+// RequestRecord identifies one Graph request for correlation with Microsoft support.
+type RequestRecord struct {
+	Time            time.Time
+	Method          string
+	Path            string
+	Status          int
+	ClientRequestId string
+	RequestId       string
+}
+
+// requestHistory keeps the most recent requests across re-initializations of the client.
+type requestHistory struct {
+	mu      sync.Mutex
+	records []RequestRecord
+}
+
+// requestIdTransport records the client-request-id and request-id of every Graph request,
+// logging both when a request fails. It wraps the middleware pipeline, where the Graph telemetry
+// handler already tags each request with a client-request-id, so it takes the id Graph echoes in
+// its response rather than adding a second one.
+type requestIdTransport struct {
+	next    http.RoundTripper
+	history *requestHistory
+}
+
+func (t *requestIdTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	record := RequestRecord{
+		Time:   time.Now(),
+		Method: req.Method,
+		Path:   req.URL.Path,
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	clientRequestId := sentRequestId(req, resp)
+	record.ClientRequestId = clientRequestId
+	if err != nil {
+		log.Printf("Graph request %s %s failed [client-request-id: %s]: %v", record.Method, record.Path, clientRequestId, err)
+	} else {
+		record.Status = resp.StatusCode
+		record.RequestId = resp.Header.Get("request-id")
+		if resp.StatusCode >= 400 {
+			log.Printf("Graph request %s %s returned %d [client-request-id: %s, request-id: %s]",
+				record.Method, record.Path, resp.StatusCode, clientRequestId, record.RequestId)
+		}
+	}
+	t.history.remember(record)
+	if trail := auditTrailOf(req.Context()); trail != nil {
+		trail.add(record)
+	}
+	return resp, err
+}
+
+func (h *requestHistory) remember(record RequestRecord) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records = append(h.records, record)
+	if len(h.records) > requestIdHistory {
+		h.records = h.records[len(h.records)-requestIdHistory:]
+	}
+}
+
+// RecentRequests returns the most recent Graph requests, oldest first.
+func (g *GraphHelper) RecentRequests() []RequestRecord {
+	g.requests.mu.Lock()
+	defer g.requests.mu.Unlock()
+	return append([]RequestRecord(nil), g.requests.records...)
+}
+
+// sentRequestId returns the client-request-id a request went out with: the one Graph echoes in
+// its response, or without a response the one the pipeline set on the request.
+func sentRequestId(req *http.Request, resp *http.Response) string {
+	if resp != nil {
+		if id := resp.Header.Get("client-request-id"); id != "" {
+			return id
+		}
+	}
+	return req.Header.Get("client-request-id")
+}
@@ -0,0 +1,63 @@
+package graphhelper
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// telemetryTransport tags requests the way the Graph telemetry handler in the pipeline does.
+type telemetryTransport struct {
+	next http.RoundTripper
+	id   string
+}
+
+func (t *telemetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.Header.Add("client-request-id", t.id)
+	return t.next.RoundTrip(req)
+}
+
+type failingTransport struct{}
+
+func (failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestRequestIdTransportRecordsTheSentId(t *testing.T) {
+	var sent []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = r.Header.Values("client-request-id")
+		w.Header().Set("client-request-id", r.Header.Get("client-request-id"))
+		w.Header().Set("request-id", "graph-request-1")
+	}))
+	defer server.Close()
+
+	history := &requestHistory{}
+	transport := &requestIdTransport{next: &telemetryTransport{next: http.DefaultTransport, id: "pipeline-id"}, history: history}
+	request, _ := http.NewRequest("GET", server.URL, nil)
+	response, err := transport.RoundTrip(request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	response.Body.Close()
+
+	if len(sent) != 1 || sent[0] != "pipeline-id" {
+		t.Errorf("sent client-request-id %v, want only the pipeline's", sent)
+	}
+	if len(history.records) != 1 || history.records[0].ClientRequestId != "pipeline-id" || history.records[0].RequestId != "graph-request-1" {
+		t.Errorf("records = %+v, want the id sent and Graph's request id", history.records)
+	}
+}
+
+func TestRequestIdTransportRecordsTheIdOfAFailedRequest(t *testing.T) {
+	history := &requestHistory{}
+	transport := &requestIdTransport{next: &telemetryTransport{next: failingTransport{}, id: "pipeline-id"}, history: history}
+	request, _ := http.NewRequest("GET", "https://graph.microsoft.com/v1.0/users", nil)
+	if _, err := transport.RoundTrip(request); err == nil {
+		t.Fatal("RoundTrip() succeeded, want the transport's error")
+	}
+	if len(history.records) != 1 || history.records[0].ClientRequestId != "pipeline-id" {
+		t.Errorf("records = %+v, want the id set on the request", history.records)
+	}
+}
@@ -0,0 +1,72 @@
+package graphhelper
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+)
+
+// EventResponses are the accepted keywords for RespondToEvent.
+var EventResponses = []string{"accept", "tentativelyAccept", "decline"}
+
+// RespondToEvent accepts, tentatively accepts or declines a meeting request on behalf of a user or room.
+//
+// Parameters:
+//   - userId: The ID or email of the attendee responding.
+//   - eventId: The ID of the meeting request in the attendee's calendar.
+//   - response: One of "accept", "tentativelyAccept" or "decline".
+//   - comment: An optional comment sent to the organiser.
+//
+// Returns:
+//   - error: An error object if the response is not valid or the request fails, otherwise nil.
+func (g *GraphHelper) RespondToEvent(userId string, eventId string, response string, comment string) error {
+	client, err := g.graphClient()
+	if err != nil {
+		return err
+	}
+
+	event := client.Users().ByUserId(userId).Events().ByEventId(eventId)
+
+	// Only meeting requests from someone else can be responded to
+	existing, err := event.Get(context.Background(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to get event: %v", err)
+	}
+	if existing.GetIsOrganizer() != nil && *existing.GetIsOrganizer() {
+		return fmt.Errorf("event %s is organised by %s, not a meeting request it can respond to", eventId, userId)
+	}
+
+	ctx, audited := g.audit(context.Background(), response+" event", userId+"/events/"+eventId)
+	sendResponse := true
+	switch response {
+	case "accept":
+		requestBody := users.NewItemEventsItemAcceptPostRequestBody()
+		requestBody.SetSendResponse(&sendResponse)
+		if comment != "" {
+			requestBody.SetComment(&comment)
+		}
+		err = event.Accept().Post(ctx, requestBody, nil)
+	case "tentativelyAccept":
+		requestBody := users.NewItemEventsItemTentativelyAcceptPostRequestBody()
+		requestBody.SetSendResponse(&sendResponse)
+		if comment != "" {
+			requestBody.SetComment(&comment)
+		}
+		err = event.TentativelyAccept().Post(ctx, requestBody, nil)
+	case "decline":
+		requestBody := users.NewItemEventsItemDeclinePostRequestBody()
+		requestBody.SetSendResponse(&sendResponse)
+		if comment != "" {
+			requestBody.SetComment(&comment)
+		}
+		err = event.Decline().Post(ctx, requestBody, nil)
+	default:
+		return fmt.Errorf("unknown response %q, expected one of %v", response, EventResponses)
+	}
+	audited(err)
+	if err != nil {
+		return fmt.Errorf("failed to %s event: %v", response, err)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package graphhelper
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// richNotificationFields are the event properties Graph includes in rich notifications.
+const richNotificationFields = "subject,start,end,organizer,isCancelled,categories,sensitivity"
+
+// NotificationCertificate is the certificate Graph encrypts resource data with for rich
+// notifications, together with the private key used to decrypt it.
+type NotificationCertificate struct {
+	Id          string
+	certificate []byte // DER
+	key         *rsa.PrivateKey
+}
+
+// EncryptedContent is the encryptedContent of a rich notification.
+type EncryptedContent struct {
+	Data                            string `json:"data"`
+	DataSignature                   string `json:"dataSignature"`
+	DataKey                         string `json:"dataKey"`
+	EncryptionCertificateId         string `json:"encryptionCertificateId"`
+	EncryptionCertificateThumbprint string `json:"encryptionCertificateThumbprint"`
+}
+
+// LoadNotificationCertificate reads a PEM certificate and its RSA private key.
+//
+// Parameters:
+//   - certFile: The PEM file holding the certificate sent to Graph.
+//   - keyFile: The PEM file holding the certificate's RSA private key.
+//   - id: The id Graph echoes back so the certificate can be identified.
+//
+// Returns:
+//   - *NotificationCertificate: The certificate and key.
+//   - error: An error object if the files cannot be read or the key is not RSA, otherwise nil.
+func LoadNotificationCertificate(certFile string, keyFile string, id string) (*NotificationCertificate, error) {
+	pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load notification certificate: %v", err)
+	}
+	key, ok := pair.PrivateKey.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("failed to load notification certificate: the private key is not an RSA key")
+	}
+	return &NotificationCertificate{Id: id, certificate: pair.Certificate[0], key: key}, nil
+}
+
+// SetNotificationCertificate makes new subscriptions ask for rich notifications encrypted
+// with the given certificate. A nil certificate turns rich notifications off.
+func (g *GraphHelper) SetNotificationCertificate(certificate *NotificationCertificate) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.notificationCertificate = certificate
+}
+
+func (g *GraphHelper) getNotificationCertificate() *NotificationCertificate {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.notificationCertificate
+}
+
+// Decrypt returns the resource data of a rich notification, as JSON.
+// The symmetric key is unwrapped with RSA-OAEP, the data signature is checked with
+// HMAC-SHA256, and the data is decrypted with AES-CBC using the first 16 bytes of the key as IV.
+func (c *NotificationCertificate) Decrypt(content EncryptedContent) ([]byte, error) {
+	if content.EncryptionCertificateId != "" && content.EncryptionCertificateId != c.Id {
+		return nil, fmt.Errorf("notification was encrypted for certificate %q, not %q", content.EncryptionCertificateId, c.Id)
+	}
+
+	dataKey, err := base64.StdEncoding.DecodeString(content.DataKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dataKey: %v", err)
+	}
+	key, err := rsa.DecryptOAEP(sha1.New(), rand.Reader, c.key, dataKey, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt dataKey: %v", err)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(content.Data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid data: %v", err)
+	}
+	signature, err := base64.StdEncoding.DecodeString(content.DataSignature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dataSignature: %v", err)
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	if !hmac.Equal(mac.Sum(nil), signature) {
+		return nil, errors.New("notification data signature does not match, it may have been tampered with")
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid symmetric key: %v", err)
+	}
+	if len(data) == 0 || len(data)%aes.BlockSize != 0 {
+		return nil, errors.New("notification data is not a whole number of blocks")
+	}
+	plain := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, key[:aes.BlockSize]).CryptBlocks(plain, data)
+
+	// remove the PKCS#7 padding
+	padding := int(plain[len(plain)-1])
+	if padding == 0 || padding > aes.BlockSize || !bytes.Equal(plain[len(plain)-padding:], bytes.Repeat([]byte{byte(padding)}, padding)) {
+		return nil, errors.New("notification data has invalid padding")
+	}
+	return plain[:len(plain)-padding], nil
+}
+
+// encodedCertificate returns the certificate as Graph expects it in encryptionCertificate.
+func (c *NotificationCertificate) encodedCertificate() string {
+	return base64.StdEncoding.EncodeToString(c.certificate)
+}
@@ -0,0 +1,166 @@
+package graphhelper
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCertificate writes a self-signed certificate and key to a temporary directory
+// and loads them as a notification certificate.
+func testCertificate(t *testing.T) *NotificationCertificate {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "msgraph-cli notifications"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}), 0600)
+
+	certificate, err := LoadNotificationCertificate(certFile, keyFile, "test-cert")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if certificate.encodedCertificate() != base64.StdEncoding.EncodeToString(der) {
+		t.Fatal("encodedCertificate() is not the DER certificate")
+	}
+	return certificate
+}
+
+// encryptLikeGraph encrypts data the way Graph does for rich notifications.
+func encryptLikeGraph(t *testing.T, certificate *NotificationCertificate, key []byte, data []byte) EncryptedContent {
+	t.Helper()
+	dataKey, err := rsa.EncryptOAEP(sha1.New(), rand.Reader, &certificate.key.PublicKey, key, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	padding := aes.BlockSize - len(data)%aes.BlockSize
+	padded := append(append([]byte(nil), data...), bytes.Repeat([]byte{byte(padding)}, padding)...)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	encrypted := make([]byte, len(padded))
+	cipher.NewCBCEncrypter(block, key[:aes.BlockSize]).CryptBlocks(encrypted, padded)
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(encrypted)
+
+	return EncryptedContent{
+		Data:                    base64.StdEncoding.EncodeToString(encrypted),
+		DataSignature:           base64.StdEncoding.EncodeToString(mac.Sum(nil)),
+		DataKey:                 base64.StdEncoding.EncodeToString(dataKey),
+		EncryptionCertificateId: certificate.Id,
+	}
+}
+
+func TestNotificationCertificateDecrypt(t *testing.T) {
+	certificate := testCertificate(t)
+	key := []byte("0123456789abcdef0123456789abcdef")
+
+	event := `{"subject":"Weekly sync","isCancelled":false}`
+	content := encryptLikeGraph(t, certificate, key, []byte(event))
+	// AES-256-CBC with the IV taken from the key is deterministic, this vector matches
+	// openssl enc -aes-256-cbc -K <key> -iv <first 16 bytes of key>
+	const knownData = "XrVIPlPfDn5xqlmam+ul2ePkt4XAjUJKWXA/Jku803KG+Kvtr6wwbpg8tQOwVwY3"
+	if content.Data != knownData {
+		t.Fatalf("test vector data = %s, want %s", content.Data, knownData)
+	}
+
+	got, err := certificate.Decrypt(content)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(got) != event {
+		t.Errorf("Decrypt() = %q, want %q", got, event)
+	}
+}
+
+// TestNotificationCertificateDecryptKnownVector decrypts a notification made with openssl rather
+// than this package, following Graph's documented scheme: the key wrapped with RSA-OAEP (SHA-1),
+// the data encrypted with AES-256-CBC using the first 16 bytes of the key as IV, and signed with
+// HMAC-SHA256 over the encrypted data. See testdata/notification.json.
+func TestNotificationCertificateDecryptKnownVector(t *testing.T) {
+	certificate, err := LoadNotificationCertificate(filepath.Join("testdata", "notification-cert.pem"), filepath.Join("testdata", "notification-key.pem"), "test-cert")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fixture, err := os.ReadFile(filepath.Join("testdata", "notification.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var notification struct {
+		EncryptedContent EncryptedContent `json:"encryptedContent"`
+	}
+	if err := json.Unmarshal(fixture, &notification); err != nil {
+		t.Fatalf("fixture is not valid JSON: %v", err)
+	}
+	want, err := os.ReadFile(filepath.Join("testdata", "notification-plain.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := certificate.Decrypt(notification.EncryptedContent)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Decrypt() = %q, want %q", got, want)
+	}
+}
+
+func TestNotificationCertificateRejectsTampering(t *testing.T) {
+	certificate := testCertificate(t)
+	key := []byte("0123456789abcdef0123456789abcdef")
+	content := encryptLikeGraph(t, certificate, key, []byte(`{"subject":"Weekly sync"}`))
+
+	tampered := content
+	data, _ := base64.StdEncoding.DecodeString(content.Data)
+	data[0] ^= 0xff
+	tampered.Data = base64.StdEncoding.EncodeToString(data)
+	if _, err := certificate.Decrypt(tampered); err == nil || !strings.Contains(err.Error(), "signature") {
+		t.Errorf("Decrypt() of tampered data = %v, want a signature error", err)
+	}
+
+	otherCertificate := content
+	otherCertificate.EncryptionCertificateId = "other-cert"
+	if _, err := certificate.Decrypt(otherCertificate); err == nil {
+		t.Error("Decrypt() accepted content encrypted for another certificate")
+	}
+
+	badKey := content
+	badKey.DataKey = base64.StdEncoding.EncodeToString([]byte("not an RSA encrypted key"))
+	if _, err := certificate.Decrypt(badKey); err == nil {
+		t.Error("Decrypt() accepted an invalid dataKey")
+	}
+}
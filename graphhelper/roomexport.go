@@ -0,0 +1,90 @@
+package graphhelper
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// DefaultRoomsCSV is the file rooms are exported to.
+const DefaultRoomsCSV = "rooms.csv"
+
+// roomCSVHeader names the columns written by WriteRoomsCSV.
+var roomCSVHeader = []string{"name", "email", "capacity", "building", "floor", "features"}
+
+// roomFeatures lists what a room is equipped with: its devices, wheelchair access and tags.
+func roomFeatures(room models.Roomable) []string {
+	var features []string
+	for _, device := range []*string{room.GetAudioDeviceName(), room.GetVideoDeviceName(), room.GetDisplayDeviceName()} {
+		if name := StringOrDefault(device, ""); name != "" {
+			features = append(features, name)
+		}
+	}
+	if room.GetIsWheelChairAccessible() != nil && *room.GetIsWheelChairAccessible() {
+		features = append(features, "wheelchair accessible")
+	}
+	return append(features, room.GetTags()...)
+}
+
+// WriteRoomsCSV writes the rooms as CSV with a header row. Unset fields are left empty, and
+// the features are separated by semicolons.
+func WriteRoomsCSV(w io.Writer, rooms []models.Roomable) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(roomCSVHeader); err != nil {
+		return err
+	}
+	for _, room := range rooms {
+		capacity := ""
+		if room.GetCapacity() != nil {
+			capacity = strconv.Itoa(int(*room.GetCapacity()))
+		}
+		floor := StringOrDefault(room.GetFloorLabel(), "")
+		if floor == "" && room.GetFloorNumber() != nil {
+			floor = strconv.Itoa(int(*room.GetFloorNumber()))
+		}
+		err := writer.Write([]string{
+			StringOrDefault(room.GetDisplayName(), ""), StringOrDefault(room.GetEmailAddress(), ""), capacity,
+			StringOrDefault(room.GetBuilding(), ""), floor, strings.Join(roomFeatures(room), "; "),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// ExportRoomsCSV writes every room in the tenant, with its capacity, building, floor and
+// features, to a CSV file for facilities reporting.
+//
+// Parameters:
+//   - path: The file to write. An existing file is replaced.
+//
+// Returns:
+//   - int: The number of rooms written.
+//   - error: An error object if the rooms cannot be listed or the file written, otherwise nil.
+func (g *GraphHelper) ExportRoomsCSV(path string) (int, error) {
+
+	rooms, err := g.GetRooms()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list rooms: %v", err)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create %s: %v", path, err)
+	}
+	if err := WriteRoomsCSV(file, rooms); err != nil {
+		file.Close()
+		return 0, fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	if err := file.Close(); err != nil {
+		return 0, fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	return len(rooms), nil
+}
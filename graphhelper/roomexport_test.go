@@ -0,0 +1,39 @@
+package graphhelper
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+func TestWriteRoomsCSV(t *testing.T) {
+	name, email, building, floorLabel, video := "Boardroom", "boardroom@example.com", "HQ, North", "Level 3", "Surface Hub"
+	capacity, floorNumber, accessible := int32(12), int32(2), true
+	full := models.NewRoom()
+	full.SetDisplayName(&name)
+	full.SetEmailAddress(&email)
+	full.SetCapacity(&capacity)
+	full.SetBuilding(&building)
+	full.SetFloorLabel(&floorLabel)
+	full.SetVideoDeviceName(&video)
+	full.SetIsWheelChairAccessible(&accessible)
+	full.SetTags([]string{"quiet"})
+	numbered := models.NewRoom()
+	numbered.SetFloorNumber(&floorNumber)
+	// Places can return rooms with nothing but an id
+	empty := models.NewRoom()
+
+	var out strings.Builder
+	if err := WriteRoomsCSV(&out, []models.Roomable{full, numbered, empty}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "name,email,capacity,building,floor,features\n" +
+		"Boardroom,boardroom@example.com,12,\"HQ, North\",Level 3,Surface Hub; wheelchair accessible; quiet\n" +
+		",,,,2,\n" +
+		",,,,,\n"
+	if out.String() != want {
+		t.Errorf("WriteRoomsCSV() =\n%s\nwant\n%s", out.String(), want)
+	}
+}
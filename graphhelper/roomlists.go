@@ -0,0 +1,177 @@
+package graphhelper
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// AgendaItem is one event in a room list agenda, annotated with the room it is in.
+type AgendaItem struct {
+	RoomName  string
+	RoomEmail string
+	Start     time.Time
+	End       time.Time
+	AllDay    bool // Start and End are midnight in UTC of the dates covered
+	Event     models.Eventable
+}
+
+// When shows the time of the item in location, or the dates covered by an all day item.
+func (item AgendaItem) When(location *time.Location) string {
+	if item.Start.IsZero() {
+		return "-"
+	}
+	if item.AllDay {
+		return formatAllDay(item.Start, item.End)
+	}
+	end := "-"
+	if !item.End.IsZero() {
+		end = item.End.In(location).Format(TimeOfDayLayout)
+	}
+	return item.Start.In(location).Format(DisplayLayout) + " - " + end
+}
+
+// GetRoomLists returns the room lists (usually one per building) in the tenant,
+// following @odata.nextLink page by page.
+func (g *GraphHelper) GetRoomLists() ([]models.RoomListable, error) {
+	client, err := g.graphClient()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := client.Places().GraphRoomList().Get(context.Background(), nil)
+
+	var roomLists []models.RoomListable
+	for page := 1; ; page++ {
+		if err != nil {
+			g.reportProgress("room lists", page, len(roomLists), true)
+			return nil, err
+		}
+		roomLists = append(roomLists, result.GetValue()...)
+
+		nextLink := result.GetOdataNextLink()
+		if nextLink == nil {
+			g.reportProgress("room lists", page, len(roomLists), true)
+			return roomLists, nil
+		}
+		g.reportProgress("room lists", page, len(roomLists), false)
+
+		result, err = client.Places().GraphRoomList().WithUrl(*nextLink).Get(context.Background(), nil)
+	}
+}
+
+// GetRoomListRooms returns the rooms in a room list.
+//
+// Parameters:
+//   - roomListId: The ID or email of the room list.
+//
+// Returns:
+//   - []models.Roomable: The rooms in the list.
+//   - error: An error object if the request fails, otherwise nil.
+func (g *GraphHelper) GetRoomListRooms(roomListId string) ([]models.Roomable, error) {
+	client, err := g.graphClient()
+	if err != nil {
+		return nil, err
+	}
+
+	builder := client.Places().ByPlaceId(roomListId).GraphRoomList().Rooms()
+	result, err := builder.Get(context.Background(), nil)
+
+	var rooms []models.Roomable
+	for page := 1; ; page++ {
+		if err != nil {
+			g.reportProgress("rooms", page, len(rooms), true)
+			return nil, err
+		}
+		rooms = append(rooms, result.GetValue()...)
+
+		nextLink := result.GetOdataNextLink()
+		if nextLink == nil {
+			g.reportProgress("rooms", page, len(rooms), true)
+			return rooms, nil
+		}
+		g.reportProgress("rooms", page, len(rooms), false)
+
+		result, err = builder.WithUrl(*nextLink).Get(context.Background(), nil)
+	}
+}
+
+// GetRoomListAgenda fetches the calendar view of every room in a room list and merges the
+// events into one agenda sorted by start time. Rooms are fetched one after another; a room
+// whose calendar cannot be read is recorded in the summary and the others are still listed.
+//
+// Parameters:
+//   - roomListId: The ID or email of the room list.
+//   - start: The start of the window.
+//   - end: The end of the window.
+//
+// Returns:
+//   - []AgendaItem: The events of all rooms, earliest first.
+//   - RoomSummary: The outcome for each room.
+//   - error: An error object if the rooms of the list cannot be read, otherwise nil.
+func (g *GraphHelper) GetRoomListAgenda(roomListId string, start time.Time, end time.Time) ([]AgendaItem, RoomSummary, error) {
+	rooms, err := g.GetRoomListRooms(roomListId)
+	if err != nil {
+		return nil, RoomSummary{}, err
+	}
+
+	names := map[string]string{}
+	var emails []string
+	for _, room := range rooms {
+		if room.GetEmailAddress() == nil {
+			continue
+		}
+		email := *room.GetEmailAddress()
+		names[email] = StringOrDefault(room.GetDisplayName(), email)
+		emails = append(emails, email)
+	}
+
+	var agenda []AgendaItem
+	summary := ForEachRoom(emails, func(email string) error {
+		events, err := g.GetCalendarView(email, start, end)
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			agenda = append(agenda, newAgendaItem(names[email], email, event))
+		}
+		return nil
+	})
+
+	sortAgenda(agenda)
+	return agenda, summary, nil
+}
+
+// newAgendaItem reads the start and end of an event, leaving them zero when they are not set.
+func newAgendaItem(roomName string, roomEmail string, event models.Eventable) AgendaItem {
+	item := AgendaItem{RoomName: roomName, RoomEmail: roomEmail, Event: event}
+	if event.GetIsAllDay() != nil && *event.GetIsAllDay() {
+		item.AllDay = true
+		if dateTime := dateTimeOf(event.GetStart()); dateTime != nil {
+			item.Start, _ = allDayDate(*dateTime, time.UTC)
+		}
+		if dateTime := dateTimeOf(event.GetEnd()); dateTime != nil {
+			item.End, _ = allDayDate(*dateTime, time.UTC)
+		}
+		return item
+	}
+	if dateTime := dateTimeOf(event.GetStart()); dateTime != nil {
+		item.Start, _ = parseFromGraph(*dateTime)
+	}
+	if dateTime := dateTimeOf(event.GetEnd()); dateTime != nil {
+		item.End, _ = parseFromGraph(*dateTime)
+	}
+	return item
+}
+
+// sortAgenda orders an agenda by start time, then by room name.
+func sortAgenda(agenda []AgendaItem) {
+	sort.SliceStable(agenda, func(i, j int) bool {
+		if !agenda[i].Start.Equal(agenda[j].Start) {
+			return agenda[i].Start.Before(agenda[j].Start)
+		}
+		return agenda[i].RoomName < agenda[j].RoomName
+	})
+}
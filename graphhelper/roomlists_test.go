@@ -0,0 +1,36 @@
+package graphhelper
+
+import (
+	"testing"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+func newTestEvent(subject string, start string) models.Eventable {
+	event := models.NewEvent()
+	event.SetSubject(&subject)
+	if start != "" {
+		event.SetStart(models.NewDateTimeTimeZone())
+		event.GetStart().SetDateTime(&start)
+	}
+	return event
+}
+
+func TestSortAgenda(t *testing.T) {
+	agenda := []AgendaItem{
+		newAgendaItem("Boardroom", "board@example.com", newTestEvent("late", "2024-03-01T15:00:00.0000000")),
+		newAgendaItem("Atrium", "atrium@example.com", newTestEvent("early", "2024-03-01T09:00:00.0000000")),
+		newAgendaItem("Boardroom", "board@example.com", newTestEvent("same time b", "2024-03-01T12:00:00.0000000")),
+		newAgendaItem("Atrium", "atrium@example.com", newTestEvent("same time a", "2024-03-01T12:00:00.0000000")),
+		newAgendaItem("Atrium", "atrium@example.com", newTestEvent("no start", "")),
+	}
+
+	sortAgenda(agenda)
+
+	want := []string{"no start", "early", "same time a", "same time b", "late"}
+	for i, item := range agenda {
+		if got := StringOrDefault(item.Event.GetSubject(), ""); got != want[i] {
+			t.Errorf("agenda[%d] = %q, want %q", i, got, want[i])
+		}
+	}
+}
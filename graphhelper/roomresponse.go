@@ -0,0 +1,64 @@
+package graphhelper
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// DefaultRoomResponseTimeout is how long to wait for a room to answer a booking.
+const DefaultRoomResponseTimeout = 15 * time.Second
+
+// roomResponsePoll is how often the organiser's event is read while waiting for the room.
+const roomResponsePoll = 2 * time.Second
+
+// WaitForRoomResponse reads the organiser's event until the room has accepted, tentatively
+// accepted or declined it, or the timeout passes. Room mailboxes process invitations
+// automatically, but a created event is only booked once the room has accepted.
+//
+// Parameters:
+//   - eventId: The ID of the event in the organiser's calendar.
+//   - roomEmail: The email of the room invited to the event.
+//   - timeout: How long to wait for the room to respond.
+//
+// Returns:
+//   - models.ResponseType: The room's response, NOTRESPONDED_RESPONSETYPE if it has not responded in time.
+//   - error: An error object if the event cannot be read or the room is not invited, otherwise nil.
+func (g *GraphHelper) WaitForRoomResponse(eventId string, roomEmail string, timeout time.Duration) (models.ResponseType, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		event, err := g.GetOrganiserEvent(eventId)
+		if err != nil {
+			return models.NONE_RESPONSETYPE, err
+		}
+		response, invited := roomResponse(event, roomEmail)
+		if !invited {
+			return models.NONE_RESPONSETYPE, fmt.Errorf("room %s is not an attendee of event %s", roomEmail, eventId)
+		}
+		switch response {
+		case models.ACCEPTED_RESPONSETYPE, models.TENTATIVELYACCEPTED_RESPONSETYPE, models.DECLINED_RESPONSETYPE:
+			return response, nil
+		}
+
+		if !time.Now().Add(roomResponsePoll).Before(deadline) {
+			return models.NOTRESPONDED_RESPONSETYPE, nil
+		}
+		time.Sleep(roomResponsePoll)
+	}
+}
+
+// roomResponse returns how the room has responded to an event, and whether it is invited at all.
+func roomResponse(event models.Eventable, roomEmail string) (models.ResponseType, bool) {
+	for _, attendee := range event.GetAttendees() {
+		if attendee.GetEmailAddress() == nil || !strings.EqualFold(StringOrDefault(attendee.GetEmailAddress().GetAddress(), ""), roomEmail) {
+			continue
+		}
+		if attendee.GetStatus() == nil || attendee.GetStatus().GetResponse() == nil {
+			return models.NONE_RESPONSETYPE, true
+		}
+		return *attendee.GetStatus().GetResponse(), true
+	}
+	return models.NONE_RESPONSETYPE, false
+}
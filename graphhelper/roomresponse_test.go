@@ -0,0 +1,31 @@
+package graphhelper
+
+import (
+	"testing"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+func TestRoomResponse(t *testing.T) {
+	event := models.NewEvent()
+	person := newAttendee("alice@example.com", models.REQUIRED_ATTENDEETYPE)
+	accepted := models.ACCEPTED_RESPONSETYPE
+	person.SetStatus(models.NewResponseStatus())
+	person.GetStatus().SetResponse(&accepted)
+	room := newAttendee("Room@example.com", models.RESOURCE_ATTENDEETYPE)
+	declined := models.DECLINED_RESPONSETYPE
+	room.SetStatus(models.NewResponseStatus())
+	room.GetStatus().SetResponse(&declined)
+	other := newAttendee("other-room@example.com", models.RESOURCE_ATTENDEETYPE)
+	event.SetAttendees([]models.Attendeeable{person, room, other})
+
+	if response, invited := roomResponse(event, "room@example.com"); !invited || response != models.DECLINED_RESPONSETYPE {
+		t.Errorf("roomResponse(room) = %v, %t, want declined, true", response, invited)
+	}
+	if response, invited := roomResponse(event, "other-room@example.com"); !invited || response != models.NONE_RESPONSETYPE {
+		t.Errorf("roomResponse(other room) = %v, %t, want none, true", response, invited)
+	}
+	if _, invited := roomResponse(event, "missing@example.com"); invited {
+		t.Error("roomResponse() found a room that is not invited")
+	}
+}
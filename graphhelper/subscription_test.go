@@ -0,0 +1,38 @@
+package graphhelper
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestGroupEventsResource(t *testing.T) {
+	got := groupEventsResource("5f3e1c4a-0b1d-4c2e-9a7f-123456789abc")
+	if want := "/groups/5f3e1c4a-0b1d-4c2e-9a7f-123456789abc/events"; got != want {
+		t.Errorf("groupEventsResource() = %q, want %q", got, want)
+	}
+	if !sameResource(got, "Groups/5F3E1C4A-0B1D-4C2E-9A7F-123456789ABC/Events") {
+		t.Errorf("sameResource() = false, want Graph's echo of %q to match", got)
+	}
+}
+
+func TestCreateGroupEventsSubscriptionRejectsInvalidIds(t *testing.T) {
+	g := NewGraphHelper(&Config{})
+
+	tests := []struct {
+		name    string
+		groupId string
+	}{
+		{name: "empty", groupId: ""},
+		{name: "email", groupId: "rooms@example.com"},
+		{name: "path", groupId: "5f3e1c4a-0b1d-4c2e-9a7f-123456789abc/events"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := g.CreateGroupEventsSubscription(context.Background(), tt.groupId, false)
+			if err == nil || !strings.Contains(err.Error(), "not an object id") {
+				t.Errorf("CreateGroupEventsSubscription(%q) = %v, want an object id error", tt.groupId, err)
+			}
+		})
+	}
+}
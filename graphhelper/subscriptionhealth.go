@@ -0,0 +1,98 @@
+package graphhelper
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// DefaultSubscriptionExpiryWarning is how close to expiry a subscription is reported by the health check.
+const DefaultSubscriptionExpiryWarning = 24 * time.Hour
+
+// SubscriptionWarning is a problem found by the subscription health check: a subscription
+// about to expire, or an expected resource without one.
+type SubscriptionWarning struct {
+	Resource       string
+	SubscriptionId string    // empty when the resource has no subscription
+	Expires        time.Time // zero when the resource has no subscription
+}
+
+// Missing reports whether the warning is for a resource without a subscription.
+func (w SubscriptionWarning) Missing() bool {
+	return w.SubscriptionId == ""
+}
+
+// String describes the warning for the log.
+func (w SubscriptionWarning) String() string {
+	if w.Missing() {
+		return fmt.Sprintf("no subscription for %s", w.Resource)
+	}
+	return fmt.Sprintf("subscription %s for %s expires %s", w.SubscriptionId, w.Resource, formatQueryTime(w.Expires))
+}
+
+// ExpectedSubscriptionResources returns the resources the tool subscribes to on startup,
+// the events of "ROOM_EMAIL" and "ORGANISER_EMAIL".
+func (g *GraphHelper) ExpectedSubscriptionResources() []string {
+	config := g.Config()
+	var resources []string
+	for _, userId := range []string{config.RoomEmail, config.OrganiserEmail} {
+		if userId != "" {
+			resources = append(resources, eventsResource(userId))
+		}
+	}
+	return resources
+}
+
+// CheckSubscriptions lists the subscriptions and reports those expiring within the given
+// time, and each expected resource that has no subscription.
+//
+// Parameters:
+//   - expected: The resources that should have a subscription.
+//   - within: How close to expiry a subscription is reported.
+//
+// Returns:
+//   - []SubscriptionWarning: The problems found, expiring subscriptions first.
+//   - error: An error object if listing the subscriptions fails, otherwise nil.
+func (g *GraphHelper) CheckSubscriptions(expected []string, within time.Duration) ([]SubscriptionWarning, error) {
+
+	subscriptions, err := g.ListSubscriptions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions: %v", err)
+	}
+	return subscriptionWarnings(subscriptions, expected, time.Now().Add(within)), nil
+}
+
+// subscriptionWarnings finds the subscriptions expiring before deadline and the expected
+// resources without a subscription.
+func subscriptionWarnings(subscriptions []models.Subscriptionable, expected []string, deadline time.Time) []SubscriptionWarning {
+	var warnings []SubscriptionWarning
+	for _, subscription := range subscriptions {
+		if subscription.GetId() == nil || subscription.GetExpirationDateTime() == nil {
+			continue
+		}
+		if expires := *subscription.GetExpirationDateTime(); expires.Before(deadline) {
+			warnings = append(warnings, SubscriptionWarning{
+				Resource:       StringOrDefault(subscription.GetResource(), "-"),
+				SubscriptionId: *subscription.GetId(),
+				Expires:        expires,
+			})
+		}
+	}
+
+	for _, resource := range expected {
+		found := false
+		for _, subscription := range subscriptions {
+			// rich notification subscriptions carry a $select after the resource
+			if subscription.GetResource() != nil && sameResource(strings.SplitN(*subscription.GetResource(), "?", 2)[0], resource) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			warnings = append(warnings, SubscriptionWarning{Resource: resource})
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,53 @@
+package graphhelper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+func TestSubscriptionWarnings(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	subscription := func(id string, resource string, expires time.Time) models.Subscriptionable {
+		s := models.NewSubscription()
+		s.SetId(&id)
+		s.SetResource(&resource)
+		s.SetExpirationDateTime(&expires)
+		return s
+	}
+	room, organiser := eventsResource("room@example.com"), eventsResource("organiser@example.com")
+
+	tests := []struct {
+		name          string
+		subscriptions []models.Subscriptionable
+		want          []string
+	}{
+		{"healthy", []models.Subscriptionable{
+			subscription("sub-1", "Users/Room@example.com/Events", now.Add(48*time.Hour)),
+			subscription("sub-2", organiser, now.Add(72*time.Hour)),
+		}, nil},
+		{"expiring soon", []models.Subscriptionable{
+			subscription("sub-1", room, now.Add(2*time.Hour)),
+			subscription("sub-2", organiser, now.Add(72*time.Hour)),
+		}, []string{"subscription sub-1 for /users/room@example.com/events expires 2024-03-01T11:00:00Z"}},
+		{"missing", []models.Subscriptionable{
+			subscription("sub-1", room+"?$select=subject", now.Add(48*time.Hour)),
+		}, []string{"no subscription for /users/organiser@example.com/events"}},
+		{"none", nil, []string{"no subscription for /users/room@example.com/events", "no subscription for /users/organiser@example.com/events"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			warnings := subscriptionWarnings(test.subscriptions, []string{room, organiser}, now.Add(DefaultSubscriptionExpiryWarning))
+			if len(warnings) != len(test.want) {
+				t.Fatalf("warnings = %v, want %v", warnings, test.want)
+			}
+			for i, warning := range warnings {
+				if got := warning.String(); got != test.want[i] {
+					t.Errorf("warning %d = %q, want %q", i, got, test.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package graphhelper
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// ansiRed and ansiReset colour a line red on terminals that support it.
+const (
+	ansiRed   = "\033[31m"
+	ansiReset = "\033[0m"
+)
+
+// TimeCheck compares the times an event was requested for with the times Graph stored.
+type TimeCheck struct {
+	RequestedStart time.Time
+	RequestedEnd   time.Time
+	ReturnedStart  time.Time // zero when Graph returned no start, or one in an unknown time zone
+	ReturnedEnd    time.Time
+}
+
+// CheckEventTimes reads the start and end of an event returned by Graph, whatever time zone
+// they are in, to compare them with the times it was requested for.
+func (g *GraphHelper) CheckEventTimes(event models.Eventable, start time.Time, end time.Time) TimeCheck {
+	summary := g.NewEventSummary(event)
+	return TimeCheck{RequestedStart: start, RequestedEnd: end, ReturnedStart: summary.StartUTC, ReturnedEnd: summary.EndUTC}
+}
+
+// Matches reports whether Graph stored the event at the requested times.
+func (c TimeCheck) Matches() bool {
+	return c.ReturnedStart.Equal(c.RequestedStart) && c.ReturnedEnd.Equal(c.RequestedEnd)
+}
+
+// Write prints the requested and returned times in location, flagging a mismatch, in red
+// when colour is true.
+func (c TimeCheck) Write(w io.Writer, location *time.Location, colour bool) {
+	fmt.Fprintf(w, "Requested: %s\n", formatTimeRange(c.RequestedStart, c.RequestedEnd, location))
+	returned := fmt.Sprintf("Returned:  %s", formatTimeRange(c.ReturnedStart, c.ReturnedEnd, location))
+	if c.Matches() {
+		fmt.Fprintln(w, returned)
+		return
+	}
+	returned += "  <- differs from the requested times"
+	if colour {
+		returned = ansiRed + returned + ansiReset
+	}
+	fmt.Fprintln(w, returned)
+}
+
+// formatTimeRange shows a start and end in location, "-" for a time that is not known.
+func formatTimeRange(start time.Time, end time.Time, location *time.Location) string {
+	startText, endText := "-", "-"
+	if !start.IsZero() {
+		startText = start.In(location).Format(DisplayLayout)
+	}
+	if !end.IsZero() {
+		endText = end.In(location).Format(DisplayLayout)
+		if !start.IsZero() && sameDay(start.In(location), end.In(location)) {
+			endText = end.In(location).Format(TimeOfDayLayout)
+		}
+	}
+	return startText + " - " + endText
+}
+
+// sameDay reports whether two times fall on the same calendar date.
+func sameDay(a time.Time, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
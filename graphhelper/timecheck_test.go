@@ -0,0 +1,61 @@
+package graphhelper
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+func TestCheckEventTimes(t *testing.T) {
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	end := start.Add(30 * time.Minute)
+	returned := func(startValue string, endValue string, zone string) models.Eventable {
+		event := models.NewEvent()
+		event.SetStart(models.NewDateTimeTimeZone())
+		event.GetStart().SetDateTime(&startValue)
+		event.GetStart().SetTimeZone(&zone)
+		event.SetEnd(models.NewDateTimeTimeZone())
+		event.GetEnd().SetDateTime(&endValue)
+		event.GetEnd().SetTimeZone(&zone)
+		return event
+	}
+
+	tests := []struct {
+		name  string
+		event models.Eventable
+		want  string
+	}{
+		{"same times in UTC", returned("2024-03-01T09:00:00.0000000", "2024-03-01T09:30:00.0000000", "UTC"),
+			"Requested: Fri 01 Mar 09:00 - 09:30\nReturned:  Fri 01 Mar 09:00 - 09:30\n"},
+		{"same times in a Windows zone", returned("2024-03-01T01:00:00.0000000", "2024-03-01T01:30:00.0000000", "Pacific Standard Time"),
+			"Requested: Fri 01 Mar 09:00 - 09:30\nReturned:  Fri 01 Mar 09:00 - 09:30\n"},
+		{"shifted by the zone", returned("2024-03-01T09:00:00.0000000", "2024-03-01T09:30:00.0000000", "Pacific Standard Time"),
+			"Requested: Fri 01 Mar 09:00 - 09:30\n" + ansiRed + "Returned:  Fri 01 Mar 17:00 - 17:30  <- differs from the requested times" + ansiReset + "\n"},
+		{"no times", models.NewEvent(),
+			"Requested: Fri 01 Mar 09:00 - 09:30\n" + ansiRed + "Returned:  - - -  <- differs from the requested times" + ansiReset + "\n"},
+	}
+
+	g := &GraphHelper{}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var out bytes.Buffer
+			g.CheckEventTimes(test.event, start, end).Write(&out, time.UTC, true)
+			if out.String() != test.want {
+				t.Errorf("Write() = %q, want %q", out.String(), test.want)
+			}
+		})
+	}
+}
+
+func TestTimeCheckWriteWithoutColour(t *testing.T) {
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	check := TimeCheck{RequestedStart: start, RequestedEnd: start.Add(time.Hour), ReturnedStart: start.Add(time.Hour), ReturnedEnd: start.Add(26 * time.Hour)}
+
+	var out bytes.Buffer
+	check.Write(&out, time.UTC, false)
+	if want := "Requested: Fri 01 Mar 09:00 - 10:00\nReturned:  Fri 01 Mar 10:00 - Sat 02 Mar 11:00  <- differs from the requested times\n"; out.String() != want {
+		t.Errorf("Write() = %q, want %q", out.String(), want)
+	}
+}
@@ -0,0 +1,61 @@
+package graphhelper
+
+import "time"
+
+// Time layouts used when talking to Graph and to the user.
+const (
+	// graphDateTimeLayout is the dateTime of a Graph dateTimeTimeZone: no offset, as the zone is
+	// sent alongside, with up to seven fractional digits in responses.
+	graphDateTimeLayout = "2006-01-02T15:04:05.999999999"
+
+	// graphQueryLayout is used for query parameters such as the calendarView window, which carry an offset.
+	graphQueryLayout = time.RFC3339
+
+	// InputLayout is how dates and times are typed at the prompts, in the configured time zone.
+	InputLayout = "2006-01-02T15:04"
+
+	// DisplayLayout is how a date and time is shown in short listings.
+	DisplayLayout = "Mon 02 Jan 15:04"
+
+	// TimeOfDayLayout is how a time is shown when the date is already clear.
+	TimeOfDayLayout = "15:04"
+
+	// DateLayout is how the date of an all day event is shown.
+	DateLayout = "Mon 02 Jan"
+)
+
+// formatForGraph formats t as the UTC dateTime of a Graph dateTimeTimeZone.
+func formatForGraph(t time.Time) string {
+	return t.UTC().Format(graphDateTimeLayout)
+}
+
+// parseFromGraph parses the dateTime of a Graph dateTimeTimeZone returned in UTC.
+func parseFromGraph(dateTime string) (time.Time, error) {
+	return time.Parse(graphDateTimeLayout, dateTime)
+}
+
+// allDayDate reads the date of an all day event's start or end as midnight in location. All day
+// events cover the same calendar dates in every time zone, so the time and zone Graph sends
+// alongside the date are ignored rather than converted.
+func allDayDate(dateTime string, location *time.Location) (time.Time, error) {
+	t, err := parseFromGraph(dateTime)
+	if err != nil {
+		return time.Time{}, err
+	}
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, location), nil
+}
+
+// formatAllDay shows the dates an all day event covers. Its end is the midnight after its last day.
+func formatAllDay(start time.Time, end time.Time) string {
+	last := end.AddDate(0, 0, -1)
+	if end.IsZero() || !last.After(start) {
+		return start.Format(DateLayout) + ", all day"
+	}
+	return start.Format(DateLayout) + " to " + last.Format(DateLayout) + ", all day"
+}
+
+// formatQueryTime formats t for a Graph query parameter.
+func formatQueryTime(t time.Time) string {
+	return t.Format(graphQueryLayout)
+}
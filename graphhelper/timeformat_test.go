@@ -0,0 +1,60 @@
+package graphhelper
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatForGraph(t *testing.T) {
+	melbourne := time.FixedZone("AEDT", 11*60*60)
+	at := time.Date(2024, 3, 1, 20, 30, 0, 0, melbourne)
+
+	if got, want := formatForGraph(at), "2024-03-01T09:30:00"; got != want {
+		t.Errorf("formatForGraph() = %q, want %q", got, want)
+	}
+	if got, want := formatQueryTime(at), "2024-03-01T20:30:00+11:00"; got != want {
+		t.Errorf("formatQueryTime() = %q, want %q", got, want)
+	}
+}
+
+func TestParseFromGraph(t *testing.T) {
+	want := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	for _, dateTime := range []string{
+		"2024-03-01T09:30:00",
+		"2024-03-01T09:30:00.0000000",
+	} {
+		got, err := parseFromGraph(dateTime)
+		if err != nil {
+			t.Errorf("parseFromGraph(%q) returned %v", dateTime, err)
+			continue
+		}
+		if !got.Equal(want) || got.Location() != time.UTC {
+			t.Errorf("parseFromGraph(%q) = %v, want %v", dateTime, got, want)
+		}
+	}
+
+	if _, err := parseFromGraph("2024-03-01T09:30:00Z"); err == nil {
+		t.Error("parseFromGraph accepted a dateTime with an offset")
+	}
+}
+
+func TestFormatForGraphRoundTrips(t *testing.T) {
+	at := time.Date(2024, 12, 31, 23, 59, 59, 0, time.UTC)
+	got, err := parseFromGraph(formatForGraph(at))
+	if err != nil || !got.Equal(at) {
+		t.Errorf("round trip = %v, %v; want %v", got, err, at)
+	}
+}
+
+func TestUserLayouts(t *testing.T) {
+	at := time.Date(2024, 3, 1, 9, 5, 0, 0, time.UTC)
+	if got, want := at.Format(InputLayout), "2024-03-01T09:05"; got != want {
+		t.Errorf("InputLayout = %q, want %q", got, want)
+	}
+	if got, want := at.Format(DisplayLayout), "Fri 01 Mar 09:05"; got != want {
+		t.Errorf("DisplayLayout = %q, want %q", got, want)
+	}
+	if got, want := at.Format(TimeOfDayLayout), "09:05"; got != want {
+		t.Errorf("TimeOfDayLayout = %q, want %q", got, want)
+	}
+}
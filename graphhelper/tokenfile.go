@@ -0,0 +1,67 @@
+package graphhelper
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+)
+
+// tokenFile is what WriteAppToken writes, shaped like an OAuth token response so scripts can
+// read it with, for example, jq -r .access_token.
+type tokenFile struct {
+	TokenType   string    `json:"token_type"`
+	AccessToken string    `json:"access_token"`
+	ExpiresOn   time.Time `json:"expires_on"`
+}
+
+// WriteAppToken writes the app-only access token and its expiry to a file only the current
+// user can read, for reuse by other tools such as curl. The token grants every application
+// permission of the app registration until it expires, so the file must be kept private.
+//
+// Parameters:
+//   - path: The file to write. An existing file is replaced.
+//
+// Returns:
+//   - time.Time: When the written token expires.
+//   - error: An error object if the token cannot be obtained or written, otherwise nil.
+func (g *GraphHelper) WriteAppToken(path string) (time.Time, error) {
+	token, err := g.appToken()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get app token: %v", err)
+	}
+	if err := writeTokenFile(path, token); err != nil {
+		return time.Time{}, err
+	}
+	return token.ExpiresOn, nil
+}
+
+// writeTokenFile writes the token to a private temporary file beside path, then renames it into
+// place, so the token is never readable by others nor left half written.
+func writeTokenFile(path string, token azcore.AccessToken) error {
+	content, err := json.MarshalIndent(tokenFile{TokenType: "Bearer", AccessToken: token.Token, ExpiresOn: token.ExpiresOn}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode token: %v", err)
+	}
+
+	// CreateTemp creates the file with 0600 permissions
+	file, err := os.CreateTemp(filepath.Dir(path), ".token-*")
+	if err != nil {
+		return fmt.Errorf("failed to write token file: %v", err)
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(append(content, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write token file: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write token file: %v", err)
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("failed to write token file: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package graphhelper
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+)
+
+func TestWriteTokenFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token.json")
+	// an older, readable file is replaced
+	if err := os.WriteFile(path, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	expires := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	if err := writeTokenFile(path, azcore.AccessToken{Token: "eyJ0eXAi", ExpiresOn: expires}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var written tokenFile
+	if err := json.Unmarshal(content, &written); err != nil {
+		t.Fatalf("token file is not JSON: %v", err)
+	}
+	if written.TokenType != "Bearer" || written.AccessToken != "eyJ0eXAi" || !written.ExpiresOn.Equal(expires) {
+		t.Errorf("token file = %+v", written)
+	}
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mode := info.Mode().Perm(); mode != 0600 {
+			t.Errorf("token file mode = %v, want 0600", mode)
+		}
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+		t.Errorf("%d files left in the directory, want only the token file", len(entries))
+	}
+}
+
+func TestWriteAppTokenWithoutCredential(t *testing.T) {
+	g := NewGraphHelper(&Config{})
+	path := filepath.Join(t.TempDir(), "token.json")
+
+	if _, err := g.WriteAppToken(path); err == nil {
+		t.Error("WriteAppToken() succeeded without a credential")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("token file written without a token: %v", err)
+	}
+}
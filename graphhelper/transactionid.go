@@ -0,0 +1,28 @@
+package graphhelper
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// TransactionWindow is how long creating the same booking again is treated as a repeat of the
+// first request. The window is fixed, not sliding: a repeat that crosses into the next window
+// gets a new transaction id.
+const TransactionWindow = 10 * time.Minute
+
+// eventTransactionId derives the transactionId of a booking from the room, start and subject,
+// and the TransactionWindow it is made in. Graph creates one event per transactionId, so the
+// same booking sent twice within the window, such as a menu option chosen twice, is only
+// created once.
+func eventTransactionId(roomEmail string, start time.Time, subject string, now time.Time) string {
+	key := strings.Join([]string{
+		strings.ToLower(roomEmail),
+		start.UTC().Format(time.RFC3339),
+		subject,
+		now.UTC().Truncate(TransactionWindow).Format(time.RFC3339),
+	}, "\n")
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:16])
+}
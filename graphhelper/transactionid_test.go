@@ -0,0 +1,39 @@
+package graphhelper
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEventTransactionId(t *testing.T) {
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	now := time.Date(2024, 2, 28, 10, 1, 0, 0, time.UTC)
+	first := eventTransactionId("room@example.com", start, "Room booking", now)
+
+	tests := []struct {
+		name     string
+		room     string
+		start    time.Time
+		subject  string
+		now      time.Time
+		wantSame bool
+	}{
+		{"repeated in the window", "Room@Example.com", start.In(time.FixedZone("AEDT", 11*60*60)), "Room booking", now.Add(5 * time.Minute), true},
+		{"next window", "room@example.com", start, "Room booking", now.Add(TransactionWindow), false},
+		{"other room", "other@example.com", start, "Room booking", now, false},
+		{"other start", "room@example.com", start.Add(30 * time.Minute), "Room booking", now, false},
+		{"other subject", "room@example.com", start, "Stand-up", now, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := eventTransactionId(test.room, test.start, test.subject, test.now)
+			if (got == first) != test.wantSame {
+				t.Errorf("eventTransactionId() = %s, first %s, want same %t", got, first, test.wantSame)
+			}
+		})
+	}
+	if len(first) != 32 {
+		t.Errorf("eventTransactionId() = %q, want 32 hex characters", first)
+	}
+}
@@ -0,0 +1,92 @@
+package graphhelper
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+)
+
+// EventUpdate is a change to an event's subject or times. A blank subject and zero times are
+// left as they are; the start and end are changed together.
+type EventUpdate struct {
+	Subject string
+	Start   time.Time
+	End     time.Time
+}
+
+// Validate checks the update changes something and that the event would still end after it starts.
+func (u EventUpdate) Validate() error {
+	if u.Subject == "" && u.Start.IsZero() && u.End.IsZero() {
+		return fmt.Errorf("nothing to change")
+	}
+	if u.Start.IsZero() != u.End.IsZero() {
+		return fmt.Errorf("the start and end are changed together")
+	}
+	if !u.Start.IsZero() && !u.End.After(u.Start) {
+		return fmt.Errorf("the end %s is not after the start %s", u.End.Format(InputLayout), u.Start.Format(InputLayout))
+	}
+	return nil
+}
+
+// UpdateEvent changes the subject or times of an event. Only the organiser's copy can be
+// changed, Graph then sends the attendees an update.
+//
+// Parameters:
+//   - ctx: Cancels the requests.
+//   - userId: The ID or email of the event's organiser.
+//   - eventId: The ID of the event in the organiser's calendar.
+//   - update: The subject and times to set.
+//
+// Returns:
+//   - models.Eventable: The event as updated by Graph.
+//   - error: An error object if the update is not valid or the request fails, otherwise nil.
+func (g *GraphHelper) UpdateEvent(ctx context.Context, userId string, eventId string, update EventUpdate) (models.Eventable, error) {
+	client, err := g.graphClient()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateUserId("organiser", userId); err != nil {
+		return nil, err
+	}
+	if err := update.Validate(); err != nil {
+		return nil, err
+	}
+	// an email that is not the user's UPN is not accepted in the path
+	userId, err = g.mailboxId(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	item := client.Users().ByUserId(userId).Events().ByEventId(eventId)
+	event, err := item.Get(ctx, &users.ItemEventsEventItemRequestBuilderGetRequestConfiguration{
+		QueryParameters: &users.ItemEventsEventItemRequestBuilderGetQueryParameters{
+			Select: []string{"id", "isOrganizer"},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event: %v", err)
+	}
+	if event.GetIsOrganizer() != nil && !*event.GetIsOrganizer() {
+		return nil, fmt.Errorf("event %s is not organised by %s, only the organiser can change it", eventId, userId)
+	}
+
+	patch := models.NewEvent()
+	if update.Subject != "" {
+		patch.SetSubject(&update.Subject)
+	}
+	if !update.Start.IsZero() {
+		patch.SetStart(g.eventDateTimeTimeZone(update.Start))
+		patch.SetEnd(g.eventDateTimeTimeZone(update.End))
+	}
+	patchCtx, audited := g.audit(ctx, "update event", userId+"/events/"+eventId)
+	updated, err := item.Patch(patchCtx, patch, nil)
+	audited(err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update event: %v", err)
+	}
+	return updated, nil
+}
@@ -0,0 +1,32 @@
+package graphhelper
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEventUpdateValidate(t *testing.T) {
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	tests := []struct {
+		name   string
+		update EventUpdate
+		valid  bool
+	}{
+		{"subject", EventUpdate{Subject: "Planning"}, true},
+		{"times", EventUpdate{Start: start, End: end}, true},
+		{"nothing", EventUpdate{}, false},
+		{"start only", EventUpdate{Start: start}, false},
+		{"end before start", EventUpdate{Start: end, End: start}, false},
+		{"no length", EventUpdate{Start: start, End: start}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.update.Validate(); (err == nil) != test.valid {
+				t.Errorf("Validate() = %v, want valid %t", err, test.valid)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package graphhelper
+
+import "testing"
+
+func TestUserFilterQuery(t *testing.T) {
+	disabled, enabled := false, true
+	tests := []struct {
+		name          string
+		filter        UserFilter
+		resourcesOnly bool
+		want          string
+		description   string
+	}{
+		{"none", UserFilter{}, false, "", "users"},
+		{"disabled", UserFilter{AccountEnabled: &disabled}, false, "accountEnabled eq false", "disabled users"},
+		{"department", UserFilter{Department: "Sales"}, false, "department eq 'Sales'", "users in Sales"},
+		{"quoted", UserFilter{JobTitle: "Director' or true eq true or jobTitle eq '"}, false,
+			"jobTitle eq 'Director'' or true eq true or jobTitle eq '''", "users with job title Director' or true eq true or jobTitle eq '"},
+		{"every condition", UserFilter{AccountEnabled: &enabled, Department: "Facilities", JobTitle: "Manager"}, true,
+			"isResourceAccount eq true and accountEnabled eq true and department eq 'Facilities' and jobTitle eq 'Manager'",
+			"enabled users in Facilities with job title Manager"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.filter.query(test.resourcesOnly); got != test.want {
+				t.Errorf("query() = %q, want %q", got, test.want)
+			}
+			if got := test.filter.String(); got != test.description {
+				t.Errorf("String() = %q, want %q", got, test.description)
+			}
+		})
+	}
+}
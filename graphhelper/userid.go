@@ -0,0 +1,95 @@
+package graphhelper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models/odataerrors"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+)
+
+// ErrUserNotFound is returned by ResolveUserId when no user has the email.
+var ErrUserNotFound = errors.New("no user has this email")
+
+// ErrUserLookupForbidden is returned by ResolveUserId when the app may not read users, as it
+// holds neither User.Read.All nor Directory.Read.All.
+var ErrUserLookupForbidden = errors.New("the app may not look up users")
+
+// ResolveUserId returns the object id of the user or room with the given email, looked up by
+// their mail or user principal name, so a mailbox whose mail differs from its UPN is still found.
+// An object id is returned as it is. Ids are cached, and served from the cache while it is fresh.
+//
+// Parameters:
+//   - ctx: The context of the request.
+//   - email: The email of the user or room, or their object id.
+//
+// Returns:
+//   - string: The object id.
+//   - error: ErrUserNotFound if no user has the email, ErrUserLookupForbidden if Graph answers 403,
+//     another error object if the request fails or more than one user has it, otherwise nil.
+func (g *GraphHelper) ResolveUserId(ctx context.Context, email string) (string, error) {
+
+	if objectIdPattern.MatchString(email) {
+		return email, nil
+	}
+	if err := validateEmail("user", email); err != nil {
+		return "", err
+	}
+	key := strings.ToLower(email)
+	if id, ok := g.cache.getUserId(key); ok {
+		return id, nil
+	}
+	client, err := g.graphClient()
+	if err != nil {
+		return "", err
+	}
+
+	quoted := strings.ReplaceAll(email, "'", "''")
+	filter := fmt.Sprintf("mail eq '%s' or userPrincipalName eq '%s'", quoted, quoted)
+	result, err := client.Users().Get(ctx, &users.UsersRequestBuilderGetRequestConfiguration{
+		QueryParameters: &users.UsersRequestBuilderGetQueryParameters{
+			Select: []string{"id"},
+			Filter: &filter,
+		},
+	})
+	if err != nil {
+		var odataError *odataerrors.ODataError
+		if errors.As(err, &odataError) && odataError.GetStatusCode() == http.StatusForbidden {
+			return "", fmt.Errorf("%s: %w", email, ErrUserLookupForbidden)
+		}
+		return "", fmt.Errorf("failed to look up %s: %v", email, err)
+	}
+
+	found := result.GetValue()
+	switch {
+	case len(found) == 0:
+		return "", fmt.Errorf("%s: %w", email, ErrUserNotFound)
+	case len(found) > 1:
+		return "", fmt.Errorf("%d users have the email %s", len(found), email)
+	}
+	id := StringOrDefault(found[0].GetId(), "")
+	if id == "" {
+		return "", fmt.Errorf("Graph returned %s without an id", email)
+	}
+	g.cache.setUserId(key, id)
+	return id, nil
+}
+
+// mailboxId returns the id to put in a /users/{id} path for a mailbox: its object id, so an email
+// that is not the mailbox's UPN still works. When the app may not look up users, the email is used
+// as it is, which works for a UPN with only the Calendars permissions.
+func (g *GraphHelper) mailboxId(ctx context.Context, email string) (string, error) {
+	id, err := g.ResolveUserId(ctx, email)
+	return mailboxIdOf(email, id, err)
+}
+
+// mailboxIdOf returns the id of a mailbox from the outcome of looking up its email.
+func mailboxIdOf(email string, id string, err error) (string, error) {
+	if errors.Is(err, ErrUserLookupForbidden) {
+		return email, nil
+	}
+	return id, err
+}
@@ -0,0 +1,55 @@
+package graphhelper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResolveUserId(t *testing.T) {
+	g := NewGraphHelper(&Config{CacheTTL: DefaultCacheTTL})
+	clock := &testClock{now: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)}
+	g.cache.now = clock.Now
+	id := "5f3e1c4a-0b1d-4c2e-9a7f-123456789abc"
+	g.cache.setUserId("room@example.com", id)
+
+	// served without a Graph client
+	got, err := g.ResolveUserId(context.Background(), "Room@Example.com")
+	if err != nil || got != id {
+		t.Errorf("ResolveUserId() = %q, %v; want the cached id %q", got, err, id)
+	}
+
+	// an object id needs no lookup
+	if got, err := g.ResolveUserId(context.Background(), id); err != nil || got != id {
+		t.Errorf("ResolveUserId(id) = %q, %v; want it returned as it is", got, err)
+	}
+
+	// once expired, the next call goes to Graph, which has no client here
+	clock.advance(DefaultCacheTTL)
+	if _, err := g.ResolveUserId(context.Background(), "room@example.com"); err == nil || !strings.Contains(err.Error(), "not initialized") {
+		t.Errorf("ResolveUserId() after expiry = %v, want it to ask Graph", err)
+	}
+	if _, err := g.ResolveUserId(context.Background(), "someone else@example.com"); err == nil || !strings.Contains(err.Error(), "not a valid email") {
+		t.Errorf("ResolveUserId() = %v, want an invalid email error", err)
+	}
+}
+
+func TestMailboxIdOf(t *testing.T) {
+	email, id := "room@example.com", "5f3e1c4a-0b1d-4c2e-9a7f-123456789abc"
+
+	if got, err := mailboxIdOf(email, id, nil); err != nil || got != id {
+		t.Errorf("mailboxIdOf() = %q, %v; want the object id", got, err)
+	}
+	// without User.Read.All the email is used in the path as it is
+	forbidden := fmt.Errorf("%s: %w", email, ErrUserLookupForbidden)
+	if got, err := mailboxIdOf(email, "", forbidden); err != nil || got != email {
+		t.Errorf("mailboxIdOf() after a 403 = %q, %v; want the email", got, err)
+	}
+	notFound := fmt.Errorf("%s: %w", email, ErrUserNotFound)
+	if _, err := mailboxIdOf(email, "", notFound); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("mailboxIdOf() = %v, want ErrUserNotFound", err)
+	}
+}
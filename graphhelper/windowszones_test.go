@@ -0,0 +1,82 @@
+package graphhelper
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWindowsZonesLoad(t *testing.T) {
+	for windows, iana := range windowsZones {
+		if _, err := time.LoadLocation(iana); err != nil {
+			t.Errorf("%s maps to %s, which does not load: %v", windows, iana, err)
+		}
+	}
+	for iana, windows := range ianaAliases {
+		if _, ok := windowsZones[windows]; !ok {
+			t.Errorf("%s maps to %q, which is not a Windows time zone", iana, windows)
+		}
+	}
+}
+
+func TestIANAToWindows(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "America/Los_Angeles", want: "Pacific Standard Time"},
+		{name: "Australia/Melbourne", want: "AUS Eastern Standard Time"},
+		{name: "UTC", want: "UTC"},
+		{name: "Pacific Standard Time", want: "Pacific Standard Time"},
+		{name: "Middle/Earth", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ianaToWindows(test.name)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("ianaToWindows(%q) = %q, want an error", test.name, got)
+				}
+				return
+			}
+			if err != nil || got != test.want {
+				t.Errorf("ianaToWindows(%q) = %q, %v, want %q", test.name, got, err, test.want)
+			}
+		})
+	}
+}
+
+func TestLoadTimeZone(t *testing.T) {
+	for name, want := range map[string]string{
+		"Australia/Melbourne":       "Australia/Melbourne",
+		"AUS Eastern Standard Time": "Australia/Sydney",
+		"Pacific Standard Time":     "America/Los_Angeles",
+	} {
+		location, err := LoadTimeZone(name)
+		if err != nil || location.String() != want {
+			t.Errorf("LoadTimeZone(%q) = %v, %v, want %s", name, location, err, want)
+		}
+	}
+	if _, err := LoadTimeZone("Middle Earth Standard Time"); err == nil {
+		t.Error("LoadTimeZone() of an unknown zone succeeded")
+	}
+}
+
+func TestEventDateTimeTimeZone(t *testing.T) {
+	melbourne, _ := time.LoadLocation("Australia/Melbourne")
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, melbourne)
+
+	g := NewGraphHelper(&Config{TimeZone: melbourne})
+	value := g.eventDateTimeTimeZone(start)
+	if *value.GetDateTime() != "2024-03-01T09:00:00" || *value.GetTimeZone() != "AUS Eastern Standard Time" {
+		t.Errorf("eventDateTimeTimeZone() = %s in %s", *value.GetDateTime(), *value.GetTimeZone())
+	}
+
+	// the system zone has no name, so the time is sent in UTC
+	g = NewGraphHelper(&Config{TimeZone: time.Local})
+	value = g.eventDateTimeTimeZone(start)
+	if *value.GetDateTime() != "2024-02-29T22:00:00" || *value.GetTimeZone() != "UTC" {
+		t.Errorf("eventDateTimeTimeZone() = %s in %s, want UTC", *value.GetDateTime(), *value.GetTimeZone())
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// readLine reads a whole line from stdin, spaces included, and returns it without the line ending.
+// It reads a byte at a time so nothing is buffered away from the fmt.Scanf calls used elsewhere.
+func readLine() (string, error) {
+	return readLineFrom(os.Stdin)
+}
+
+func readLineFrom(r io.Reader) (string, error) {
+	var line strings.Builder
+	var b [1]byte
+	for {
+		n, err := r.Read(b[:])
+		if n == 1 {
+			if b[0] == '\n' {
+				break
+			}
+			line.WriteByte(b[0])
+		}
+		if err == io.EOF && line.Len() > 0 {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return strings.TrimSuffix(line.String(), "\r"), nil
+}
+
+// readSecretFrom reads a secret such as a password without echoing it when r is a terminal,
+// ending the line it was typed on, and reads it as any other line otherwise.
+func readSecretFrom(r io.Reader, w io.Writer) (string, error) {
+	if f, ok := r.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		secret, err := term.ReadPassword(int(f.Fd()))
+		fmt.Fprintln(w)
+		return string(secret), err
+	}
+	return readLineFrom(r)
+}
+
+// promptInput prints label, reads a line and passes it, trimmed, to onDone. A blank answer
+// cancels, and onDone is not called. Reading the whole line leaves nothing behind for the
+// next menu choice, unlike a fmt.Scanf of a single word.
+func promptInput(label string, onDone func(string)) {
+	promptInputFrom(os.Stdin, os.Stdout, label, onDone)
+}
+
+func promptInputFrom(r io.Reader, w io.Writer, label string, onDone func(string)) {
+	fmt.Fprintln(w, label)
+	value, err := readLineFrom(r)
+	if err != nil {
+		log.Printf("Error reading input: %v", err)
+		return
+	}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		fmt.Fprintln(w, "Cancelled")
+		return
+	}
+	onDone(value)
+}
+
+// confirm asks a yes or no question, returning true only for "y".
+func confirm(question string) bool {
+	return confirmFrom(os.Stdin, os.Stdout, question)
+}
+
+func confirmFrom(r io.Reader, w io.Writer, question string) bool {
+	fmt.Fprintf(w, "%s (y/N):\n", question)
+	answer, err := readLineFrom(r)
+	if err != nil {
+		log.Printf("Error reading answer: %v", err)
+		return false
+	}
+	return strings.EqualFold(strings.TrimSpace(answer), "y")
+}
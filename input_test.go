@@ -0,0 +1,75 @@
+package main
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestReadLineFrom(t *testing.T) {
+	r := strings.NewReader("weekly sync\r\n\nlast line")
+
+	for _, want := range []string{"weekly sync", "", "last line"} {
+		got, err := readLineFrom(r)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != want {
+			t.Errorf("readLineFrom() = %q, want %q", got, want)
+		}
+	}
+	if _, err := readLineFrom(r); err != io.EOF {
+		t.Errorf("error at end of input = %v, want io.EOF", err)
+	}
+}
+
+func TestPromptInputFrom(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		want   string
+		called bool
+	}{
+		{name: "value", input: "  AAMkAGI2 \n", want: "AAMkAGI2", called: true},
+		{name: "blank cancels", input: "   \n", called: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := strings.NewReader(test.input + "next choice\n")
+			var out strings.Builder
+			called := false
+			promptInputFrom(r, &out, "Enter the event id:", func(value string) {
+				called = true
+				if value != test.want {
+					t.Errorf("onDone(%q), want %q", value, test.want)
+				}
+			})
+			if called != test.called {
+				t.Errorf("onDone called = %t, want %t", called, test.called)
+			}
+			// the rest of the input is left for the next prompt
+			if rest, _ := readLineFrom(r); rest != "next choice" {
+				t.Errorf("next line = %q, want %q", rest, "next choice")
+			}
+		})
+	}
+}
+
+func TestPromptInputFromNoInput(t *testing.T) {
+	var out strings.Builder
+	promptInputFrom(strings.NewReader(""), &out, "Enter the event id:", func(value string) {
+		t.Errorf("onDone(%q) called at end of input", value)
+	})
+}
+
+func TestConfirmFrom(t *testing.T) {
+	for input, want := range map[string]bool{"y\n": true, " Y \n": true, "n\n": false, "\n": false, "yes\n": false, "": false} {
+		var out strings.Builder
+		if got := confirmFrom(strings.NewReader(input), &out, "Delete?"); got != want {
+			t.Errorf("confirmFrom(%q) = %t, want %t", input, got, want)
+		}
+		if out.String() != "Delete? (y/N):\n" {
+			t.Errorf("prompt = %q", out.String())
+		}
+	}
+}
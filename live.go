@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bovinemagnet/msgraph-cli/graphhelper"
+)
+
+// refreshDebounce is how long notifications must be quiet before the bookings are re-fetched,
+// so a burst of changes causes a single refresh.
+const refreshDebounce = 2 * time.Second
+
+// liveBookings remembers which calendar was last listed and re-lists it when a
+// webhook notification arrives for it.
+type liveBookings struct {
+	mu             sync.Mutex
+	graphHelper    *graphhelper.GraphHelper
+	console        *console
+	userId         string
+	subscriptionId string
+	timer          *time.Timer
+}
+
+func newLiveBookings(graphHelper *graphhelper.GraphHelper, console *console) *liveBookings {
+	return &liveBookings{graphHelper: graphHelper, console: console}
+}
+
+// watch makes the given room or user the one refreshed on notifications.
+func (l *liveBookings) watch(userId string) {
+	// Notifications name the mailbox by its object id, so match on the subscription instead
+	subscriptionId := ""
+	subscription, err := l.graphHelper.FindEventsSubscription(userId)
+	if err != nil {
+		log.Printf("Live refresh: could not look up subscription for %s: %v", userId, err)
+	} else if subscription != nil && subscription.GetId() != nil {
+		subscriptionId = *subscription.GetId()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.userId = userId
+	l.subscriptionId = subscriptionId
+}
+
+// notify inspects a notification body and schedules a refresh if it concerns the watched calendar.
+func (l *liveBookings) notify(body []byte) {
+	var notification struct {
+		Value []struct {
+			SubscriptionId string `json:"subscriptionId"`
+			Resource       string `json:"resource"`
+		} `json:"value"`
+	}
+	if err := json.Unmarshal(body, &notification); err != nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.userId == "" {
+		return
+	}
+
+	for _, value := range notification.Value {
+		matches := (l.subscriptionId != "" && value.SubscriptionId == l.subscriptionId) ||
+			strings.Contains(strings.ToLower(value.Resource), strings.ToLower(l.userId))
+		if !matches {
+			continue
+		}
+		if l.timer != nil {
+			l.timer.Stop()
+		}
+		userId := l.userId
+		l.timer = time.AfterFunc(refreshDebounce, func() {
+			l.refresh(userId)
+		})
+		return
+	}
+}
+
+// refresh re-lists the bookings once the menu is not in the middle of an action.
+func (l *liveBookings) refresh(userId string) {
+	l.console.do(func() {
+		fmt.Printf("\n\nBookings changed for %s, refreshing:\n", userId)
+		l.graphHelper.PrintRoom7DaysBookings(os.Stdout, userId)
+		fmt.Print(":> ")
+	})
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"log"
+	"strings"
+
+	"github.com/bovinemagnet/msgraph-cli/graphhelper"
+)
+
+// Permission sets the menu options need. Holding any one permission of a set is enough,
+// a ReadWrite permission includes its Read permission.
+var (
+	readUsers    = []string{"User.Read.All", "User.ReadWrite.All", "Directory.Read.All", "Directory.ReadWrite.All"}
+	readPlaces   = []string{"Place.Read.All", "Place.ReadWrite.All"}
+	writePlaces  = []string{"Place.ReadWrite.All"}
+	readEvents   = []string{"Calendars.Read", "Calendars.ReadWrite"}
+	writeEvents  = []string{"Calendars.ReadWrite"}
+	readSettings = []string{"MailboxSettings.Read", "MailboxSettings.ReadWrite"}
+)
+
+// menuRequirements are the application permissions each menu option needs. Options that
+// are not listed need none beyond signing in, or only work locally.
+var menuRequirements = map[int64][]string{
+	2:  readUsers,
+	4:  readPlaces,
+	5:  readEvents,
+	6:  readEvents,
+	7:  readEvents,
+	9:  writeEvents,
+	10: writeEvents,
+	12: writeEvents,
+	14: readPlaces,
+	17: readPlaces,
+	18: writeEvents,
+	19: readEvents,
+	21: readSettings,
+	22: readEvents,
+	25: readEvents,
+	26: readPlaces,
+	27: readPlaces,
+	29: writeEvents,
+	32: readEvents,
+	33: writeEvents,
+	35: readPlaces,
+	36: readEvents,
+	39: readEvents,
+	40: readEvents,
+	41: readEvents,
+	42: readEvents,
+	43: readUsers,
+	46: readEvents,
+	48: readPlaces,
+	50: readEvents,
+	52: readUsers,
+	53: readEvents,
+	54: writePlaces,
+	57: readUsers,
+	58: readUsers,
+	59: readUsers,
+}
+
+// menuPermissions knows which application permissions the app has, so the menu can mark the
+// options that would only fail. Until they are known every option is shown as available.
+type menuPermissions struct {
+	granted map[string]bool
+}
+
+// load reads the granted permissions from the roles in the app's access token, which needs
+// no directory permission. If the token cannot be read the menu is left unmarked.
+func (p *menuPermissions) load(graphHelper *graphhelper.GraphHelper) {
+	p.granted = nil
+	token, err := graphHelper.GetAppToken()
+	if err != nil {
+		return
+	}
+	roles, err := graphhelper.TokenRoles(*token)
+	if err != nil {
+		log.Printf("Error reading app token, menu options are not checked against its permissions: %v", err)
+		return
+	}
+	p.set(roles)
+}
+
+// set records the granted permissions.
+func (p *menuPermissions) set(roles []string) {
+	p.granted = map[string]bool{}
+	for _, role := range roles {
+		p.granted[role] = true
+	}
+}
+
+// missing returns the permissions a menu option needs, one of which must be granted,
+// or nil when the option is available or the permissions are not known.
+func (p *menuPermissions) missing(choice int64) []string {
+	if p == nil || p.granted == nil {
+		return nil
+	}
+	required := menuRequirements[choice]
+	for _, permission := range required {
+		if p.granted[permission] {
+			return nil
+		}
+	}
+	return required
+}
+
+// note returns the text appended to an unavailable menu option, or "" for an available one.
+func (p *menuPermissions) note(choice int64) string {
+	missing := p.missing(choice)
+	if missing == nil {
+		return ""
+	}
+	return " (unavailable, needs " + strings.Join(missing, " or ") + ")"
+}
@@ -0,0 +1,35 @@
+package main
+
+import "testing"
+
+func TestMenuPermissionsMissing(t *testing.T) {
+	tests := []struct {
+		name    string
+		granted []string
+		choice  int64
+		want    string
+	}{
+		{name: "read granted", granted: []string{"Calendars.Read"}, choice: 5, want: ""},
+		{name: "read write covers read", granted: []string{"Calendars.ReadWrite"}, choice: 5, want: ""},
+		{name: "read does not cover write", granted: []string{"Calendars.Read"}, choice: 12, want: " (unavailable, needs Calendars.ReadWrite)"},
+		{name: "none granted", granted: []string{}, choice: 4, want: " (unavailable, needs Place.Read.All or Place.ReadWrite.All)"},
+		{name: "option needs nothing", granted: []string{}, choice: 13, want: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var permissions menuPermissions
+			permissions.set(test.granted)
+			if got := permissions.note(test.choice); got != test.want {
+				t.Errorf("note(%d) = %q, want %q", test.choice, got, test.want)
+			}
+		})
+	}
+}
+
+func TestMenuPermissionsUnknown(t *testing.T) {
+	var permissions menuPermissions
+	if missing := permissions.missing(12); missing != nil {
+		t.Errorf("missing(12) = %v before the permissions are loaded, want nil", missing)
+	}
+}
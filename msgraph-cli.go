@@ -1,325 +1,2593 @@
 package main
 
 import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/bovinemagnet/msgraph-cli/graphhelper"
-	"github.com/joho/godotenv"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
 )
 
 func main() {
-	fmt.Println("Go MS Graph App-Only Simple CLI Tool")
+	if len(os.Args) > 1 && (os.Args[1] == "--version" || os.Args[1] == "-version") {
+		showVersion()
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "setup" {
+		// prompt for the settings and write them to .env
+		if err := setup(); err != nil {
+			log.Fatalf("Setup: %v", err)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "env-diff" {
+		// show which settings .env.local overrides
+		if err := envDiffCommand(os.Stdout); err != nil {
+			log.Fatalf("Env diff: %v", err)
+		}
+		return
+	}
+
+	fmt.Println(readBuildDetails())
 	fmt.Println()
 
 	// Load .env files
 	// .env.local takes precedence (if present)
-	godotenv.Load(".env.local")
-	err := godotenv.Load()
+	values, err := readEnvFiles()
+	if err != nil {
+		log.Fatalf("Error loading .env: %v", err)
+	}
+	if len(values) == 0 {
+		log.Println("No .env or .env.local found, using the environment only")
+	}
+	for key, value := range values {
+		if fromEnvFiles(key) {
+			os.Setenv(key, value)
+			envFileKeys[key] = true
+		}
+	}
+
+	config, err := graphhelper.LoadConfig()
+	if err != nil && len(values) == 0 {
+		log.Fatalf("Invalid configuration: %v\nRun \"%s setup\" to create a .env file", err, os.Args[0])
+	}
 	if err != nil {
-		log.Fatal("Error loading .env")
+		log.Fatalf("Invalid configuration: %v", err)
 	}
 
 	// Set up app auth
-	graphHelper := graphhelper.NewGraphHelper()
+	graphHelper := graphhelper.NewGraphHelper(config)
+	graphHelper.SetProgress(printProgress)
 
 	initializeGraph(graphHelper)
 
-	// Start up a simple the webserver for the subscription messages on the port in the .env file.
+	// Mark the menu options the app lacks the permissions for
+	permissions := &menuPermissions{}
+	permissions.load(graphHelper)
+
+	// The menu and the background goroutines take turns printing and calling Graph
+	out := newConsole()
+
+	// Re-read the .env files when the process receives SIGHUP
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
 	go func() {
-		port := graphHelper.GetPort()
-		http.HandleFunc("/webhook", handleGraphSubscription)
-		log.Println("Server starting... [port: " + port + "]")
-		if err := http.ListenAndServe(port, nil); err != nil {
-			log.Fatalf("Server error: %v", err)
+		for range hangup {
+			log.Println("SIGHUP received, reloading config")
+			out.do(func() {
+				reloadConfig(graphHelper)
+				permissions.load(graphHelper)
+			})
+		}
+	}()
+
+	// Ask for rich notifications, carrying the changed event, when a certificate is configured
+	var certificate *graphhelper.NotificationCertificate
+	if config.RichNotifications {
+		certificate, err = graphhelper.LoadNotificationCertificate(config.RichNotificationsCert, config.RichNotificationsKey, config.RichNotificationsCertId)
+		if err != nil {
+			log.Fatalf("Invalid configuration: %v", err)
 		}
+		graphHelper.SetNotificationCertificate(certificate)
+	}
+
+	// Start up a simple the webserver for the subscription messages on the port in the .env file.
+	live := newLiveBookings(graphHelper, out)
+	notifications := newNotificationLog(config.WebhookLogFile)
+	var overflow *notificationLog
+	if config.WebhookOverflow == graphhelper.WebhookOverflowPersist {
+		overflow = newNotificationLog(config.WebhookOverflowFile)
+	}
+	queue := newNotificationQueue(config.WebhookBufferSize, overflow, func(body []byte) {
+		processNotification(body, live, notifications, certificate)
+	})
+	clientState := func() string { return graphHelper.Config().WebhookClientState }
+	server := &http.Server{Addr: config.Port, Handler: newWebhookHandler(queue, clientState)}
+	go startWebhookServer(server, config.WebhookBindRetries, config.WebhookTLSCert, config.WebhookTLSKey)
+
+	// Background work stops when the tool shuts down
+	background, stopBackground := context.WithCancel(context.Background())
+	shutdown := func() {
+		stopBackground()
+		shutdownWebhookServer(server, queue)
+	}
+
+	// Shut down cleanly on Ctrl-C or SIGTERM, even while the menu waits for input
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		received := <-interrupt
+		log.Printf("%v received, shutting down", received)
+		shutdown()
+		os.Exit(0)
 	}()
 
-	// get the organiser and room email from the environment.
-	organiserEmail := graphHelper.GetOrganiserEmail()
-	if organiserEmail == "" {
-		fmt.Println("No organiser found")
+	if config.StartupSubscribe {
+		out.do(func() { subscribeOnStartup(graphHelper) })
+	}
+
+	// Keep every subscription alive in the background
+	if interval := config.SubscriptionRenewInterval; interval > 0 {
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-background.Done():
+					return
+				case <-ticker.C:
+				}
+				out.do(func() {
+					if _, err := graphHelper.RenewAllSubscriptions(graphhelper.MaxSubscriptionLifetime); err != nil {
+						log.Printf("Automatic subscription renewal: %v", err)
+					}
+				})
+			}
+		}()
 	}
 
-	roomEmail := graphHelper.GetRoomEmail()
-	if roomEmail == "" {
-		fmt.Println("No room email found")
+	// Warn about subscriptions about to expire, or missing, instead of notifications silently stopping
+	if interval := config.SubscriptionCheckInterval; interval > 0 {
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-background.Done():
+					return
+				case <-ticker.C:
+				}
+				out.do(func() { checkSubscriptions(graphHelper) })
+			}
+		}()
 	}
 
 	var choice int64 = -1
 
 	for {
-		fmt.Printf("\n\nPlease choose one of the following options:\n")
-		fmt.Println("  0.  Exit")
-		fmt.Println("  1.  Display access token")
-		fmt.Println("  +-----------------------------------+")
-		fmt.Println("  2.  List All Users")
-		fmt.Println("  3.  List All Subscriptions")
-		fmt.Println("  4.  List All Rooms")
-		fmt.Println("  5.  List 7 days of Events - By Room [" + roomEmail + "]")
-		fmt.Println("  6.  List 7 days of Events - By Organiser [" + organiserEmail + "]")
-		fmt.Println("  +-----------------------------------+")
-		fmt.Println("  7.  Create a 1 day subscription - By Room [" + roomEmail + "]")
-		fmt.Println("  8.  Delete a subscription by the subscription id")
-		fmt.Println("  +-----------------------------------+")
-		fmt.Println("  9.  Delete event id - By Room [" + roomEmail + "]")
-		fmt.Println("  10. Delete event id - By Organiser [" + organiserEmail + "]")
-		fmt.Println("  +-----------------------------------+")
-		fmt.Print(":> ")
+		out.do(func() {
+			// the active room can be changed, and the config reloaded, from the menu
+			roomEmail := graphHelper.Config().RoomEmail
+			organiserEmail := graphHelper.Config().OrganiserEmail
+
+			fmt.Printf("\n\nPlease choose one of the following options:\n")
+			fmt.Println("  0.  Exit")
+			fmt.Println("  1.  Display access token")
+			fmt.Println("  +-----------------------------------+")
+			fmt.Println("  2.  List All Users" + permissions.note(2))
+			fmt.Println("  3.  List All Subscriptions")
+			fmt.Println("  4.  List All Rooms" + permissions.note(4))
+			fmt.Println("  5.  List 7 days of Events - By Room [" + roomEmail + "]" + permissions.note(5))
+			fmt.Println("  6.  List 7 days of Events - By Organiser [" + organiserEmail + "]" + permissions.note(6))
+			fmt.Println("  +-----------------------------------+")
+			fmt.Println("  7.  Create a 1 day subscription - By Room [" + roomEmail + "]" + permissions.note(7))
+			fmt.Println("  8.  Delete a subscription by the subscription id")
+			fmt.Println("  +-----------------------------------+")
+			fmt.Println("  9.  Delete event id - By Room [" + roomEmail + "]" + permissions.note(9))
+			fmt.Println("  10. Delete event id - By Organiser [" + organiserEmail + "]" + permissions.note(10))
+			fmt.Println("  +-----------------------------------+")
+			fmt.Println("  11. Refresh Cache")
+			fmt.Println("  +-----------------------------------+")
+			fmt.Println("  12. Create an event - By Organiser [" + organiserEmail + "] in Room [" + roomEmail + "]" + permissions.note(12))
+			fmt.Println("  +-----------------------------------+")
+			fmt.Println("  13. Copy last id to clipboard")
+			fmt.Println("  +-----------------------------------+")
+			fmt.Println("  14. Choose active room [" + roomEmail + "]" + permissions.note(14))
+			fmt.Printf("  15. Toggle list users to resource accounts only [%t]\n", graphHelper.ResourceAccountsOnly())
+			fmt.Println("  +-----------------------------------+")
+			fmt.Println("  16. Reload Config")
+			fmt.Println("  +-----------------------------------+")
+			fmt.Println("  17. List All Places - By Type (room, workspace, equipment)" + permissions.note(17))
+			fmt.Println("  +-----------------------------------+")
+			fmt.Println("  18. Respond to event id - By Room [" + roomEmail + "]" + permissions.note(18))
+			fmt.Println("  19. Find 30 days of Events by subject - By Room [" + roomEmail + "]" + permissions.note(19))
+			fmt.Println("  +-----------------------------------+")
+			fmt.Println("  20. Save a listing to a file")
+			fmt.Println("  +-----------------------------------+")
+			fmt.Println("  21. Show mailbox settings - By Room [" + roomEmail + "]" + permissions.note(21))
+			fmt.Println("  +-----------------------------------+")
+			fmt.Println("  22. Find meeting times in the next 7 days - By Organiser [" + organiserEmail + "] in Room [" + roomEmail + "]" + permissions.note(22))
+			fmt.Println("  +-----------------------------------+")
+			fmt.Println("  23. Renew all subscriptions")
+			fmt.Println("  24. Show recent Graph request ids")
+			fmt.Println("  25. Browse 7 days of Events - By Room [" + roomEmail + "]" + permissions.note(25))
+			fmt.Println("  26. Create a 1 day subscription - For every room" + permissions.note(26))
+			fmt.Println("  27. List 7 days of Events - By Room list" + permissions.note(27))
+			fmt.Println("  28. Test Endpoint [" + graphHelper.Config().Endpoint + "]")
+			fmt.Println("  29. Delete all events in a date range - By Room [" + roomEmail + "]" + permissions.note(29))
+			fmt.Println("  30. Show app permissions")
+			fmt.Println("  31. Browse subscriptions")
+			fmt.Println("  32. Show changed Events since last time - By Room [" + roomEmail + "]" + permissions.note(32))
+			fmt.Println("  33. Extend event id - By Organiser [" + organiserEmail + "]" + permissions.note(33))
+			fmt.Println("  34. Show version")
+			fmt.Println("  35. Show room details [" + roomEmail + "]" + permissions.note(35))
+			fmt.Println("  36. List declined bookings - By Room [" + roomEmail + "]" + permissions.note(36))
+			pageSize, orderBy := graphHelper.UserListing()
+			fmt.Printf("  37. Set list users page size and order [%d, %s]\n", pageSize, orderBy)
+			fmt.Println("  38. Write access token to a file")
+			fmt.Println("  39. Compare availability with another room - By Room [" + roomEmail + "]" + permissions.note(39))
+			fmt.Println("  40. List 7 days of cancelled Events - By Room [" + roomEmail + "]" + permissions.note(40))
+			fmt.Println("  41. List 7 days of online meetings - By Room [" + roomEmail + "]" + permissions.note(41))
+			fmt.Println("  42. List 7 days of Events by an organiser - By Room [" + roomEmail + "]" + permissions.note(42))
+			if graphHelper.Config().ShowPhotos {
+				fmt.Println("  43. Show photo - By Room or user [" + roomEmail + "]" + permissions.note(43))
+			}
+			fmt.Println("  44. Save subscriptions to a manifest")
+			fmt.Println("  45. Reconcile subscriptions with a manifest")
+			fmt.Println("  46. List 7 days of sent meeting invitations - By Organiser [" + organiserEmail + "]" + permissions.note(46))
+			fmt.Println("  47. Check a subscription is still active by the subscription id")
+			fmt.Println("  48. Export All Rooms to CSV" + permissions.note(48))
+			fmt.Println("  49. Show webhook notification counts")
+			fmt.Println("  50. List 7 days of Events in a category - By Room [" + roomEmail + "]" + permissions.note(50))
+			fmt.Printf("  51. Toggle hiding private event details [%t]\n", graphHelper.Config().HidePrivateEvents)
+			fmt.Println("  52. Look up the user id of an email" + permissions.note(52))
+			fmt.Println("  53. List 30 days of recurring series - By Room [" + roomEmail + "]" + permissions.note(53))
+			fmt.Println("  54. Show or set a room's booking policy [" + roomEmail + "]" + permissions.note(54))
+			fmt.Println("  55. Delete all subscriptions")
+			fmt.Printf("  56. Toggle raw JSON in detail views [%t]\n", graphHelper.Config().ShowRawJSON)
+			fmt.Println("  57. List disabled user accounts" + permissions.note(57))
+			fmt.Println("  58. List users by department" + permissions.note(58))
+			fmt.Println("  59. List users by job title" + permissions.note(59))
+			fmt.Println("  +-----------------------------------+")
+			fmt.Print(":> ")
+		})
 
 		_, err = fmt.Scanf("%d", &choice)
 		if err != nil {
 			choice = -1
 		}
 
-		switch choice {
-		case 0:
-			// Exit the program
-			fmt.Println("Goodbye...")
-		case 1:
-			// Display access token
-			displayAccessToken(graphHelper)
-		case 2:
-			// List users
-			listUsers(graphHelper)
-		case 3:
-			// List Subscriptions
-			listSubscriptions(graphHelper)
-		case 4:
-			// list rooms
-			listRooms(graphHelper)
-		case 5:
-			// list rooms
-			listRoomBookingsAsRoom(graphHelper)
-		case 6:
-			// list rooms
-			listRoomBookingsAsOrganiser(graphHelper)
-		case 7:
-			// create 1 day subscription
-			createOneDaySubscription(graphHelper)
-		case 8:
-			// delete subscription by subscription id asked for as input
-			deleteSubscription(graphHelper)
-		case 9:
-			// delete event by event id for the specified room//
-			deleteEventByRoom(graphHelper)
-		case 10:
-			// delete event by event id for the specified organiser
-			deleteEventByOrganiser(graphHelper)
-		default:
-			fmt.Println("Invalid choice! Please try again.")
-		}
+		out.do(func() {
+			if missing := permissions.missing(choice); missing != nil {
+				fmt.Printf("This option needs the %s application permission, use option 30 to see what is granted.\n", strings.Join(missing, " or "))
+				return
+			}
+
+			switch choice {
+			case 0:
+				// Exit the program
+				fmt.Println("Goodbye...")
+			case 1:
+				// Display access token
+				displayAccessToken(graphHelper)
+			case 2:
+				// List users
+				listUsers(graphHelper)
+			case 3:
+				// List Subscriptions
+				listSubscriptions(graphHelper)
+			case 4:
+				// list rooms
+				listRooms(graphHelper)
+			case 5:
+				// list rooms
+				listRoomBookingsAsRoom(graphHelper, live)
+			case 6:
+				// list rooms
+				listRoomBookingsAsOrganiser(graphHelper, live)
+			case 7:
+				// create 1 day subscription
+				createOneDaySubscription(graphHelper)
+			case 8:
+				// delete subscription by subscription id asked for as input
+				deleteSubscription(graphHelper)
+			case 9:
+				// delete event by event id for the specified room//
+				deleteEventByRoom(graphHelper)
+			case 10:
+				// delete event by event id for the specified organiser
+				deleteEventByOrganiser(graphHelper)
+			case 11:
+				// drop cached rooms and users
+				refreshCache(graphHelper)
+			case 12:
+				// create an event in one of the organiser's calendars, booking the room
+				createEventByOrganiser(graphHelper)
+			case 13:
+				// copy the most recently printed id
+				copyLastId(graphHelper)
+			case 14:
+				// pick the room used by the room actions
+				chooseRoom(graphHelper)
+			case 15:
+				// only show room and equipment mailboxes when listing users
+				graphHelper.SetResourceAccountsOnly(!graphHelper.ResourceAccountsOnly())
+				fmt.Printf("List users shows resource accounts only: %t\n", graphHelper.ResourceAccountsOnly())
+			case 16:
+				// re-read .env and .env.local
+				reloadConfig(graphHelper)
+				permissions.load(graphHelper)
+			case 17:
+				// list rooms or workspaces
+				listPlaces(graphHelper)
+			case 18:
+				// accept, tentatively accept or decline a meeting request for the room
+				respondToEventByRoom(graphHelper)
+			case 19:
+				// search the room's events by subject
+				findRoomEvents(graphHelper)
+			case 20:
+				// run one of the listings and also write it to a file
+				saveListing(graphHelper, live)
+			case 21:
+				// time zone, working hours and automatic replies of the room
+				showMailboxSettings(graphHelper)
+			case 22:
+				// suggest times when the attendees and the room are free
+				findMeetingTimes(graphHelper)
+			case 23:
+				// extend the expiry of every subscription
+				renewAllSubscriptions(graphHelper)
+			case 24:
+				// ids to quote to Microsoft support
+				showRecentRequests(graphHelper)
+			case 25:
+				// pick an event to see its details and act on it
+				browseRoomEvents(graphHelper)
+			case 26:
+				// subscribe to every room, retrying the ones that failed on request
+				subscribeAllRooms(graphHelper)
+			case 27:
+				// one agenda for all the rooms in a building
+				listRoomListAgenda(graphHelper)
+			case 28:
+				// check ENDPOINT answers Graph's validation request before subscribing
+				testEndpoint(graphHelper)
+			case 29:
+				// clear out the bookings left behind by testing
+				deleteRoomEventsInRange(graphHelper)
+			case 30:
+				// the application permissions granted to the app registration
+				showAppPermissions(graphHelper)
+			case 31:
+				// pick a subscription to delete or renew it
+				browseSubscriptions(graphHelper)
+			case 32:
+				// only what changed in the room's calendar since the previous sync
+				syncRoomEvents(graphHelper)
+			case 33:
+				// a meeting running long, without double booking the room
+				extendEventByOrganiser(graphHelper)
+			case 34:
+				// which build is running, for issue reports
+				showVersion()
+			case 35:
+				// building, floor and devices, to pick the right room
+				showRoomDetails(graphHelper)
+			case 36:
+				// why a booking of the room did not stick
+				listRoomDeclines(graphHelper)
+			case 37:
+				// larger pages and another order for big tenants
+				setUserListing(graphHelper)
+			case 38:
+				// for ad-hoc Graph calls with curl
+				writeAccessToken(graphHelper)
+			case 39:
+				// pick the better of two rooms for a meeting
+				compareRoomAvailability(graphHelper)
+			case 40:
+				// bookings cancelled but still holding the room
+				listFilteredRoomEvents(graphHelper, graphhelper.EventFilter{OnlyCancelled: true})
+			case 41:
+				listFilteredRoomEvents(graphHelper, graphhelper.EventFilter{OnlyOnlineMeetings: true})
+			case 42:
+				promptInput("Enter the organiser's email (blank to cancel):", func(organiser string) {
+					listFilteredRoomEvents(graphHelper, graphhelper.EventFilter{Organiser: organiser})
+				})
+			case 43:
+				// recognise a room or person at a glance
+				showPhoto(graphHelper)
+			case 44:
+				// record the subscriptions wanted, to restore them later
+				saveSubscriptionManifest(graphHelper)
+			case 45:
+				// create, renew and delete until the subscriptions match the manifest
+				reconcileSubscriptions(graphHelper)
+			case 46:
+				// what the organiser has booked, and whether the rooms took it
+				listOrganiserInvitations(graphHelper)
+			case 47:
+				// after the webhook goes quiet, whether Graph still has the subscription
+				checkSubscription(graphHelper)
+			case 48:
+				// capacity, building and floor of every room for facilities
+				exportRooms(graphHelper)
+			case 49:
+				// whether a burst of notifications overflowed the queue
+				queued, dropped, persisted := queue.counts()
+				fmt.Printf("Notifications waiting: %d, dropped: %d, written to the overflow log: %d\n", queued, dropped, persisted)
+			case 50:
+				promptInput("Enter the category (blank to cancel):", func(category string) {
+					listFilteredRoomEvents(graphHelper, graphhelper.EventFilter{Category: category})
+				})
+			case 51:
+				// show private and confidential events as "Private", for when the screen is shared
+				graphHelper.SetHidePrivateEvents(!graphHelper.Config().HidePrivateEvents)
+				fmt.Printf("Private event details hidden: %t\n", graphHelper.Config().HidePrivateEvents)
+			case 52:
+				// the object id behind a mailbox whose email is not its UPN
+				promptInput("Enter the email of the room or user (blank to cancel):", func(email string) {
+					resolveUserId(graphHelper, email)
+				})
+			case 53:
+				// recurring bookings, to cancel one occurrence instead of the whole series
+				listRecurringSeries(graphHelper)
+			case 54:
+				// reserve a room, or open it to everyone again, without Exchange PowerShell
+				setRoomBookingPolicy(graphHelper)
+			case 55:
+				// clear out every subscription, resuming a run that was interrupted or throttled
+				deleteAllSubscriptions(graphHelper)
+			case 56:
+				// the objects as Graph returned them, for debugging unexpected field values
+				graphHelper.SetShowRawJSON(!graphHelper.Config().ShowRawJSON)
+				fmt.Printf("Raw JSON in detail views: %t\n", graphHelper.Config().ShowRawJSON)
+			case 57:
+				// accounts that can no longer sign in but still hold mailboxes or licences
+				disabled := false
+				listFilteredUsers(graphHelper, graphhelper.UserFilter{AccountEnabled: &disabled})
+			case 58:
+				promptInput("Enter the department (blank to cancel):", func(department string) {
+					listFilteredUsers(graphHelper, graphhelper.UserFilter{Department: department})
+				})
+			case 59:
+				promptInput("Enter the job title (blank to cancel):", func(jobTitle string) {
+					listFilteredUsers(graphHelper, graphhelper.UserFilter{JobTitle: jobTitle})
+				})
+			default:
+				fmt.Println("Invalid choice! Please try again.")
+			}
+		})
 
 		if choice == 0 {
 			break
 		}
 	}
+	shutdown()
+}
+
+// envFileKeys are the variables currently set from the .env files, so a reload can unset
+// the ones that have since been removed from them. Only used with the console held.
+var envFileKeys = map[string]bool{}
+
+// fromEnvFiles reports whether key takes its value from the .env files: it was set from them
+// before, or is not set at all. Variables already in the environment are not overridden.
+func fromEnvFiles(key string) bool {
+	if envFileKeys[key] {
+		return true
+	}
+	_, set := os.LookupEnv(key)
+	return !set
+}
+
+// readEnvFiles returns the values in .env, overridden by those in .env.local (if present), from
+// the files findEnvFiles finds. When there are none no values are returned, as the settings
+// may all be in the environment.
+func readEnvFiles() (map[string]string, error) {
+	files, found, err := findEnvFiles()
+	if err != nil || !found {
+		return map[string]string{}, err
+	}
+	return files.read()
+}
+
+// reloadConfig re-reads .env and .env.local, overriding values loaded at startup, and
+// re-initializes Graph so changed credentials take effect. Variables removed from the files
+// are unset. As at startup, variables set in the environment by other means are kept. If the
+// new configuration is invalid the problems are reported and the previous configuration is
+// kept. The webhook server settings are only read at startup.
+func reloadConfig(graphHelper *graphhelper.GraphHelper) {
+
+	values, err := readEnvFiles()
+	if err != nil {
+		log.Printf("Error reloading .env: %v", err)
+		return
+	}
+
+	config, err := graphhelper.LoadConfigFrom(func(key string) string {
+		if value, ok := values[key]; ok && fromEnvFiles(key) {
+			return value
+		}
+		if envFileKeys[key] {
+			// removed from the files since they were last read
+			return ""
+		}
+		return os.Getenv(key)
+	})
+	if err != nil {
+		log.Printf("Config not reloaded: %v", err)
+		return
+	}
+
+	for key := range envFileKeys {
+		if _, ok := values[key]; !ok {
+			os.Unsetenv(key)
+			delete(envFileKeys, key)
+		}
+	}
+	for key, value := range values {
+		if fromEnvFiles(key) {
+			os.Setenv(key, value)
+			envFileKeys[key] = true
+		}
+	}
+
+	graphHelper.ReloadConfig(config)
+	if err := graphHelper.InitializeGraphForAppAuth(); err != nil {
+		log.Printf("Error initializing Graph for app auth after reload: %v", err)
+		return
+	}
+	log.Println("Config reloaded")
+}
+
+// printProgress shows how many pages have been fetched, rewriting the same line until the fetch is done.
+func printProgress(what string, page int, fetched int, done bool) {
+	if done {
+		if page > 1 {
+			fmt.Printf("\rFetched %d pages, %d %s         \n", page, fetched, what)
+		}
+		return
+	}
+	spinner := `|/-\`
+	fmt.Printf("\r%c Fetched page %d, %d %s so far...", spinner[page%len(spinner)], page, fetched, what)
 }
 
 func initializeGraph(graphHelper *graphhelper.GraphHelper) {
 	err := graphHelper.InitializeGraphForAppAuth()
 	if err != nil {
-		log.Panicf("Error initializing Graph for app auth: %v\n", err)
+		// keep the menu usable, every Graph option will report the failure
+		log.Printf("Error initializing Graph for app auth: %v\n", err)
 	}
 }
 
-func displayAccessToken(graphHelper *graphhelper.GraphHelper) {
-	token, err := graphHelper.GetAppToken()
+// webhookShutdownTimeout is how long notifications being handled and queued get to finish at shutdown.
+const webhookShutdownTimeout = 5 * time.Second
+
+// newWebhookHandler routes "/webhook" to handleGraphSubscription on a mux of its own, so the
+// server does not share http.DefaultServeMux and can be started more than once in tests.
+func newWebhookHandler(queue *notificationQueue, clientState func() string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/webhook", func(w http.ResponseWriter, r *http.Request) {
+		handleGraphSubscription(w, r, queue, clientState())
+	})
+	return mux
+}
+
+// startWebhookServer binds the server's port and serves subscription notifications.
+// A port that is briefly in use is retried with exponential backoff; if it still cannot be
+// bound the error is reported and the rest of the tool keeps working without notifications.
+// When a certificate and key are given the server speaks HTTPS directly.
+func startWebhookServer(server *http.Server, retries int, certFile string, keyFile string) {
+	useTLS := certFile != "" || keyFile != ""
+	if useTLS {
+		// Check the pair loads before binding so a bad path is reported clearly
+		if _, err := tls.LoadX509KeyPair(certFile, keyFile); err != nil {
+			log.Printf("Server error, webhook notifications are disabled: invalid WEBHOOK_TLS_CERT/WEBHOOK_TLS_KEY: %v", err)
+			return
+		}
+	}
+
+	listener, err := bindWebhookPort(server.Addr, retries)
 	if err != nil {
-		log.Panicf("Error getting user token: %v\n", err)
+		log.Printf("Server error, webhook notifications are disabled: %v", err)
+		return
 	}
+	serveWebhook(server, listener, certFile, keyFile)
+}
 
-	fmt.Printf("App-only token: %s", *token)
-	fmt.Println()
+// bindWebhookPort listens on port, retrying with exponential backoff while it is in use.
+func bindWebhookPort(port string, retries int) (net.Listener, error) {
+	backoff := time.Second
+	for attempt := 0; ; attempt++ {
+		listener, err := net.Listen("tcp", port)
+		if err == nil {
+			return listener, nil
+		}
+		if attempt >= retries {
+			return nil, err
+		}
+		log.Printf("Server could not bind [port: %s], retrying in %v: %v", port, backoff, err)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
 }
 
-func listUsers(graphHelper *graphhelper.GraphHelper) {
-	users, err := graphHelper.GetUsers()
-	if err != nil {
-		log.Panicf("Error getting users: %v", err)
+// serveWebhook serves notifications on listener until the server is shut down.
+func serveWebhook(server *http.Server, listener net.Listener, certFile string, keyFile string) {
+	port := listener.Addr().String()
+	var err error
+	if certFile != "" || keyFile != "" {
+		log.Println("Server starting with TLS... [port: " + port + "]")
+		err = server.ServeTLS(listener, certFile, keyFile)
+	} else {
+		log.Println("Server starting... [port: " + port + "]")
+		err = server.Serve(listener)
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Printf("Server error: %v", err)
+	}
+}
+
+// shutdownWebhookServer stops accepting notifications, then waits for the queued ones to be
+// processed, so none that Graph was told were received is lost or left half written to
+// "WEBHOOK_LOG_FILE". Both together are bounded by webhookShutdownTimeout.
+func shutdownWebhookServer(server *http.Server, queue *notificationQueue) {
+	ctx, cancel := context.WithTimeout(context.Background(), webhookShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Server shutdown: %v", err)
 	}
+	if err := queue.close(ctx); err != nil {
+		log.Printf("Webhook queue shutdown: %v", err)
+	}
+}
 
-	// Output each user's details
-	for _, user := range users.GetValue() {
-		fmt.Printf("User: %s\n", *user.GetDisplayName())
-		fmt.Printf("  ID: %s\n", *user.GetId())
+// subscribeOnStartup creates event subscriptions for the configured room and organiser,
+// skipping any resource that already has one. Subscriptions expire, so this keeps
+// notifications flowing across restarts.
+func subscribeOnStartup(graphHelper *graphhelper.GraphHelper) {
 
-		noEmail := "NO EMAIL"
-		email := user.GetMail()
-		if email == nil {
-			email = &noEmail
+	config := graphHelper.Config()
+	summary := graphhelper.ForEachRoom([]string{config.RoomEmail, config.OrganiserEmail}, func(email string) error {
+		existing, err := graphHelper.FindEventsSubscription(email)
+		if err != nil {
+			return err
 		}
-		fmt.Printf("  Email: %s\n", *email)
+		if existing != nil {
+			log.Printf("Startup subscribe: %s already has subscription %s", email, *existing.GetId())
+			return nil
+		}
+		_, err = graphHelper.CreateRoomSubscription(email, false)
+		return err
+	})
+	if err := summary.Err(); err != nil {
+		log.Printf("Startup subscribe %v", err)
 	}
+}
 
-	// If GetOdataNextLink does not return nil,
-	// there are more users available on the server
-	nextLink := users.GetOdataNextLink()
+func displayAccessToken(graphHelper *graphhelper.GraphHelper) {
+	token, err := graphHelper.GetAppToken()
+	if err != nil {
+		log.Printf("Error getting user token: %v\n", err)
+		return
+	}
 
-	fmt.Println()
-	fmt.Printf("More users available? %t\n", nextLink != nil)
+	fmt.Printf("App-only token: %s", *token)
 	fmt.Println()
 }
 
-func listSubscriptions(graphHelper *graphhelper.GraphHelper) {
+// defaultTokenFile is where writeAccessToken writes the token when no file is given.
+const defaultTokenFile = "graph-token.json"
 
-	subscriptions, err := graphHelper.ListSubscriptions()
+// writeAccessToken writes the app-only token and its expiry to a private file for other tools.
+func writeAccessToken(graphHelper *graphhelper.GraphHelper) {
+	fmt.Println("The token grants all of the app's permissions until it expires. Keep the file private and delete it when done.")
+	fmt.Printf("Enter the file to write (blank for %s):\n", defaultTokenFile)
+	path, err := readLine()
 	if err != nil {
-		log.Panicf("Error making Graph call: %v", err)
+		log.Printf("Error reading file name: %v", err)
+		return
+	}
+	if path = strings.TrimSpace(path); path == "" {
+		path = defaultTokenFile
 	}
 
-	// check for nil size on the subscriptions
-	if subscriptions == nil {
-		fmt.Println("No subscriptions found")
+	expires, err := graphHelper.WriteAppToken(path)
+	if err != nil {
+		log.Printf("Error writing access token: %v", err)
 		return
 	}
+	fmt.Printf("Wrote the access token to %s, it expires at %s\n", path, expires.In(graphHelper.Config().TimeZone).Format(graphhelper.DisplayLayout))
+	fmt.Printf("Use it with: curl -H \"Authorization: Bearer $(jq -r .access_token %s)\" https://graph.microsoft.com/v1.0/users\n", path)
+}
 
-	for _, subscription := range subscriptions.GetValue() {
-		fmt.Printf("SubscriptionId: %s\n", *subscription.GetId())
-		fmt.Printf("  ChangeType: %s\n", *subscription.GetChangeType())
-		fmt.Printf("  ExpirationDateTime: %s\n", subscription.GetExpirationDateTime().String())
-		fmt.Printf("  Resource: %s\n", *subscription.GetResource())
-		fmt.Printf("  ApplicationId: %s\n", *subscription.GetApplicationId())
-		// print the additional data
-		fmt.Printf("  Additional Data length: %v\n", len(subscription.GetAdditionalData()))
-		//fmt.Printf("  LifecycleNotificationURL: %v\n", *subscription.GetLifecycleNotificationUrl())
-		//fmt.Printf("  ClientState: %s\n", *subscription.GetClientState())
-		fmt.Printf("  CreatorId: %v\n", *subscription.GetCreatorId())
-		fmt.Printf("  NotificationURL: %v\n", *subscription.GetNotificationUrl())
-
-		fmt.Println()
+func showRecentRequests(graphHelper *graphhelper.GraphHelper) {
+	requests := graphHelper.RecentRequests()
+	if len(requests) == 0 {
+		fmt.Println("No Graph requests made yet")
+		return
+	}
 
+	for _, request := range requests {
+		fmt.Printf("%s %s %s -> %d\n", request.Time.Format("15:04:05"), request.Method, request.Path, request.Status)
+		fmt.Printf("  client-request-id: %s\n", request.ClientRequestId)
+		fmt.Printf("  request-id: %s\n", request.RequestId)
 	}
 }
 
-func listRooms(graphHelper *graphhelper.GraphHelper) {
-
-	graphHelper.ListRooms()
+// showAppPermissions lists the application permissions granted to the app registration.
+// When the app may not read the directory, the roles in its access token are shown instead.
+func showAppPermissions(graphHelper *graphhelper.GraphHelper) {
+	permissions, err := graphHelper.GetAppPermissions()
+	if err == nil {
+		var names []string
+		for _, permission := range permissions {
+			fmt.Printf("%s: %s\n", permission.Resource, permission.Permission)
+			names = append(names, permission.Permission)
+		}
+		fmt.Printf("%d permissions granted\n", len(permissions))
+		checkCalendarPermission(names)
+		return
+	}
+	if !errors.Is(err, graphhelper.ErrPermissionsNotReadable) {
+		log.Printf("Error listing app permissions: %v", err)
+		return
+	}
 
+	fmt.Println(err)
+	fmt.Println("Showing the roles in the access token instead:")
+	token, err := graphHelper.GetAppToken()
+	if err != nil {
+		log.Printf("Error getting app token: %v", err)
+		return
+	}
+	roles, err := graphhelper.TokenRoles(*token)
+	if err != nil {
+		log.Printf("Error reading app token: %v", err)
+		return
+	}
+	for _, role := range roles {
+		fmt.Printf("Microsoft Graph: %s\n", role)
+	}
+	fmt.Printf("%d permissions granted\n", len(roles))
+	checkCalendarPermission(roles)
 }
 
-func listRoomBookingsAsOrganiser(graphHelper *graphhelper.GraphHelper) {
+// checkCalendarPermission warns when the permission every event option needs is missing.
+func checkCalendarPermission(permissions []string) {
+	for _, permission := range permissions {
+		if permission == "Calendars.ReadWrite" {
+			return
+		}
+	}
+	fmt.Println("Calendars.ReadWrite is not granted, listing, creating and deleting events will fail.")
+	fmt.Println("Add it as an application permission of the app registration and grant admin consent.")
+}
 
-	organiser := graphHelper.GetOrganiserEmail()
-	if organiser == "" {
-		fmt.Println("No organiser found")
+func listUsers(graphHelper *graphhelper.GraphHelper) {
+	users, err := graphHelper.GetUsers()
+	if err != nil {
+		log.Printf("Error getting users: %v", err)
+		return
+	}
+	if len(users) == 0 {
+		fmt.Println("No users found")
 		return
 	}
 
-	graphHelper.ListRoom7DaysBookings(organiser)
+	printUsers(graphHelper, users)
 
+	fmt.Println()
+	fmt.Printf("Users listed: %d\n", len(users))
+	total, err := graphHelper.CountUsers()
+	if err == nil {
+		fmt.Printf("Total users: %d\n", total)
+	}
+	fmt.Println()
 }
 
-func listRoomBookingsAsRoom(graphHelper *graphhelper.GraphHelper) {
-
-	roomEmail := graphHelper.GetRoomEmail()
-	if roomEmail == "" {
-		fmt.Println("No room email found")
+// listFilteredUsers lists the users that match the filter, for directory audits.
+func listFilteredUsers(graphHelper *graphhelper.GraphHelper, filter graphhelper.UserFilter) {
+	users, err := graphHelper.GetFilteredUsers(filter)
+	if err != nil {
+		log.Printf("Error getting %s: %v", filter, err)
+		return
+	}
+	if len(users) == 0 {
+		fmt.Printf("No %s found\n", filter)
 		return
 	}
 
-	graphHelper.ListRoom7DaysBookings(roomEmail)
+	printUsers(graphHelper, users)
 
+	fmt.Println()
+	fmt.Printf("Found %d %s\n", len(users), filter)
+	fmt.Println()
 }
 
-func handleGraphSubscription(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "POST" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+// printUsers prints each user's details, with their account state, department and job title
+// when those were fetched.
+func printUsers(graphHelper *graphhelper.GraphHelper, users []models.Userable) {
+	for _, user := range users {
+		fmt.Printf("User: %s\n", graphhelper.StringOrDefault(user.GetDisplayName(), "(unknown)"))
+		fmt.Printf("  ID: %s\n", graphhelper.StringOrDefault(user.GetId(), "-"))
+		graphHelper.SetLastId(user.GetId())
+
+		noEmail := "NO EMAIL"
+		email := user.GetMail()
+		if email == nil {
+			email = &noEmail
+		}
+		fmt.Printf("  Email: %s\n", *email)
+		if user.GetIsResourceAccount() != nil {
+			fmt.Printf("  Resource account: %t\n", *user.GetIsResourceAccount())
+		}
+		if user.GetAccountEnabled() != nil {
+			fmt.Printf("  Account enabled: %t\n", *user.GetAccountEnabled())
+		}
+		if user.GetDepartment() != nil {
+			fmt.Printf("  Department: %s\n", *user.GetDepartment())
+		}
+		if user.GetJobTitle() != nil {
+			fmt.Printf("  Job title: %s\n", *user.GetJobTitle())
+		}
+	}
+}
+
+// setUserListing asks for the page size and sort order of user listings, keeping the current
+// values for blank answers.
+func setUserListing(graphHelper *graphhelper.GraphHelper) {
+
+	pageSize, orderBy := graphHelper.UserListing()
+
+	fmt.Printf("Enter the page size, 1 to %d (blank for %d):\n", graphhelper.MaxUsersPageSize, pageSize)
+	answer, err := readLine()
+	if err != nil {
+		log.Printf("Error reading page size: %v", err)
 		return
 	}
+	if answer = strings.TrimSpace(answer); answer != "" {
+		pageSize, err = strconv.Atoi(answer)
+		if err != nil {
+			fmt.Printf("%q is not a number, user listing unchanged\n", answer)
+			return
+		}
+	}
 
-	body, err := ioutil.ReadAll(r.Body)
+	fmt.Printf("Enter the order, one of %s (blank for %s):\n", strings.Join(graphhelper.UserOrderFields, ", "), orderBy)
+	answer, err = readLine()
 	if err != nil {
-		http.Error(w, "Failed to read request body", http.StatusInternalServerError)
+		log.Printf("Error reading order: %v", err)
 		return
 	}
+	if answer = strings.TrimSpace(answer); answer != "" {
+		orderBy = answer
+	}
 
-	// Check if this is a validation request
-	if r.URL.Query().Get("validationToken") != "" {
-		validationToken := r.URL.Query().Get("validationToken")
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(validationToken))
-		log.Println("Validation token sent back to Microsoft Graph:", validationToken)
+	if err := graphHelper.SetUserListing(pageSize, orderBy); err != nil {
+		fmt.Printf("%v, user listing unchanged\n", err)
 		return
 	}
+	fmt.Printf("Users are now listed %d per page, by %s\n", pageSize, orderBy)
+}
 
-	// If not a validation request, this is likely an event notification
-	log.Printf("Received notification: %s", string(body))
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("Notification received"))
+func refreshCache(graphHelper *graphhelper.GraphHelper) {
+	graphHelper.InvalidateCache()
+	fmt.Println("Cache cleared, rooms and users will be fetched from Graph on next use")
 }
 
-func createOneDaySubscription(graphHelper *graphhelper.GraphHelper) {
-	roomEmail := graphHelper.GetRoomEmail()
-	if roomEmail == "" {
-		fmt.Println("No room email found")
+func copyLastId(graphHelper *graphhelper.GraphHelper) {
+	id := graphHelper.LastId()
+	if id == "" {
+		fmt.Println("No id has been printed yet")
 		return
 	}
 
-	values := graphHelper.CreateRoomSubscription(roomEmail)
-	println(values)
+	err := copyToClipboard(id)
+	if err != nil {
+		fmt.Printf("Clipboard not available (%v), copy it manually: %s\n", err, id)
+		return
+	}
+	fmt.Printf("Copied %s to the clipboard\n", id)
 }
 
-func deleteSubscription(graphHelper *graphhelper.GraphHelper) {
+func listSubscriptions(graphHelper *graphhelper.GraphHelper) {
 
-	// As user to input the subscription id to delete
-	var subscriptionId string
-	fmt.Println("Enter the subscription id to delete")
-	_, err := fmt.Scanf("%s", &subscriptionId)
+	subscriptions, err := graphHelper.ListSubscriptions()
 	if err != nil {
-		log.Printf("Error reading subscription id: %v", err)
+		log.Printf("Error making Graph call: %v", err)
 		return
 	}
-	// now deleteSubscription
-	err = graphHelper.DeleteSubscription(subscriptionId)
-	if err != nil {
-		log.Printf("Error deleting subscription: %v", err)
+
+	if len(subscriptions) == 0 {
+		fmt.Println("No subscriptions found")
 		return
 	}
+
+	endpoint, toEndpoint := graphHelper.Config().Endpoint, 0
+	for _, subscription := range subscriptions {
+		if graphhelper.StringOrDefault(subscription.GetNotificationUrl(), "") == endpoint {
+			toEndpoint++
+		}
+		printSubscription(graphHelper, subscription)
+		fmt.Println()
+	}
+	fmt.Printf("Found %d subscriptions, %d delivering to ENDPOINT\n", len(subscriptions), toEndpoint)
 }
 
-func deleteEventByOrganiser(graphHelper *graphhelper.GraphHelper) {
+// printSubscription prints the details of a subscription. Fields Graph leaves unset are shown as "-".
+func printSubscription(graphHelper *graphhelper.GraphHelper, subscription models.Subscriptionable) {
+	fmt.Printf("SubscriptionId: %s\n", graphhelper.StringOrDefault(subscription.GetId(), "-"))
+	graphHelper.SetLastId(subscription.GetId())
+	fmt.Printf("  ChangeType: %s\n", graphhelper.StringOrDefault(subscription.GetChangeType(), "-"))
+	expiration := "-"
+	if subscription.GetExpirationDateTime() != nil {
+		expiration = subscription.GetExpirationDateTime().String()
+	}
+	fmt.Printf("  ExpirationDateTime: %s\n", expiration)
+	fmt.Printf("  Resource: %s\n", graphhelper.StringOrDefault(subscription.GetResource(), "-"))
+	fmt.Printf("  ApplicationId: %s\n", graphhelper.StringOrDefault(subscription.GetApplicationId(), "-"))
+	fmt.Printf("  CreatorId: %s\n", graphhelper.StringOrDefault(subscription.GetCreatorId(), "-"))
+	fmt.Printf("  NotificationURL: %s\n", graphhelper.StringOrDefault(subscription.GetNotificationUrl(), "-"))
+	if subscription.GetLifecycleNotificationUrl() != nil {
+		fmt.Printf("  LifecycleNotificationURL: %s\n", *subscription.GetLifecycleNotificationUrl())
+	}
+	// only show whether a client state is set, never the secret itself
+	fmt.Printf("  ClientState set: %t\n", subscription.GetClientState() != nil)
+	// print the additional data
+	fmt.Printf("  Additional Data length: %v\n", len(subscription.GetAdditionalData()))
+}
 
-	organiser := graphHelper.GetOrganiserEmail()
-	if organiser == "" {
-		fmt.Println("No organiser found")
-		return
+// browseSubscriptions lists the subscriptions by number and offers to delete or renew the
+// chosen one, without copying ids around. The list is fetched again after every action.
+func browseSubscriptions(graphHelper *graphhelper.GraphHelper) {
+	for {
+		subscriptions, err := graphHelper.ListSubscriptions()
+		if err != nil {
+			log.Printf("Error listing subscriptions: %v", err)
+			return
+		}
+		if len(subscriptions) == 0 {
+			fmt.Println("No subscriptions found")
+			return
+		}
+
+		fmt.Println("Choose a subscription:")
+		fmt.Println("  0.  Back")
+		for i, subscription := range subscriptions {
+			expiration := "-"
+			if subscription.GetExpirationDateTime() != nil {
+				expiration = subscription.GetExpirationDateTime().In(graphHelper.Config().TimeZone).Format(graphhelper.DisplayLayout)
+			}
+			fmt.Printf("  %d.  expires %s  %s\n", i+1, expiration, graphhelper.StringOrDefault(subscription.GetResource(), "-"))
+		}
+		fmt.Print(":> ")
+
+		var choice int
+		_, err = fmt.Scanf("%d", &choice)
+		if err != nil || choice < 1 || choice > len(subscriptions) {
+			return
+		}
+		subscription := subscriptions[choice-1]
+		printSubscription(graphHelper, subscription)
+		graphHelper.WriteRawJSON(os.Stdout, subscription)
+		subscriptionId := graphhelper.StringOrDefault(subscription.GetId(), "")
+		if subscriptionId == "" {
+			fmt.Println("The subscription has no id")
+			continue
+		}
+
+		fmt.Println("  0.  Back")
+		fmt.Println("  1.  Delete")
+		fmt.Println("  2.  Renew for another day")
+		fmt.Print(":> ")
+
+		var action int
+		_, err = fmt.Scanf("%d", &action)
+		if err != nil {
+			continue
+		}
+
+		switch action {
+		case 1:
+			fmt.Printf("Delete subscription %s? (y/N):\n", subscriptionId)
+			answer, err := readLine()
+			if err != nil || !strings.EqualFold(strings.TrimSpace(answer), "y") {
+				fmt.Println("Nothing deleted")
+				continue
+			}
+			err = graphHelper.DeleteSubscription(subscriptionId)
+			if err != nil {
+				log.Printf("Error deleting subscription: %v", err)
+				continue
+			}
+			fmt.Println("Deleted")
+		case 2:
+			err = graphHelper.RenewSubscription(subscriptionId, time.Now().Add(24*time.Hour))
+			if err != nil {
+				log.Printf("Error renewing subscription: %v", err)
+				continue
+			}
+			fmt.Println("Renewed")
+		}
 	}
+}
 
-	var eventId string
-	fmt.Println("Enter the event id to cancel:")
-	_, err := fmt.Scanf("%s", &eventId)
+// readManifestPath asks for the subscription manifest file, the default one if left blank.
+func readManifestPath() (string, error) {
+	fmt.Printf("Enter the manifest file (blank for %s):\n", graphhelper.DefaultSubscriptionManifest)
+	path, err := readLine()
 	if err != nil {
-		log.Printf("Error reading event id: %v", err)
+		return "", err
+	}
+	if path = strings.TrimSpace(path); path == "" {
+		path = graphhelper.DefaultSubscriptionManifest
+	}
+	return path, nil
+}
+
+func saveSubscriptionManifest(graphHelper *graphhelper.GraphHelper) {
+
+	path, err := readManifestPath()
+	if err != nil {
+		log.Printf("Error reading file name: %v", err)
 		return
 	}
-	err = graphHelper.DeleteEvent(organiser, eventId)
+
+	manifest, err := graphHelper.CurrentSubscriptionManifest()
 	if err != nil {
-		log.Printf("Error canceling event: %v", err)
+		log.Printf("Error reading subscriptions: %v", err)
+		return
+	}
+	if err := graphhelper.SaveSubscriptionManifest(path, manifest); err != nil {
+		log.Printf("Error saving manifest: %v", err)
 		return
 	}
+	fmt.Printf("Saved %d subscriptions to %s\n", len(manifest.Subscriptions), path)
 }
 
-func deleteEventByRoom(graphHelper *graphhelper.GraphHelper) {
+// reconcileSubscriptions shows what it takes to make the subscriptions match a manifest and,
+// once confirmed, does it and reports the outcome of each action.
+func reconcileSubscriptions(graphHelper *graphhelper.GraphHelper) {
 
-	var eventId string
-	fmt.Println("Enter the event id to cancel:")
-	_, err := fmt.Scanf("%s", &eventId)
+	path, err := readManifestPath()
 	if err != nil {
-		log.Printf("Error reading event id: %v", err)
+		log.Printf("Error reading file name: %v", err)
+		return
+	}
+	manifest, err := graphhelper.LoadSubscriptionManifest(path)
+	if err != nil {
+		log.Printf("Error loading manifest: %v", err)
 		return
 	}
 
-	roomEmail := graphHelper.GetRoomEmail()
-	if roomEmail == "" {
-		fmt.Println("No room email found")
+	actions, err := graphHelper.PlanReconcile(manifest)
+	if err != nil {
+		log.Printf("Error planning reconciliation: %v", err)
 		return
 	}
-	err = graphHelper.DeleteEvent(roomEmail, eventId)
+	if len(actions) == 0 {
+		fmt.Println("The manifest and the tenant have no subscriptions, nothing to do")
+		return
+	}
+	for _, action := range actions {
+		fmt.Println(action)
+	}
+	if !confirm(fmt.Sprintf("Take these %d actions?", len(actions))) {
+		fmt.Println("Nothing changed")
+		return
+	}
+
+	err = graphHelper.ApplyReconcile(context.Background(), actions)
+	for _, action := range actions {
+		fmt.Println(action)
+	}
 	if err != nil {
-		log.Printf("Error canceling event: %v", err)
+		log.Printf("Error reconciling subscriptions: %v", err)
 		return
 	}
+	fmt.Printf("The subscriptions match %s\n", path)
+}
+
+func listRooms(graphHelper *graphhelper.GraphHelper) {
+
+	graphHelper.PrintRooms(os.Stdout)
+
+}
+
+// exportRooms asks for a file and writes every room's capacity, building and floor to it as CSV.
+func exportRooms(graphHelper *graphhelper.GraphHelper) {
+
+	fmt.Printf("Enter the file to write (blank for %s):\n", graphhelper.DefaultRoomsCSV)
+	path, err := readLine()
+	if err != nil {
+		log.Printf("Error reading file name: %v", err)
+		return
+	}
+	if path = strings.TrimSpace(path); path == "" {
+		path = graphhelper.DefaultRoomsCSV
+	}
+
+	rows, err := graphHelper.ExportRoomsCSV(path)
+	if err != nil {
+		log.Printf("Error exporting rooms: %v", err)
+		return
+	}
+	fmt.Printf("Wrote %d rooms to %s\n", rows, path)
+}
+
+// showRoomDetails asks for a room, the active room by default, and prints everything Places holds about it.
+func showRoomDetails(graphHelper *graphhelper.GraphHelper) {
+
+	roomEmail := graphHelper.Config().RoomEmail
+
+	fmt.Println("Enter the room id or email (blank for " + roomEmail + "):")
+	roomId, err := readLine()
+	if err != nil {
+		log.Printf("Error reading room: %v", err)
+		return
+	}
+	roomId = strings.TrimSpace(roomId)
+	if roomId == "" {
+		roomId = roomEmail
+	}
+
+	room, err := graphHelper.GetRoom(roomId)
+	if err != nil {
+		log.Printf("Error getting room: %v", err)
+		return
+	}
+	graphHelper.PrintRoomDetails(os.Stdout, room)
+	graphHelper.WriteRawJSON(os.Stdout, room)
+}
+
+// setRoomBookingPolicy shows a room's booking policy, the active room by default, and changes
+// its booking type or capacity once confirmed.
+func setRoomBookingPolicy(graphHelper *graphhelper.GraphHelper) {
+
+	roomEmail := graphHelper.Config().RoomEmail
+
+	fmt.Println("Enter the room id or email (blank for " + roomEmail + "):")
+	roomId, err := readLine()
+	if err != nil {
+		log.Printf("Error reading room: %v", err)
+		return
+	}
+	roomId = strings.TrimSpace(roomId)
+	if roomId == "" {
+		roomId = roomEmail
+	}
+
+	current, err := graphHelper.GetRoomBookingPolicy(roomId)
+	if err != nil {
+		log.Printf("Error getting booking policy: %v", err)
+		return
+	}
+	fmt.Printf("Booking policy of %s: %s\n", roomId, current)
+
+	unchanged := graphhelper.RoomBookingPolicy{BookingType: current.BookingType}
+	if unchanged.BookingType == "" {
+		// Places has none for rooms never given one, which book as standard rooms
+		unchanged.BookingType = graphhelper.DefaultRoomBookingPolicy.BookingType
+	}
+	policy := unchanged
+	fmt.Printf("Enter the booking type %v, or clear to make it standard (blank to leave it):\n", graphhelper.RoomBookingTypes)
+	bookingType, err := readLine()
+	if err != nil {
+		log.Printf("Error reading booking type: %v", err)
+		return
+	}
+	switch bookingType = strings.ToLower(strings.TrimSpace(bookingType)); bookingType {
+	case "":
+	case "clear":
+		policy = graphhelper.DefaultRoomBookingPolicy
+	default:
+		policy.BookingType = bookingType
+	}
+
+	fmt.Printf("Enter the capacity, 1 to %d (blank to leave it):\n", graphhelper.MaxRoomCapacity)
+	capacityValue, err := readLine()
+	if err != nil {
+		log.Printf("Error reading capacity: %v", err)
+		return
+	}
+	if capacityValue = strings.TrimSpace(capacityValue); capacityValue != "" {
+		capacity, err := strconv.ParseInt(capacityValue, 10, 32)
+		if err != nil {
+			log.Printf("Error reading capacity: %q is not a number", capacityValue)
+			return
+		}
+		value := int32(capacity)
+		policy.Capacity = &value
+	}
+
+	if policy.BookingType == unchanged.BookingType && policy.Capacity == nil {
+		fmt.Println("Nothing to change")
+		return
+	}
+	if err := policy.Validate(); err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	if !confirm(fmt.Sprintf("Set the booking policy of %s to %s?", roomId, policy)) {
+		fmt.Println("Cancelled")
+		return
+	}
+
+	updated, err := graphHelper.SetRoomBookingPolicy(context.Background(), roomId, policy)
+	if err != nil {
+		log.Printf("Error setting booking policy: %v", err)
+		return
+	}
+	fmt.Printf("Booking policy of %s is now: %s\n", roomId, updated)
+}
+
+func listPlaces(graphHelper *graphhelper.GraphHelper) {
+
+	var placeType string
+	fmt.Printf("Enter the place type %v:\n", graphhelper.PlaceTypes)
+	_, err := fmt.Scanf("%s", &placeType)
+	if err != nil {
+		log.Printf("Error reading place type: %v", err)
+		return
+	}
+
+	graphHelper.ListPlaces(os.Stdout, strings.ToLower(placeType))
+}
+
+// chooseRoom lists the rooms in the tenant and makes the chosen one the active room.
+func chooseRoom(graphHelper *graphhelper.GraphHelper) {
+
+	rooms, err := graphHelper.GetRooms()
+	if err != nil {
+		log.Printf("Error listing rooms: %v", err)
+		return
+	}
+	if len(rooms) == 0 {
+		fmt.Println("No rooms found")
+		return
+	}
+
+	fmt.Println("Choose a room:")
+	for i, room := range rooms {
+		capacity := "-"
+		if room.GetCapacity() != nil {
+			capacity = fmt.Sprintf("%d", *room.GetCapacity())
+		}
+		fmt.Printf("  %d.  %s [%s] (capacity %s)\n", i+1,
+			graphhelper.StringOrDefault(room.GetDisplayName(), "(unknown)"),
+			graphhelper.StringOrDefault(room.GetEmailAddress(), "-"),
+			capacity)
+	}
+	fmt.Print(":> ")
+
+	var choice int
+	_, err = fmt.Scanf("%d", &choice)
+	if err != nil || choice < 1 || choice > len(rooms) {
+		fmt.Println("Invalid choice, active room unchanged")
+		return
+	}
+
+	roomEmail := rooms[choice-1].GetEmailAddress()
+	if roomEmail == nil {
+		fmt.Println("That room has no email address, active room unchanged")
+		return
+	}
+	graphHelper.SetRoomEmail(*roomEmail)
+	fmt.Printf("Active room is now %s\n", *roomEmail)
+}
+
+func listRoomBookingsAsOrganiser(graphHelper *graphhelper.GraphHelper, live *liveBookings) {
+
+	organiser := graphHelper.Config().OrganiserEmail
+
+	graphHelper.PrintRoom7DaysBookings(os.Stdout, organiser)
+	live.watch(organiser)
+
+}
+
+func listRoomBookingsAsRoom(graphHelper *graphhelper.GraphHelper, live *liveBookings) {
+
+	roomEmail := graphHelper.Config().RoomEmail
+
+	graphHelper.PrintRoom7DaysBookings(os.Stdout, roomEmail)
+	live.watch(roomEmail)
+
+}
+
+// handleGraphSubscription echoes Graph's validation token and queues notifications for
+// processNotification, answering without waiting for them. When clientState is set,
+// notifications that do not all carry it are rejected.
+func handleGraphSubscription(w http.ResponseWriter, r *http.Request, queue *notificationQueue, clientState string) {
+	if r.Method != "POST" {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to read request body"})
+		return
+	}
+
+	// Check if this is a validation request, Graph requires the token echoed back as plain text
+	if r.URL.Query().Get("validationToken") != "" {
+		validationToken := r.URL.Query().Get("validationToken")
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(validationToken))
+		log.Println("Validation token sent back to Microsoft Graph:", validationToken)
+		return
+	}
+
+	// If not a validation request, this is likely an event notification
+	if clientState != "" && !clientStateMatches(body, clientState) {
+		log.Printf("Rejected notification with the wrong clientState: %s", string(body))
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": "Invalid clientState"})
+		return
+	}
+	// Graph retries notifications that are not answered quickly, so answer before processing
+	queue.enqueue(body)
+	writeJSON(w, http.StatusOK, map[string]string{"status": "received"})
+}
+
+// processNotification logs a notification, decrypting any rich notifications, and passes it
+// on to the live refresh and the notification log.
+func processNotification(body []byte, live *liveBookings, notifications *notificationLog, certificate *graphhelper.NotificationCertificate) {
+	log.Printf("Received notification: %s", string(body))
+	if certificate != nil {
+		logRichNotifications(body, certificate)
+	}
+	live.notify(body)
+	if err := notifications.append(body); err != nil {
+		log.Printf("Error writing notification log: %v", err)
+	}
+}
+
+// clientStateMatches reports whether every notification in body carries the expected client state.
+func clientStateMatches(body []byte, clientState string) bool {
+	var notification struct {
+		Value []struct {
+			ClientState string `json:"clientState"`
+		} `json:"value"`
+	}
+	if err := json.Unmarshal(body, &notification); err != nil || len(notification.Value) == 0 {
+		return false
+	}
+	for _, value := range notification.Value {
+		if subtle.ConstantTimeCompare([]byte(value.ClientState), []byte(clientState)) != 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// logRichNotifications decrypts and logs the events carried by rich notifications.
+// Notifications without encrypted content, or that fail to decrypt, are only reported.
+func logRichNotifications(body []byte, certificate *graphhelper.NotificationCertificate) {
+	var notification struct {
+		Value []struct {
+			ChangeType       string                        `json:"changeType"`
+			EncryptedContent *graphhelper.EncryptedContent `json:"encryptedContent"`
+		} `json:"value"`
+	}
+	if err := json.Unmarshal(body, &notification); err != nil {
+		return
+	}
+
+	for _, value := range notification.Value {
+		if value.EncryptedContent == nil {
+			continue
+		}
+		content, err := certificate.Decrypt(*value.EncryptedContent)
+		if err != nil {
+			log.Printf("Error decrypting rich notification: %v", err)
+			continue
+		}
+		log.Printf("Rich notification (%s): %s", value.ChangeType, string(content))
+	}
+}
+
+// writeJSON writes value as a JSON response with the given status code.
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		log.Printf("Error writing response: %v", err)
+	}
+}
+
+func createOneDaySubscription(graphHelper *graphhelper.GraphHelper) {
+	roomEmail := graphHelper.Config().RoomEmail
+
+	fmt.Println("Subscribe to 1. the room [" + roomEmail + "] or 2. a Microsoft 365 group's calendar? (1/2, default 1):")
+	choice, err := readLine()
+	if err != nil {
+		log.Printf("Error reading choice: %v", err)
+		return
+	}
+	groupId := ""
+	switch strings.TrimSpace(choice) {
+	case "", "1":
+	case "2":
+		fmt.Println("Enter the group's object id:")
+		groupId, err = readLine()
+		if err != nil {
+			log.Printf("Error reading group id: %v", err)
+			return
+		}
+	default:
+		fmt.Println("Invalid choice")
+		return
+	}
+
+	fmt.Println("Renew an existing subscription if there is one? (Y/n, n creates a new one anyway):")
+	answer, err := readLine()
+	if err != nil {
+		log.Printf("Error reading answer: %v", err)
+		return
+	}
+	force := strings.EqualFold(strings.TrimSpace(answer), "n")
+
+	var subscriptionId string
+	if groupId != "" {
+		subscriptionId, err = graphHelper.CreateGroupEventsSubscription(context.Background(), groupId, force)
+	} else {
+		subscriptionId, err = graphHelper.CreateRoomSubscription(roomEmail, force)
+	}
+	if err != nil {
+		log.Printf("Error creating subscription: %v", err)
+		return
+	}
+	fmt.Printf("SubscriptionId: %s\n", subscriptionId)
+}
+
+// subscribeAllRooms creates or renews a subscription for every room, reports which rooms
+// failed, and offers to retry just those.
+func subscribeAllRooms(graphHelper *graphhelper.GraphHelper) {
+
+	rooms, err := graphHelper.GetRooms()
+	if err != nil {
+		log.Printf("Error listing rooms: %v", err)
+		return
+	}
+	var emails []string
+	for _, room := range rooms {
+		if room.GetEmailAddress() != nil {
+			emails = append(emails, *room.GetEmailAddress())
+		}
+	}
+
+	for len(emails) > 0 {
+		summary := graphhelper.ForEachRoom(emails, func(email string) error {
+			_, err := graphHelper.CreateRoomSubscription(email, false)
+			return err
+		})
+		for _, result := range summary.Results {
+			if result.Err != nil {
+				fmt.Printf("%s: failed: %v\n", result.Room, result.Err)
+			} else {
+				fmt.Printf("%s: subscribed\n", result.Room)
+			}
+		}
+		fmt.Printf("Subscribed %d of %d rooms\n", len(summary.Succeeded()), len(summary.Results))
+
+		emails = summary.Failed()
+		if len(emails) == 0 {
+			return
+		}
+		fmt.Printf("Retry the %d failed rooms? (y/N):\n", len(emails))
+		answer, err := readLine()
+		if err != nil || !strings.EqualFold(strings.TrimSpace(answer), "y") {
+			return
+		}
+	}
+	fmt.Println("No rooms with an email address found")
+}
+
+// checkSubscriptions logs a warning for each subscription close to expiry and each expected
+// resource without a subscription. With automatic renewal on, expiring subscriptions are renewed
+// straight away rather than waiting for the next renewal.
+func checkSubscriptions(graphHelper *graphhelper.GraphHelper) {
+	config := graphHelper.Config()
+	warnings, err := graphHelper.CheckSubscriptions(graphHelper.ExpectedSubscriptionResources(), config.SubscriptionExpiryWarning)
+	if err != nil {
+		log.Printf("Subscription health check: %v", err)
+		return
+	}
+
+	expiring := 0
+	for _, warning := range warnings {
+		log.Printf("Subscription health check: %s", warning)
+		if !warning.Missing() {
+			expiring++
+		}
+	}
+	if expiring > 0 && config.SubscriptionRenewInterval > 0 {
+		if _, err := graphHelper.RenewAllSubscriptions(graphhelper.MaxSubscriptionLifetime); err != nil {
+			log.Printf("Automatic subscription renewal: %v", err)
+		}
+	}
+}
+
+func renewAllSubscriptions(graphHelper *graphhelper.GraphHelper) {
+
+	var hours int
+	fmt.Printf("Enter how many hours from now the subscriptions should last (max %.0f):\n", graphhelper.MaxSubscriptionLifetime.Hours())
+	_, err := fmt.Scanf("%d", &hours)
+	if err != nil || hours <= 0 {
+		log.Printf("Error reading hours: %v", err)
+		return
+	}
+
+	renewed, err := graphHelper.RenewAllSubscriptions(time.Duration(hours) * time.Hour)
+	if err != nil {
+		log.Printf("Error renewing subscriptions: %v", err)
+	}
+	fmt.Printf("Renewed %d subscriptions\n", renewed)
+}
+
+func testEndpoint(graphHelper *graphhelper.GraphHelper) {
+	endpoint := graphHelper.Config().Endpoint
+	fmt.Printf("Sending a validation request to %s...\n", endpoint)
+
+	client := &http.Client{Timeout: endpointValidationTimeout}
+	latency, err := checkEndpoint(client, endpoint)
+	if err != nil {
+		fmt.Printf("Endpoint test failed: %v\n", err)
+		return
+	}
+	fmt.Printf("Endpoint echoed the validation token in %v\n", latency.Round(time.Millisecond))
+}
+
+// checkSubscription asks for a subscription id and shows whether Graph still has it, and when
+// it expires.
+func checkSubscription(graphHelper *graphhelper.GraphHelper) {
+
+	promptInput("Enter the subscription id to check (blank to cancel):", func(subscriptionId string) {
+		subscription, err := graphHelper.GetSubscription(context.Background(), subscriptionId)
+		if errors.Is(err, graphhelper.ErrSubscriptionNotFound) {
+			fmt.Printf("Subscription %s is gone: it expired, was deleted or was dropped by Graph\n", subscriptionId)
+			return
+		}
+		if err != nil {
+			log.Printf("Error checking subscription: %v", err)
+			return
+		}
+
+		printSubscription(graphHelper, subscription)
+		graphHelper.WriteRawJSON(os.Stdout, subscription)
+		if expires := subscription.GetExpirationDateTime(); expires != nil {
+			if remaining := time.Until(*expires); remaining > 0 {
+				fmt.Printf("Active, expires in %s\n", remaining.Round(time.Minute))
+				return
+			}
+			fmt.Println("Expired, Graph will remove it shortly")
+			return
+		}
+		fmt.Println("Active")
+	})
+}
+
+func deleteSubscription(graphHelper *graphhelper.GraphHelper) {
+
+	promptInput("Enter the subscription id to delete (blank to cancel):", func(subscriptionId string) {
+		if !confirm("Delete subscription " + subscriptionId + "?") {
+			return
+		}
+		if err := graphHelper.DeleteSubscription(subscriptionId); err != nil {
+			log.Printf("Error deleting subscription: %v", err)
+			return
+		}
+		fmt.Printf("Deleted subscription %s\n", subscriptionId)
+	})
+}
+
+// deleteAllSubscriptions deletes every subscription once confirmed, resuming from the progress
+// file an earlier run left behind.
+func deleteAllSubscriptions(graphHelper *graphhelper.GraphHelper) {
+
+	path := graphhelper.DefaultDeletionProgressFile
+	if _, err := os.Stat(path); err == nil {
+		fmt.Printf("%s is left from an earlier run, the subscriptions it lists are skipped\n", path)
+	}
+	if !confirm("Delete every subscription the app can see?") {
+		fmt.Println("Nothing deleted")
+		return
+	}
+
+	deleted, err := graphHelper.DeleteAllSubscriptions(context.Background(), os.Stdout, path)
+	fmt.Printf("Deleted %d subscriptions\n", deleted)
+	if errors.Is(err, graphhelper.ErrThrottled) {
+		fmt.Println("Graph is throttling the app, wait a few minutes and choose this option again to carry on")
+		return
+	}
+	if err != nil {
+		log.Printf("Error deleting subscriptions: %v", err)
+	}
+}
+
+func deleteEventByOrganiser(graphHelper *graphhelper.GraphHelper) {
+	deleteEventById(graphHelper, graphHelper.Config().OrganiserEmail)
+}
+
+func deleteEventByRoom(graphHelper *graphhelper.GraphHelper) {
+	deleteEventById(graphHelper, graphHelper.Config().RoomEmail)
+}
+
+// deleteEventById asks for an event id in the mailbox's calendar and, once confirmed, cancels it.
+func deleteEventById(graphHelper *graphhelper.GraphHelper, mailbox string) {
+
+	promptInput("Enter the event id to cancel (blank to cancel):", func(eventId string) {
+		if !confirm("Cancel event " + eventId + " in " + mailbox + "?") {
+			return
+		}
+		if err := graphHelper.DeleteEvent(mailbox, eventId); err != nil {
+			log.Printf("Error canceling event: %v", err)
+			return
+		}
+		fmt.Printf("Cancelled event %s\n", eventId)
+	})
+}
+
+// deleteRoomEventsInRange lists the room's events between two dates and, once confirmed, deletes them all.
+func deleteRoomEventsInRange(graphHelper *graphhelper.GraphHelper) {
+
+	roomEmail := graphHelper.Config().RoomEmail
+	timeZone := graphHelper.Config().TimeZone
+
+	var startValue, endValue string
+	fmt.Println("Enter the local start of the range (YYYY-MM-DDTHH:MM):")
+	if _, err := fmt.Scanf("%s", &startValue); err != nil {
+		log.Printf("Error reading start time: %v", err)
+		return
+	}
+	start, err := time.ParseInLocation(graphhelper.InputLayout, startValue, timeZone)
+	if err != nil {
+		log.Printf("Error parsing start time: %v", err)
+		return
+	}
+	fmt.Println("Enter the local end of the range (YYYY-MM-DDTHH:MM):")
+	if _, err := fmt.Scanf("%s", &endValue); err != nil {
+		log.Printf("Error reading end time: %v", err)
+		return
+	}
+	end, err := time.ParseInLocation(graphhelper.InputLayout, endValue, timeZone)
+	if err != nil {
+		log.Printf("Error parsing end time: %v", err)
+		return
+	}
+
+	// list what would go before deleting anything
+	count, err := graphHelper.DeleteRoomEventsInRange(context.Background(), os.Stdout, roomEmail, start, end, true)
+	if err != nil {
+		log.Printf("Error listing events: %v", err)
+		return
+	}
+	if count == 0 {
+		fmt.Println("No events found")
+		return
+	}
+	fmt.Printf("Delete these %d events from %s? (y/N):\n", count, roomEmail)
+	answer, err := readLine()
+	if err != nil || !strings.EqualFold(strings.TrimSpace(answer), "y") {
+		fmt.Println("Nothing deleted")
+		return
+	}
+
+	deleted, err := graphHelper.DeleteRoomEventsInRange(context.Background(), os.Stdout, roomEmail, start, end, false)
+	if err != nil {
+		log.Printf("Error deleting events: %v", err)
+	}
+	fmt.Printf("Deleted %d events\n", deleted)
+}
+
+func createEventByOrganiser(graphHelper *graphhelper.GraphHelper) {
+
+	organiser := graphHelper.Config().OrganiserEmail
+
+	roomEmail := graphHelper.Config().RoomEmail
+
+	var startValue string
+	fmt.Println("Enter the local start time (YYYY-MM-DDTHH:MM):")
+	_, err := fmt.Scanf("%s", &startValue)
+	if err != nil {
+		log.Printf("Error reading start time: %v", err)
+		return
+	}
+	start, err := time.ParseInLocation(graphhelper.InputLayout, startValue, graphHelper.Config().TimeZone)
+	if err != nil {
+		log.Printf("Error parsing start time: %v", err)
+		return
+	}
+
+	fmt.Println("Enter the attendees, e.g. alice@example.com:optional, bob@example.com (empty for none):")
+	attendeeList, err := readLine()
+	if err != nil {
+		log.Printf("Error reading attendees: %v", err)
+		return
+	}
+	var attendees []graphhelper.Attendee
+	if strings.TrimSpace(attendeeList) != "" {
+		attendees, err = graphhelper.ParseAttendees(attendeeList)
+		if err != nil {
+			log.Printf("Error reading attendees: %v", err)
+			return
+		}
+	}
+
+	// the settings give the subject and length unless others are typed
+	config := graphHelper.Config()
+	fmt.Printf("Enter the subject (blank for %q):\n", config.EventSubject)
+	subject, err := readLine()
+	if err != nil {
+		log.Printf("Error reading subject: %v", err)
+		return
+	}
+	if subject = strings.TrimSpace(subject); subject == "" {
+		subject = config.EventSubject
+	}
+	fmt.Printf("Enter the length in minutes (blank for %d):\n", int(config.EventDuration.Minutes()))
+	lengthValue, err := readLine()
+	if err != nil {
+		log.Printf("Error reading length: %v", err)
+		return
+	}
+	duration := config.EventDuration
+	if lengthValue = strings.TrimSpace(lengthValue); lengthValue != "" {
+		minutes, err := strconv.Atoi(lengthValue)
+		if err != nil || minutes <= 0 {
+			log.Printf("Error reading length: %q is not a positive number of minutes", lengthValue)
+			return
+		}
+		duration = time.Duration(minutes) * time.Minute
+	}
+
+	calendarId := chooseCalendar(graphHelper, organiser)
+
+	event, err := graphHelper.CreateOrganiserEvent(roomEmail, calendarId, subject, start, start.Add(duration), attendees)
+	if err != nil {
+		log.Printf("Error creating event: %v", err)
+		return
+	}
+	if event == nil {
+		fmt.Println("The event was created, but Graph returned none of its details")
+		return
+	}
+
+	graphHelper.PrintEvent(event)
+	// time zone handling can move the event away from the times typed
+	graphHelper.CheckEventTimes(event, start, start.Add(duration)).Write(os.Stdout, config.TimeZone, colourOutput())
+
+	// the room is only booked once it accepts the invitation
+	timeout := graphHelper.Config().RoomResponseTimeout
+	if timeout <= 0 || event.GetId() == nil {
+		return
+	}
+	fmt.Printf("Waiting up to %v for %s to respond...\n", timeout, roomEmail)
+	response, err := graphHelper.WaitForRoomResponse(*event.GetId(), roomEmail, timeout)
+	if err != nil {
+		log.Printf("Error checking the room's response: %v", err)
+		return
+	}
+	switch response {
+	case models.ACCEPTED_RESPONSETYPE:
+		fmt.Printf("%s accepted, the room is booked\n", roomEmail)
+	case models.TENTATIVELYACCEPTED_RESPONSETYPE:
+		fmt.Printf("%s tentatively accepted, the room may still be released\n", roomEmail)
+	case models.DECLINED_RESPONSETYPE:
+		fmt.Printf("%s declined, the room is NOT booked\n", roomEmail)
+	default:
+		fmt.Printf("%s has not responded yet, check the event before relying on the booking\n", roomEmail)
+	}
+}
+
+func extendEventByOrganiser(graphHelper *graphhelper.GraphHelper) {
+
+	organiser := graphHelper.Config().OrganiserEmail
+
+	var eventId string
+	fmt.Println("Enter the event id to extend:")
+	_, err := fmt.Scanf("%s", &eventId)
+	if err != nil {
+		log.Printf("Error reading event id: %v", err)
+		return
+	}
+
+	var minutes int
+	fmt.Println("Enter how many minutes to extend it by:")
+	_, err = fmt.Scanf("%d", &minutes)
+	if err != nil || minutes <= 0 {
+		log.Printf("Error reading minutes: %v", err)
+		return
+	}
+
+	end, clamped, err := graphHelper.ExtendEvent(context.Background(), organiser, eventId, time.Duration(minutes)*time.Minute)
+	if err != nil {
+		log.Printf("Error extending event: %v", err)
+		return
+	}
+	localEnd := end.In(graphHelper.Config().TimeZone).Format(graphhelper.DisplayLayout)
+	if clamped {
+		fmt.Printf("Event now ends at %s, when the next booking starts\n", localEnd)
+		return
+	}
+	fmt.Printf("Event now ends at %s\n", localEnd)
+}
+
+// chooseCalendar lists the user's calendar groups and asks which one to open, then which of its
+// calendars to use. Without calendar groups it falls back to listing every calendar at once.
+// It returns an empty string, meaning the primary calendar, when nothing is chosen.
+func chooseCalendar(graphHelper *graphhelper.GraphHelper, userId string) string {
+
+	groups, err := graphHelper.ListCalendarGroups(context.Background(), userId)
+	if err != nil || len(groups) == 0 {
+		if err != nil {
+			log.Printf("Error listing calendar groups, listing every calendar: %v", err)
+		}
+		calendars, err := graphHelper.ListCalendars(userId)
+		if err != nil {
+			log.Printf("Error listing calendars, using the primary calendar: %v", err)
+			return ""
+		}
+		return pickCalendar(calendars)
+	}
+
+	for {
+		fmt.Println("Choose a calendar group:")
+		fmt.Println("  0.  Primary calendar")
+		for i, group := range groups {
+			fmt.Printf("  %d.  %s\n", i+1, graphhelper.StringOrDefault(group.GetName(), "(unnamed)"))
+		}
+		fmt.Print(":> ")
+
+		var choice int
+		_, err = fmt.Scanf("%d", &choice)
+		if err != nil || choice < 1 || choice > len(groups) {
+			return ""
+		}
+		group := groups[choice-1]
+
+		calendars, err := graphHelper.ListGroupCalendars(context.Background(), userId, graphhelper.StringOrDefault(group.GetId(), ""))
+		if err != nil {
+			log.Printf("Error listing calendars, using the primary calendar: %v", err)
+			return ""
+		}
+		if len(calendars) == 0 {
+			fmt.Printf("Calendar group %s has no calendars\n", graphhelper.StringOrDefault(group.GetName(), "(unnamed)"))
+			continue
+		}
+		return pickCalendar(calendars)
+	}
+}
+
+// pickCalendar asks which of the calendars to use, returning an empty string for the primary calendar.
+func pickCalendar(calendars []models.Calendarable) string {
+	fmt.Println("Choose a calendar:")
+	fmt.Println("  0.  Primary calendar")
+	for i, calendar := range calendars {
+		fmt.Printf("  %d.  %s\n", i+1, graphhelper.StringOrDefault(calendar.GetName(), "(unnamed)"))
+	}
+	fmt.Print(":> ")
+
+	var choice int
+	_, err := fmt.Scanf("%d", &choice)
+	if err != nil || choice < 1 || choice > len(calendars) {
+		return ""
+	}
+	return graphhelper.StringOrDefault(calendars[choice-1].GetId(), "")
+}
+
+func respondToEventByRoom(graphHelper *graphhelper.GraphHelper) {
+
+	roomEmail := graphHelper.Config().RoomEmail
+
+	var eventId string
+	fmt.Println("Enter the event id to respond to:")
+	_, err := fmt.Scanf("%s", &eventId)
+	if err != nil {
+		log.Printf("Error reading event id: %v", err)
+		return
+	}
+
+	var response string
+	fmt.Printf("Enter the response %v:\n", graphhelper.EventResponses)
+	_, err = fmt.Scanf("%s", &response)
+	if err != nil {
+		log.Printf("Error reading response: %v", err)
+		return
+	}
+
+	err = graphHelper.RespondToEvent(roomEmail, eventId, response, "")
+	if err != nil {
+		log.Printf("Error responding to event: %v", err)
+		return
+	}
+	fmt.Printf("Sent %s for event %s\n", response, eventId)
+}
+
+// saveListing asks for a file and a listing, then runs the listing with its output also written to the file.
+// The bookings listings can instead be written as JSON or CSV.
+func saveListing(graphHelper *graphhelper.GraphHelper, live *liveBookings) {
+
+	listings := map[int64]func(){
+		2: func() { listUsers(graphHelper) },
+		3: func() { listSubscriptions(graphHelper) },
+		4: func() { listRooms(graphHelper) },
+		5: func() { listRoomBookingsAsRoom(graphHelper, live) },
+		6: func() { listRoomBookingsAsOrganiser(graphHelper, live) },
+	}
+
+	var path string
+	fmt.Println("Enter the file to write to:")
+	_, err := fmt.Scanf("%s", &path)
+	if err != nil {
+		log.Printf("Error reading file path: %v", err)
+		return
+	}
+
+	var choice int64
+	fmt.Println("Enter the listing to save (2-6):")
+	_, err = fmt.Scanf("%d", &choice)
+	listing, ok := listings[choice]
+	if err != nil || !ok {
+		fmt.Println("Invalid choice! Nothing saved.")
+		return
+	}
+
+	// the bookings can also be saved as data rather than as the text shown
+	mailboxes := map[int64]string{5: graphHelper.Config().RoomEmail, 6: graphHelper.Config().OrganiserEmail}
+	if mailbox, ok := mailboxes[choice]; ok {
+		fmt.Printf("Enter the format, text or one of %v (blank for text):\n", graphhelper.EventFormats)
+		format, err := readLine()
+		if err != nil {
+			log.Printf("Error reading format: %v", err)
+			return
+		}
+		if format = strings.ToLower(strings.TrimSpace(format)); format != "" && format != "text" {
+			events, err := graphHelper.ExportRoom7DaysBookings(path, mailbox, format)
+			if err != nil {
+				log.Printf("Error exporting bookings: %v", err)
+				return
+			}
+			fmt.Printf("Wrote %d events to %s\n", events, path)
+			return
+		}
+	}
+
+	written, err := captureOutput(path, listing)
+	if err != nil {
+		log.Printf("Error writing %s: %v", path, err)
+		return
+	}
+	fmt.Printf("Wrote %d bytes to %s\n", written, path)
+}
+
+func findRoomEvents(graphHelper *graphhelper.GraphHelper) {
+
+	roomEmail := graphHelper.Config().RoomEmail
+
+	promptInput("Enter the text to find in the subject (blank to cancel):", func(search string) {
+		now := time.Now()
+		events, err := graphHelper.FindRoomEvents(roomEmail, search, now, now.Add(30*24*time.Hour))
+		if err != nil {
+			log.Printf("Error finding events: %v", err)
+			return
+		}
+		if len(events) == 0 {
+			fmt.Printf("No events found with %q in the subject in the next 30 days\n", search)
+			return
+		}
+
+		for _, event := range events {
+			graphHelper.PrintEvent(event)
+		}
+		fmt.Println()
+		fmt.Printf("Found %d events, use the delete options to remove one by id\n", len(events))
+	})
+}
+
+func showMailboxSettings(graphHelper *graphhelper.GraphHelper) {
+
+	roomEmail := graphHelper.Config().RoomEmail
+
+	settings, err := graphHelper.GetMailboxSettings(roomEmail)
+	if err != nil {
+		log.Printf("Error getting mailbox settings: %v", err)
+		return
+	}
+
+	fmt.Printf("Mailbox: %s\n", roomEmail)
+	fmt.Printf("  Time zone: %s\n", graphhelper.StringOrDefault(settings.GetTimeZone(), "(not set)"))
+
+	workingHours := settings.GetWorkingHours()
+	if workingHours == nil {
+		fmt.Println("  Working hours: (not set)")
+	} else {
+		var days []string
+		for _, day := range workingHours.GetDaysOfWeek() {
+			days = append(days, day.String())
+		}
+		start, end := "-", "-"
+		if workingHours.GetStartTime() != nil {
+			start = workingHours.GetStartTime().String()
+		}
+		if workingHours.GetEndTime() != nil {
+			end = workingHours.GetEndTime().String()
+		}
+		zone := "-"
+		if workingHours.GetTimeZone() != nil {
+			zone = graphhelper.StringOrDefault(workingHours.GetTimeZone().GetName(), "-")
+		}
+		fmt.Printf("  Working hours: %s %s-%s (%s)\n", strings.Join(days, ","), start, end, zone)
+	}
+
+	replies := settings.GetAutomaticRepliesSetting()
+	if replies == nil || replies.GetStatus() == nil {
+		fmt.Println("  Automatic replies: (not set)")
+	} else {
+		fmt.Printf("  Automatic replies: %s\n", replies.GetStatus().String())
+	}
+}
+
+func findMeetingTimes(graphHelper *graphhelper.GraphHelper) {
+
+	organiser := graphHelper.Config().OrganiserEmail
+
+	roomEmail := graphHelper.Config().RoomEmail
+
+	fmt.Println("Enter the attendee emails, separated by commas (empty for none):")
+	attendeeList, err := readLine()
+	if err != nil {
+		log.Printf("Error reading attendees: %v", err)
+		return
+	}
+
+	var minutes int
+	fmt.Println("Enter the meeting length in minutes:")
+	_, err = fmt.Scanf("%d", &minutes)
+	if err != nil || minutes <= 0 {
+		log.Printf("Error reading meeting length: %v", err)
+		return
+	}
+
+	var attendees []string
+	for _, attendee := range strings.Split(attendeeList, ",") {
+		if attendee = strings.TrimSpace(attendee); attendee != "" {
+			attendees = append(attendees, attendee)
+		}
+	}
+
+	now := time.Now()
+	result, err := graphHelper.FindMeetingTimes(organiser, attendees, roomEmail, now, now.Add(7*24*time.Hour), time.Duration(minutes)*time.Minute)
+	if err != nil {
+		log.Printf("Error finding meeting times: %v", err)
+		return
+	}
+
+	suggestions := result.GetMeetingTimeSuggestions()
+	if len(suggestions) == 0 {
+		fmt.Printf("No suitable time found: %s\n", graphhelper.StringOrDefault(result.GetEmptySuggestionsReason(), "no reason given"))
+		return
+	}
+
+	for _, suggestion := range suggestions {
+		slot := suggestion.GetMeetingTimeSlot()
+		if slot == nil || slot.GetStart() == nil || slot.GetEnd() == nil {
+			continue
+		}
+		start := graphhelper.StringOrDefault(slot.GetStart().GetDateTime(), "-")
+		end := graphhelper.StringOrDefault(slot.GetEnd().GetDateTime(), "-")
+		if localStart, err := graphHelper.LocalTime(start); err == nil {
+			start = localStart.Format(graphhelper.DisplayLayout)
+		}
+		if localEnd, err := graphHelper.LocalTime(end); err == nil {
+			end = localEnd.Format(graphhelper.TimeOfDayLayout)
+		}
+		confidence := 0.0
+		if suggestion.GetConfidence() != nil {
+			confidence = *suggestion.GetConfidence()
+		}
+		fmt.Printf("%s - %s  confidence %.0f%%  %s\n", start, end, confidence,
+			graphhelper.StringOrDefault(suggestion.GetSuggestionReason(), ""))
+	}
+}
+
+// syncRoomEvents prints the room's events that were added, updated or removed since the
+// last time, or every event in the next 30 days the first time.
+func syncRoomEvents(graphHelper *graphhelper.GraphHelper) {
+
+	roomEmail := graphHelper.Config().RoomEmail
+
+	deltaToken := graphHelper.RoomDeltaToken(roomEmail)
+	changes, err := graphHelper.GetRoomEventsDelta(context.Background(), roomEmail, deltaToken)
+	if err != nil && deltaToken != "" {
+		// tokens expire, start tracking again from scratch
+		log.Printf("Error getting changes, starting over: %v", err)
+		deltaToken = ""
+		changes, err = graphHelper.GetRoomEventsDelta(context.Background(), roomEmail, deltaToken)
+	}
+	if err != nil {
+		log.Printf("Error getting changes: %v", err)
+		return
+	}
+	if deltaToken != "" && len(changes.Changed) == 0 && len(changes.RemovedIds) == 0 {
+		fmt.Println("No changes since the last time")
+		return
+	}
+
+	for _, event := range changes.Changed {
+		graphHelper.PrintEvent(event)
+	}
+	for _, id := range changes.RemovedIds {
+		fmt.Printf("Removed Event Id : %s\n", id)
+	}
+	fmt.Println()
+	if deltaToken == "" {
+		fmt.Printf("Tracking changes to %s, found %d events in the next 30 days\n", roomEmail, len(changes.Changed))
+		return
+	}
+	fmt.Printf("%d events added or updated, %d removed since the last time\n", len(changes.Changed), len(changes.RemovedIds))
+}
+
+// listRoomDeclines lists the bookings the room declined from 7 days ago to 30 days ahead,
+// found in its own calendar and in the organiser's.
+func listRoomDeclines(graphHelper *graphhelper.GraphHelper) {
+
+	config := graphHelper.Config()
+	now := time.Now()
+	declines, err := graphHelper.GetRoomDeclines(config.RoomEmail, []string{config.OrganiserEmail}, now.Add(-7*24*time.Hour), now.Add(30*24*time.Hour))
+	if err != nil {
+		log.Printf("Error listing declined bookings: %v", err)
+		return
+	}
+	if len(declines) == 0 {
+		fmt.Println("No declined bookings found")
+		return
+	}
+
+	for _, item := range declines {
+		start := item.When(config.TimeZone)
+		organiser := graphHelper.NewEventSummary(item.Event).Organiser
+		if organiser == "" {
+			organiser = "-"
+		}
+		fmt.Printf("%s  %s  organised by %s\n", start,
+			graphhelper.StringOrDefault(item.Event.GetSubject(), "(no subject)"), organiser)
+	}
+	fmt.Println()
+	fmt.Printf("%d bookings declined by %s\n", len(declines), config.RoomEmail)
+}
+
+// listRecurringSeries lists the recurring series booking the room in the next 30 days with
+// their occurrences, and offers to cancel one of the occurrences.
+func listRecurringSeries(graphHelper *graphhelper.GraphHelper) {
+
+	roomEmail := graphHelper.Config().RoomEmail
+	now := time.Now()
+	calendar, err := graphHelper.GetRecurringSeries(context.Background(), roomEmail, now, now.Add(30*24*time.Hour))
+	if err != nil {
+		log.Printf("Error listing recurring series: %v", err)
+		return
+	}
+	calendar.WriteText(os.Stdout)
+	instances := calendar.Instances()
+	if len(instances) == 0 {
+		return
+	}
+
+	fmt.Println()
+	promptInput(fmt.Sprintf("Enter the number of an occurrence to cancel, 1 to %d (blank to skip):", len(instances)), func(value string) {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > len(instances) {
+			fmt.Printf("%q is not a number from 1 to %d\n", value, len(instances))
+			return
+		}
+		instance := instances[n-1]
+		if !confirm(fmt.Sprintf("Cancel the occurrence at %s, leaving the rest of the series?", instance.DisplayTime())) {
+			fmt.Println("Cancelled")
+			return
+		}
+		notified, err := graphHelper.CancelInstance(context.Background(), roomEmail, instance.Id, "")
+		reportCancelledOccurrence(roomEmail, notified, err)
+	})
+}
+
+// resolveUserId prints the object id of the room or user with the email.
+func resolveUserId(graphHelper *graphhelper.GraphHelper, email string) {
+	id, err := graphHelper.ResolveUserId(context.Background(), email)
+	if errors.Is(err, graphhelper.ErrUserNotFound) {
+		fmt.Printf("No user or room has the email %s\n", email)
+		return
+	}
+	if err != nil {
+		log.Printf("Error looking up %s: %v", email, err)
+		return
+	}
+	fmt.Printf("%s: %s\n", email, id)
+	graphHelper.SetLastId(&id)
+}
+
+// showPhoto draws the photo of a room or user with sixel graphics, or describes it on terminals
+// without them. Photos are large, so the option is only offered with "SHOW_PHOTOS" set.
+func showPhoto(graphHelper *graphhelper.GraphHelper) {
+
+	config := graphHelper.Config()
+	if !config.ShowPhotos {
+		fmt.Println("Photos are off, set SHOW_PHOTOS=true to show them")
+		return
+	}
+
+	fmt.Printf("Enter the email of the room or user (blank for %s):\n", config.RoomEmail)
+	userId, err := readLine()
+	if err != nil {
+		log.Printf("Error reading email: %v", err)
+		return
+	}
+	if userId = strings.TrimSpace(userId); userId == "" {
+		userId = config.RoomEmail
+	}
+
+	photo, err := graphHelper.GetPhoto(context.Background(), userId)
+	if errors.Is(err, graphhelper.ErrNoPhoto) {
+		fmt.Printf("%s has no photo\n", userId)
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting photo: %v", err)
+		return
+	}
+
+	if sixelSupported() {
+		err := graphhelper.WriteSixel(os.Stdout, photo, graphhelper.PhotoWidth)
+		if err == nil {
+			fmt.Println()
+			return
+		}
+		log.Printf("Error drawing photo: %v", err)
+	}
+	graphhelper.WritePhotoPlaceholder(os.Stdout, photo)
+}
+
+// listFilteredRoomEvents lists the room's events in the next 7 days that match the filter.
+func listFilteredRoomEvents(graphHelper *graphhelper.GraphHelper, filter graphhelper.EventFilter) {
+
+	roomEmail := graphHelper.Config().RoomEmail
+	now := time.Now()
+	events, err := graphHelper.GetFilteredCalendarView(roomEmail, now, now.Add(7*24*time.Hour), filter)
+	if err != nil {
+		log.Printf("Error listing %s: %v", filter, err)
+		return
+	}
+	if len(events) == 0 {
+		fmt.Printf("No %s found for %s in the next 7 days\n", filter, roomEmail)
+		return
+	}
+
+	for _, event := range events {
+		graphHelper.PrintEvent(event)
+	}
+	fmt.Println()
+	fmt.Printf("%d %s\n", len(events), filter)
+}
+
+// listOrganiserInvitations lists the meetings the organiser sent in the next 7 days, with the
+// response of each room invited to them.
+func listOrganiserInvitations(graphHelper *graphhelper.GraphHelper) {
+
+	organiser := graphHelper.Config().OrganiserEmail
+	now := time.Now()
+	filter := graphhelper.EventFilter{OnlyOrganised: true}
+	events, err := graphHelper.GetFilteredCalendarView(organiser, now, now.Add(7*24*time.Hour), filter)
+	if err != nil {
+		log.Printf("Error listing %s: %v", filter, err)
+		return
+	}
+	if len(events) == 0 {
+		fmt.Printf("No %s found for %s in the next 7 days\n", filter, organiser)
+		return
+	}
+
+	for _, event := range events {
+		graphHelper.PrintEvent(event)
+		rooms := graphhelper.RoomAttendees(event)
+		if len(rooms) == 0 {
+			fmt.Println("  Rooms: none invited")
+		}
+		for _, room := range rooms {
+			fmt.Printf("  Room: %s: %s\n", room.Email, room.Response)
+		}
+	}
+	fmt.Println()
+	fmt.Printf("%d meetings sent by %s\n", len(events), organiser)
+}
+
+// compareRoomAvailability shows the free/busy of the active room and another one side by side,
+// for working hours on a day, with the times both are free.
+func compareRoomAvailability(graphHelper *graphhelper.GraphHelper) {
+
+	config := graphHelper.Config()
+	promptInput("Enter the email of the room to compare with (blank to cancel):", func(otherRoom string) {
+		fmt.Println("Enter the day to compare (YYYY-MM-DD, blank for today):")
+		dayValue, err := readLine()
+		if err != nil {
+			log.Printf("Error reading day: %v", err)
+			return
+		}
+		day := time.Now().In(config.TimeZone)
+		if dayValue = strings.TrimSpace(dayValue); dayValue != "" {
+			if day, err = time.ParseInLocation("2006-01-02", dayValue, config.TimeZone); err != nil {
+				log.Printf("Error parsing day: %v", err)
+				return
+			}
+		}
+
+		start := time.Date(day.Year(), day.Month(), day.Day(), compareFromHour, 0, 0, 0, config.TimeZone)
+		end := time.Date(day.Year(), day.Month(), day.Day(), compareToHour, 0, 0, 0, config.TimeZone)
+		comparison, err := graphHelper.CompareRoomAvailability(context.Background(), config.RoomEmail, otherRoom, start, end)
+		if err != nil {
+			log.Printf("Error comparing availability: %v", err)
+			return
+		}
+		comparison.Write(os.Stdout, config.TimeZone)
+	})
+}
+
+// compareFromHour and compareToHour are the working hours rooms are compared over.
+const (
+	compareFromHour = 8
+	compareToHour   = 18
+)
+
+// browseRoomEvents lists the room's events by number, shows the details of the chosen one
+// and offers to delete, update or respond to it, without copying ids around.
+func browseRoomEvents(graphHelper *graphhelper.GraphHelper) {
+
+	roomEmail := graphHelper.Config().RoomEmail
+
+	now := time.Now()
+	events, err := graphHelper.GetCalendarView(roomEmail, now, now.Add(7*24*time.Hour))
+	if err != nil {
+		log.Printf("Error getting calendar view: %v", err)
+		return
+	}
+	if len(events) == 0 {
+		fmt.Println("No events found")
+		return
+	}
+
+	fmt.Println("Choose an event:")
+	for i, event := range events {
+		summary := graphHelper.NewEventSummary(event)
+		start, subject := summary.DisplayTime(), summary.Subject
+		if subject == "" {
+			subject = "(no subject)"
+		}
+		fmt.Printf("  %d.  %s  %s\n", i+1, start, subject)
+	}
+	fmt.Print(":> ")
+
+	var choice int
+	_, err = fmt.Scanf("%d", &choice)
+	if err != nil || choice < 1 || choice > len(events) {
+		return
+	}
+	event := events[choice-1]
+	graphHelper.PrintEvent(event)
+	graphHelper.WriteRawJSON(os.Stdout, event)
+
+	// the listing may leave out attendees, and a series has occurrences beyond the one chosen
+	details, err := graphHelper.GetEventDetails(context.Background(), roomEmail, graphHelper.NewEventSummary(event).Id, now, now.Add(7*24*time.Hour))
+	if err != nil {
+		log.Printf("Error getting event details: %v", err)
+	} else {
+		details.WriteText(os.Stdout)
+	}
+
+	fmt.Println("  0.  Back")
+	fmt.Println("  1.  Delete")
+	fmt.Println("  2.  Accept")
+	fmt.Println("  3.  Tentatively accept")
+	fmt.Println("  4.  Decline")
+	if details.SeriesId != "" {
+		fmt.Println("  5.  Cancel one occurrence of the series by its date")
+	}
+	fmt.Println("  6.  Update the subject or times")
+	fmt.Print(":> ")
+
+	var action int
+	_, err = fmt.Scanf("%d", &action)
+	if err != nil {
+		return
+	}
+
+	eventId := graphHelper.NewEventSummary(event).Id
+	switch action {
+	case 1:
+		if !confirm("Delete event " + eventId + " from " + roomEmail + "?") {
+			fmt.Println("Cancelled")
+			return
+		}
+		err = graphHelper.DeleteEvent(roomEmail, eventId)
+	case 2:
+		err = graphHelper.RespondToEvent(roomEmail, eventId, "accept", "")
+	case 3:
+		err = graphHelper.RespondToEvent(roomEmail, eventId, "tentativelyAccept", "")
+	case 4:
+		err = graphHelper.RespondToEvent(roomEmail, eventId, "decline", "")
+	case 5:
+		if details.SeriesId != "" {
+			cancelOccurrence(graphHelper, roomEmail, details.SeriesId)
+		}
+		return
+	case 6:
+		updateEvent(graphHelper, roomEmail, eventId)
+		return
+	default:
+		return
+	}
+	if err != nil {
+		log.Printf("Error updating event: %v", err)
+		return
+	}
+	fmt.Println("Done")
+}
+
+// updateEvent asks for a new subject and new local start and end times, each left as it is
+// when blank, and changes the event once confirmed. Only events the mailbox organises can be
+// changed.
+func updateEvent(graphHelper *graphhelper.GraphHelper, userId string, eventId string) {
+	timeZone := graphHelper.Config().TimeZone
+
+	var update graphhelper.EventUpdate
+	fmt.Println("Enter the new subject (blank to leave it):")
+	subject, err := readLine()
+	if err != nil {
+		log.Printf("Error reading subject: %v", err)
+		return
+	}
+	update.Subject = strings.TrimSpace(subject)
+
+	fmt.Println("Enter the new local start time (YYYY-MM-DDTHH:MM, blank to leave the times):")
+	startValue, err := readLine()
+	if err != nil {
+		log.Printf("Error reading start time: %v", err)
+		return
+	}
+	if startValue = strings.TrimSpace(startValue); startValue != "" {
+		if update.Start, err = time.ParseInLocation(graphhelper.InputLayout, startValue, timeZone); err != nil {
+			log.Printf("Error parsing start time: %v", err)
+			return
+		}
+		fmt.Println("Enter the new local end time (YYYY-MM-DDTHH:MM):")
+		endValue, err := readLine()
+		if err != nil {
+			log.Printf("Error reading end time: %v", err)
+			return
+		}
+		if update.End, err = time.ParseInLocation(graphhelper.InputLayout, strings.TrimSpace(endValue), timeZone); err != nil {
+			log.Printf("Error parsing end time: %v", err)
+			return
+		}
+	}
+
+	if update.Subject == "" && update.Start.IsZero() {
+		fmt.Println("Nothing to change")
+		return
+	}
+	if err := update.Validate(); err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	if !confirm("Update event " + eventId + " in " + userId + "?") {
+		fmt.Println("Cancelled")
+		return
+	}
+	updated, err := graphHelper.UpdateEvent(context.Background(), userId, eventId, update)
+	if err != nil {
+		log.Printf("Error updating event: %v", err)
+		return
+	}
+	graphHelper.PrintEvent(updated)
+}
+
+// cancelOccurrence asks for a day and cancels the series' occurrence on it, keeping the rest of the series.
+func cancelOccurrence(graphHelper *graphhelper.GraphHelper, userId string, seriesId string) {
+	config := graphHelper.Config()
+	promptInput("Enter the day of the occurrence (YYYY-MM-DD, blank to cancel):", func(dayValue string) {
+		day, err := time.ParseInLocation("2006-01-02", dayValue, config.TimeZone)
+		if err != nil {
+			log.Printf("Error parsing day: %v", err)
+			return
+		}
+		if !confirm(fmt.Sprintf("Cancel the occurrence on %s, leaving the rest of the series?", day.Format(graphhelper.DateLayout))) {
+			fmt.Println("Cancelled")
+			return
+		}
+		notified, err := graphHelper.CancelOccurrence(context.Background(), userId, seriesId, day)
+		reportCancelledOccurrence(userId, notified, err)
+	})
+}
+
+// reportCancelledOccurrence says how an occurrence was cancelled in the mailbox's calendar.
+func reportCancelledOccurrence(userId string, notified bool, err error) {
+	switch {
+	case err != nil:
+		log.Printf("Error cancelling occurrence: %v", err)
+	case notified:
+		fmt.Println("Occurrence cancelled, the attendees were sent the cancellation")
+	default:
+		fmt.Printf("Occurrence removed from %s's calendar\n", userId)
+	}
+}
+
+// listRoomListAgenda asks for a room list and prints the next 7 days of events of all its rooms,
+// earliest first, followed by any rooms whose calendar could not be read.
+func listRoomListAgenda(graphHelper *graphhelper.GraphHelper) {
+
+	roomLists, err := graphHelper.GetRoomLists()
+	if err != nil {
+		log.Printf("Error listing room lists: %v", err)
+		return
+	}
+	if len(roomLists) == 0 {
+		fmt.Println("No room lists found")
+		return
+	}
+
+	fmt.Println("Choose a room list:")
+	for i, roomList := range roomLists {
+		fmt.Printf("  %d.  %s [%s]\n", i+1,
+			graphhelper.StringOrDefault(roomList.GetDisplayName(), "(unknown)"),
+			graphhelper.StringOrDefault(roomList.GetEmailAddress(), "-"))
+	}
+	fmt.Print(":> ")
+
+	var choice int
+	_, err = fmt.Scanf("%d", &choice)
+	if err != nil || choice < 1 || choice > len(roomLists) {
+		fmt.Println("Invalid choice!")
+		return
+	}
+	roomList := roomLists[choice-1]
+	roomListId := graphhelper.StringOrDefault(roomList.GetEmailAddress(), graphhelper.StringOrDefault(roomList.GetId(), ""))
+
+	now := time.Now()
+	agenda, summary, err := graphHelper.GetRoomListAgenda(roomListId, now, now.Add(7*24*time.Hour))
+	if err != nil {
+		log.Printf("Error getting room list agenda: %v", err)
+		return
+	}
+	if len(agenda) == 0 && len(summary.Succeeded()) > 0 {
+		fmt.Println("No events found in the next 7 days")
+	}
+
+	timeZone := graphHelper.Config().TimeZone
+	for _, item := range agenda {
+		fmt.Printf("%s  %-20s  %s\n", item.When(timeZone), item.RoomName,
+			graphhelper.StringOrDefault(item.Event.GetSubject(), "(no subject)"))
+	}
+	fmt.Println()
+	fmt.Printf("%d events in %d rooms\n", len(agenda), len(summary.Succeeded()))
+	for _, result := range summary.Results {
+		if result.Err != nil {
+			fmt.Printf("  %s could not be read: %v\n", result.Room, result.Err)
+		}
+	}
 }
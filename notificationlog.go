@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// notificationLog appends each webhook notification as one JSON object per line.
+// Writes are serialised, the file is opened in append mode and each delivery is written
+// under an advisory lock, so notifications never interleave within a line, even when
+// several processes share the file.
+type notificationLog struct {
+	mu   sync.Mutex
+	path string
+}
+
+// notificationRecord is the line written for each notification in a delivery.
+type notificationRecord struct {
+	Received                       time.Time `json:"received"`
+	SubscriptionId                 string    `json:"subscriptionId,omitempty"`
+	SubscriptionExpirationDateTime string    `json:"subscriptionExpirationDateTime,omitempty"`
+	ChangeType                     string    `json:"changeType,omitempty"`
+	Resource                       string    `json:"resource,omitempty"`
+	ResourceId                     string    `json:"resourceId,omitempty"`
+	TenantId                       string    `json:"tenantId,omitempty"`
+}
+
+// newNotificationLog returns a log writing to path, or nil when path is empty.
+func newNotificationLog(path string) *notificationLog {
+	if path == "" {
+		return nil
+	}
+	return &notificationLog{path: path}
+}
+
+// append writes a line for every notification in a webhook body. A nil log does nothing.
+func (l *notificationLog) append(body []byte) error {
+	if l == nil {
+		return nil
+	}
+
+	var notification struct {
+		Value []struct {
+			SubscriptionId                 string `json:"subscriptionId"`
+			SubscriptionExpirationDateTime string `json:"subscriptionExpirationDateTime"`
+			ChangeType                     string `json:"changeType"`
+			Resource                       string `json:"resource"`
+			TenantId                       string `json:"tenantId"`
+			ResourceData                   struct {
+				Id string `json:"id"`
+			} `json:"resourceData"`
+		} `json:"value"`
+	}
+	if err := json.Unmarshal(body, &notification); err != nil {
+		return err
+	}
+
+	var lines bytes.Buffer
+	encoder := json.NewEncoder(&lines)
+	received := time.Now().UTC()
+	for _, value := range notification.Value {
+		err := encoder.Encode(notificationRecord{
+			Received:                       received,
+			SubscriptionId:                 value.SubscriptionId,
+			SubscriptionExpirationDateTime: value.SubscriptionExpirationDateTime,
+			ChangeType:                     value.ChangeType,
+			Resource:                       value.Resource,
+			ResourceId:                     value.ResourceData.Id,
+			TenantId:                       value.TenantId,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	file, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := lockFile(file); err != nil {
+		return err
+	}
+	defer unlockFile(file)
+
+	_, err = file.Write(lines.Bytes())
+	return err
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestNotificationLogAppendsOneLinePerNotification(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notifications.log")
+	log := newNotificationLog(path)
+	body := []byte(`{"value":[
+		{"subscriptionId":"sub-1","changeType":"created","resource":"Users/room/Events/1","resourceData":{"id":"1"}},
+		{"subscriptionId":"sub-1","changeType":"deleted","resource":"Users/room/Events/2","resourceData":{"id":"2"}}]}`)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := log.append(body); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer file.Close()
+
+	lines := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record notificationRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("line %d is not a JSON record: %v", lines+1, err)
+		}
+		if record.SubscriptionId != "sub-1" || record.ResourceId == "" {
+			t.Errorf("line %d = %+v, want the notification fields", lines+1, record)
+		}
+		lines++
+	}
+	if lines != 20 {
+		t.Errorf("got %d lines, want 20", lines)
+	}
+}
+
+func TestNotificationLogDisabled(t *testing.T) {
+	if err := newNotificationLog("").append([]byte(`{"value":[]}`)); err != nil {
+		t.Errorf("a disabled log returned %v", err)
+	}
+}
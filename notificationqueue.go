@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+)
+
+// notificationQueue hands accepted webhook notifications to a single worker, so the handler
+// answers Graph straight away instead of waiting for the notifications to be processed. Graph
+// retries notifications that are not acknowledged quickly, so a full queue never blocks the
+// handler: the notification is written to the overflow log instead, or dropped and counted
+// when there is none.
+type notificationQueue struct {
+	bodies   chan []byte
+	pending  sync.WaitGroup
+	done     chan struct{} // closed once the worker has stopped
+	mu       sync.RWMutex  // held to send on bodies, and to close it
+	closed   bool
+	overflow *notificationLog // nil drops notifications that do not fit
+	process  func(body []byte)
+
+	dropped   atomic.Int64
+	persisted atomic.Int64
+}
+
+// newNotificationQueue starts a worker passing each queued notification body to process.
+// size is how many notifications can wait.
+func newNotificationQueue(size int, overflow *notificationLog, process func(body []byte)) *notificationQueue {
+	q := &notificationQueue{bodies: make(chan []byte, size), done: make(chan struct{}), overflow: overflow, process: process}
+	go func() {
+		defer close(q.done)
+		for body := range q.bodies {
+			q.process(body)
+			q.pending.Done()
+		}
+	}()
+	return q
+}
+
+// enqueue queues a notification body without blocking, reporting whether it was queued.
+// Once the queue is closed, notifications are treated as not fitting.
+func (q *notificationQueue) enqueue(body []byte) bool {
+	q.mu.RLock()
+	if !q.closed {
+		q.pending.Add(1)
+		select {
+		case q.bodies <- body:
+			q.mu.RUnlock()
+			return true
+		default:
+			q.pending.Done()
+		}
+	}
+	q.mu.RUnlock()
+
+	if q.overflow != nil {
+		err := q.overflow.append(body)
+		if err == nil {
+			log.Printf("Webhook queue full, notification written to the overflow log (%d so far)", q.persisted.Add(1))
+			return false
+		}
+		log.Printf("Error writing overflow log: %v", err)
+	}
+	log.Printf("Webhook queue full, notification dropped (%d so far)", q.dropped.Add(1))
+	return false
+}
+
+// flush waits until every queued notification has been processed.
+func (q *notificationQueue) flush() {
+	q.pending.Wait()
+}
+
+// close stops taking notifications and waits until the worker has processed those already
+// queued, which Graph will not send again as they were acknowledged, or until ctx is done.
+func (q *notificationQueue) close(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.bodies)
+	}
+	q.mu.Unlock()
+
+	select {
+	case <-q.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%d notifications left unprocessed: %v", len(q.bodies), ctx.Err())
+	}
+}
+
+// counts returns how many notifications are waiting, and how many did not fit in the queue and
+// were dropped or written to the overflow log.
+func (q *notificationQueue) counts() (queued int, dropped int64, persisted int64) {
+	return len(q.bodies), q.dropped.Load(), q.persisted.Load()
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/bovinemagnet/msgraph-cli/graphhelper"
+	"github.com/joho/godotenv"
+)
+
+// setupField is a setting the setup wizard asks for.
+type setupField struct {
+	key    string
+	prompt string
+	secret bool // it is read without echo and its current value is not shown
+	check  func(value string) error
+	skip   func(values map[string]string) bool // the other settings make it unnecessary
+}
+
+var guidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// setupFields are the settings needed before the tool can start, in the order they are asked for.
+var setupFields = []setupField{
+	{"TENANT_ID", "Directory (tenant) id of the app registration, or the tenant's domain", false, checkTenantId, nil},
+	{"CLIENT_ID", "Application (client) id of the app registration", false, checkClientId, nil},
+	{"CLIENT_SECRET", "Client secret value of the app registration", true, nil, clientSecretUnneeded},
+	{"ROOM_EMAIL", "Email of the room to work with", false, checkSetupEmail, nil},
+	{"ORGANISER_EMAIL", "Email of the user who organises events", false, checkSetupEmail, nil},
+	{"ENDPOINT", "Public https URL Graph sends notifications to, such as https://example.ngrok.app/webhook", false, checkSetupEndpoint, nil},
+}
+
+// skipped reports whether the field is not asked for or written, given the other settings.
+func (field setupField) skipped(values map[string]string) bool {
+	return field.skip != nil && field.skip(values)
+}
+
+// clientSecretUnneeded reports whether the settings sign in without CLIENT_SECRET: with
+// DefaultAzureCredential, or with the secret read from Key Vault.
+func clientSecretUnneeded(values map[string]string) bool {
+	return strings.EqualFold(values["AUTH_MODE"], graphhelper.AuthModeDefault) || values["KEYVAULT_URL"] != ""
+}
+
+func checkTenantId(value string) error {
+	if guidPattern.MatchString(value) || (strings.Contains(value, ".") && !strings.ContainsAny(value, " /")) {
+		return nil
+	}
+	return fmt.Errorf("%q is neither a GUID nor a domain such as contoso.onmicrosoft.com", value)
+}
+
+func checkClientId(value string) error {
+	if !guidPattern.MatchString(value) {
+		return fmt.Errorf("%q is not a GUID", value)
+	}
+	return nil
+}
+
+func checkSetupEmail(value string) error {
+	if !graphhelper.IsValidEmail(value) {
+		return fmt.Errorf("%q is not a valid email address", value)
+	}
+	return nil
+}
+
+// checkSetupEndpoint only accepts https, as Graph will not create subscriptions notifying anything else.
+func checkSetupEndpoint(value string) error {
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return fmt.Errorf("%q is not an https URL", value)
+	}
+	return nil
+}
+
+// setupEnvPath returns the .env file the wizard edits: the one the tool would read, or .env in
+// the current directory when there is none.
+func setupEnvPath() (string, error) {
+	files, found, err := findEnvFiles()
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return ".env", nil
+	}
+	return files.env, nil
+}
+
+// runSetup asks for each of setupFields, offering the value already in the .env file at path so
+// it can be run again to change a single setting, and skipping those the file's other settings
+// make unnecessary, then checks the settings by signing in with
+// verify. The file is written with only its owner able to read it, as it holds the client
+// secret. Other lines of an existing file, comments included, are kept.
+func runSetup(r io.Reader, w io.Writer, path string, verify func(values map[string]string) error) error {
+	content, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	values, err := godotenv.Unmarshal(string(content))
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", path, err)
+	}
+
+	fmt.Fprintf(w, "Setting up %s. Press Enter to keep the value shown in brackets.\n", path)
+	for {
+		for _, field := range setupFields {
+			if field.skipped(values) {
+				fmt.Fprintf(w, "%s is not needed with these settings, skipped\n", field.key)
+				continue
+			}
+			value, err := askSetupField(r, w, field, values[field.key])
+			if err != nil {
+				return err
+			}
+			values[field.key] = value
+		}
+
+		fmt.Fprintln(w, "Signing in to check the settings...")
+		err := verify(values)
+		if err == nil {
+			fmt.Fprintln(w, "Signed in")
+			break
+		}
+		fmt.Fprintf(w, "The settings do not work: %v\n", err)
+		if confirmFrom(r, w, "Enter the settings again?") {
+			continue
+		}
+		if !confirmFrom(r, w, "Write them anyway?") {
+			return errors.New("setup cancelled, nothing written")
+		}
+		break
+	}
+
+	if err := writeEnvFile(path, updateEnvFile(content, values)); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Wrote %s\n", path)
+	return nil
+}
+
+// askSetupField asks for a setting until it is given a valid one. A blank answer keeps current.
+func askSetupField(r io.Reader, w io.Writer, field setupField, current string) (string, error) {
+	for {
+		shown := current
+		if field.secret && current != "" {
+			shown = "********"
+		}
+		fmt.Fprintf(w, "%s [%s]:\n", field.prompt, shown)
+		read := readLineFrom
+		if field.secret {
+			read = func(r io.Reader) (string, error) { return readSecretFrom(r, w) }
+		}
+		value, err := read(r)
+		if err != nil {
+			return "", err
+		}
+		value = strings.TrimSpace(value)
+		if value == "" {
+			value = current
+		}
+		if value == "" {
+			fmt.Fprintf(w, "%s is required\n", field.key)
+			continue
+		}
+		if field.check != nil {
+			if err := field.check(value); err != nil {
+				fmt.Fprintf(w, "%s: %v\n", field.key, err)
+				continue
+			}
+		}
+		return value, nil
+	}
+}
+
+// updateEnvFile sets the wizard's settings in the content of a .env file, replacing the lines
+// that set them and adding the ones it does not have.
+func updateEnvFile(content []byte, values map[string]string) []byte {
+	written := map[string]bool{}
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSuffix(string(content), "\n"), "\n") {
+		key, _, found := strings.Cut(strings.TrimPrefix(strings.TrimSpace(line), "export "), "=")
+		key = strings.TrimSpace(key)
+		if found && isSetupKey(key) {
+			if written[key] {
+				// a later duplicate would override the new value
+				continue
+			}
+			line, written[key] = envLine(key, values[key]), true
+		}
+		lines = append(lines, line)
+	}
+	if len(content) == 0 {
+		lines = nil
+	}
+	for _, field := range setupFields {
+		if !written[field.key] && !field.skipped(values) {
+			lines = append(lines, envLine(field.key, values[field.key]))
+		}
+	}
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
+
+func isSetupKey(key string) bool {
+	for _, field := range setupFields {
+		if field.key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// envLine formats a setting for a .env file. Single quotes keep the value as it is, so a secret
+// holding $ or \ is not expanded or unescaped when it is read back.
+func envLine(key string, value string) string {
+	if !strings.Contains(value, "'") {
+		return key + "='" + value + "'"
+	}
+	line, _ := godotenv.Marshal(map[string]string{key: value})
+	return line
+}
+
+// writeEnvFile writes a .env file readable only by its owner, tightening the permissions of an
+// existing file too.
+func writeEnvFile(path string, content []byte) error {
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		return fmt.Errorf("failed to restrict %s to its owner: %v", path, err)
+	}
+	return nil
+}
+
+// verifySetup loads the configuration the wizard's settings make, and signs in with them.
+func verifySetup(values map[string]string) error {
+	config, err := graphhelper.LoadConfigFrom(func(key string) string {
+		if value, ok := values[key]; ok {
+			return value
+		}
+		return os.Getenv(key)
+	})
+	if err != nil {
+		return err
+	}
+
+	graphHelper := graphhelper.NewGraphHelper(config)
+	if err := graphHelper.InitializeGraphForAppAuth(); err != nil {
+		return err
+	}
+	_, err = graphHelper.GetAppToken()
+	return err
+}
+
+// setup runs the setup wizard on the .env file the tool reads, warning when .env.local
+// overrides what was written and reporting a .env.local that cannot be read.
+func setup() error {
+	path, err := setupEnvPath()
+	if err != nil {
+		return err
+	}
+	if err := runSetup(os.Stdin, os.Stdout, path, verifySetup); err != nil {
+		return err
+	}
+
+	files, _, err := findEnvFiles()
+	if err != nil {
+		return fmt.Errorf("wrote %s, but could not check .env.local: %v", path, err)
+	}
+	local, err := godotenv.Read(files.local)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("wrote %s, but could not check %s, which may override it: %v", path, files.local, err)
+	}
+	for _, field := range setupFields {
+		if _, ok := local[field.key]; ok {
+			fmt.Printf("%s also sets %s, which overrides %s\n", files.local, field.key, path)
+		}
+	}
+	return nil
+}
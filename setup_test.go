@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joho/godotenv"
+)
+
+func TestUpdateEnvFile(t *testing.T) {
+	content := "# app registration\nexport TENANT_ID=old-tenant\nPORT=8080\nTENANT_ID=duplicate\n"
+	values := map[string]string{
+		"TENANT_ID":       "contoso.onmicrosoft.com",
+		"CLIENT_ID":       "11111111-2222-3333-4444-555555555555",
+		"CLIENT_SECRET":   `pa$$w\ord'1`,
+		"ROOM_EMAIL":      "room@example.com",
+		"ORGANISER_EMAIL": "organiser@example.com",
+		"ENDPOINT":        "https://example.com/webhook",
+	}
+
+	updated := string(updateEnvFile([]byte(content), values))
+	if !strings.HasPrefix(updated, "# app registration\nTENANT_ID='contoso.onmicrosoft.com'\nPORT=8080\nCLIENT_ID=") {
+		t.Errorf("updateEnvFile() = %q, want the comment and PORT kept and TENANT_ID replaced in place", updated)
+	}
+	if strings.Contains(updated, "duplicate") {
+		t.Errorf("updateEnvFile() = %q, want the duplicate TENANT_ID dropped", updated)
+	}
+
+	read, err := godotenv.Unmarshal(updated)
+	if err != nil {
+		t.Fatalf("updated file does not parse: %v", err)
+	}
+	for key, want := range values {
+		if read[key] != want {
+			t.Errorf("%s = %q, want %q", key, read[key], want)
+		}
+	}
+	if read["PORT"] != "8080" {
+		t.Errorf("PORT = %q, want it kept", read["PORT"])
+	}
+}
+
+func TestRunSetup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("CLIENT_SECRET=kept\nROOM_EMAIL=room@example.com\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	input := strings.Join([]string{
+		"contoso.onmicrosoft.com",
+		"not-a-guid",
+		"11111111-2222-3333-4444-555555555555",
+		"", // keep the secret
+		"", // keep the room
+		"organiser@example.com",
+		"http://example.com/webhook",
+		"https://example.com/webhook",
+	}, "\n") + "\n"
+
+	var verified map[string]string
+	var out strings.Builder
+	err := runSetup(strings.NewReader(input), &out, path, func(values map[string]string) error {
+		verified = values
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n%s", err, out.String())
+	}
+	for _, want := range []string{"CLIENT_ID: \"not-a-guid\" is not a GUID", "[********]", "[room@example.com]", "is not an https URL"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output = %q, want it to contain %q", out.String(), want)
+		}
+	}
+	if verified["CLIENT_SECRET"] != "kept" || verified["ENDPOINT"] != "https://example.com/webhook" {
+		t.Errorf("verified = %v, want the kept secret and the https endpoint", verified)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("permissions = %o, want 600", perm)
+	}
+	read, err := godotenv.Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read["ORGANISER_EMAIL"] != "organiser@example.com" || read["CLIENT_SECRET"] != "kept" {
+		t.Errorf("written = %v", read)
+	}
+}
+
+func TestRunSetupCancelledWhenSignInFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	input := "contoso.onmicrosoft.com\n11111111-2222-3333-4444-555555555555\nsecret\nroom@example.com\norganiser@example.com\nhttps://example.com/webhook\nn\nn\n"
+
+	var out strings.Builder
+	err := runSetup(strings.NewReader(input), &out, path, func(map[string]string) error {
+		return errors.New("invalid client secret")
+	})
+	if err == nil {
+		t.Fatal("runSetup() succeeded, want it cancelled")
+	}
+	if !strings.Contains(out.String(), "invalid client secret") {
+		t.Errorf("output = %q, want the sign in error", out.String())
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Stat() = %v, want nothing written", err)
+	}
+}
+
+func TestRunSetupSkipsClientSecret(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("AUTH_MODE=default\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	input := "contoso.onmicrosoft.com\n11111111-2222-3333-4444-555555555555\nroom@example.com\norganiser@example.com\nhttps://example.com/webhook\n"
+
+	var out strings.Builder
+	err := runSetup(strings.NewReader(input), &out, path, func(map[string]string) error { return nil })
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n%s", err, out.String())
+	}
+	if !strings.Contains(out.String(), "CLIENT_SECRET is not needed") {
+		t.Errorf("output = %q, want CLIENT_SECRET skipped", out.String())
+	}
+	read, err := godotenv.Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := read["CLIENT_SECRET"]; ok || read["ENDPOINT"] != "https://example.com/webhook" {
+		t.Errorf("written = %v, want the settings without CLIENT_SECRET", read)
+	}
+}
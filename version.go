@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// Set at build time, for example:
+//
+//	go build -ldflags "-X main.version=v1.2.0 -X main.commit=$(git rev-parse --short HEAD) -X main.buildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	version   = "dev"
+	commit    = ""
+	buildDate = ""
+)
+
+// sdkModule is the module whose version is reported alongside the tool's own.
+const sdkModule = "github.com/microsoftgraph/msgraph-sdk-go"
+
+// buildDetails describes the running build.
+type buildDetails struct {
+	Version    string
+	Commit     string
+	BuildDate  string
+	GoVersion  string
+	SDKVersion string
+}
+
+// readBuildDetails returns the details set with -ldflags, filling in what was not set from the
+// build information Go embeds, so "go install" and plain "go build" binaries still identify themselves.
+func readBuildDetails() buildDetails {
+	info, _ := debug.ReadBuildInfo()
+	return newBuildDetails(info, version, commit, buildDate)
+}
+
+// newBuildDetails combines the -ldflags values with the embedded build information, which may be nil.
+func newBuildDetails(info *debug.BuildInfo, version string, commit string, buildDate string) buildDetails {
+	details := buildDetails{
+		Version:    version,
+		Commit:     commit,
+		BuildDate:  buildDate,
+		GoVersion:  runtime.Version(),
+		SDKVersion: "unknown",
+	}
+	if info == nil {
+		return details
+	}
+
+	if info.GoVersion != "" {
+		details.GoVersion = info.GoVersion
+	}
+	if details.Version == "dev" && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		details.Version = info.Main.Version
+	}
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if details.Commit == "" {
+				details.Commit = setting.Value
+			}
+		case "vcs.time":
+			if details.BuildDate == "" {
+				details.BuildDate = setting.Value
+			}
+		}
+	}
+	for _, dep := range info.Deps {
+		if dep.Path != sdkModule {
+			continue
+		}
+		details.SDKVersion = dep.Version
+		if dep.Replace != nil {
+			details.SDKVersion = dep.Replace.Version
+		}
+	}
+	return details
+}
+
+// String returns the one line banner shown at startup.
+func (d buildDetails) String() string {
+	s := "Go MS Graph App-Only Simple CLI Tool " + d.Version
+	if d.Commit != "" {
+		s += " (" + d.Commit + ")"
+	}
+	return s
+}
+
+// showVersion prints the details of the running build, to quote when filing an issue.
+func showVersion() {
+	details := readBuildDetails()
+	fmt.Printf("Version:        %s\n", details.Version)
+	fmt.Printf("Commit:         %s\n", orUnknown(details.Commit))
+	fmt.Printf("Build date:     %s\n", orUnknown(details.BuildDate))
+	fmt.Printf("Go version:     %s\n", details.GoVersion)
+	fmt.Printf("msgraph-sdk-go: %s\n", details.SDKVersion)
+}
+
+// orUnknown returns s, or "unknown" when it is empty.
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
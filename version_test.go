@@ -0,0 +1,53 @@
+package main
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+func TestNewBuildDetails(t *testing.T) {
+	info := &debug.BuildInfo{
+		GoVersion: "go1.23.4",
+		Main:      debug.Module{Version: "(devel)"},
+		Deps: []*debug.Module{
+			{Path: "github.com/joho/godotenv", Version: "v1.5.1"},
+			{Path: sdkModule, Version: "v1.56.0"},
+		},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "4c0fe41"},
+			{Key: "vcs.time", Value: "2024-03-01T09:00:00Z"},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		info      *debug.BuildInfo
+		version   string
+		commit    string
+		buildDate string
+		want      buildDetails
+	}{
+		{
+			name: "from build info", info: info, version: "dev",
+			want: buildDetails{Version: "dev", Commit: "4c0fe41", BuildDate: "2024-03-01T09:00:00Z", GoVersion: "go1.23.4", SDKVersion: "v1.56.0"},
+		},
+		{
+			name: "ldflags win", info: info, version: "v1.2.0", commit: "abc1234", buildDate: "2024-04-01T00:00:00Z",
+			want: buildDetails{Version: "v1.2.0", Commit: "abc1234", BuildDate: "2024-04-01T00:00:00Z", GoVersion: "go1.23.4", SDKVersion: "v1.56.0"},
+		},
+		{
+			name: "go install", version: "dev",
+			info: &debug.BuildInfo{GoVersion: "go1.23.4", Main: debug.Module{Version: "v1.3.0"}},
+			want: buildDetails{Version: "v1.3.0", GoVersion: "go1.23.4", SDKVersion: "unknown"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := newBuildDetails(test.info, test.version, test.commit, test.buildDate)
+			if got != test.want {
+				t.Errorf("newBuildDetails() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,313 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// failingReader is a request body that cannot be read.
+type failingReader struct{}
+
+func (failingReader) Read(p []byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func TestHandleGraphSubscription(t *testing.T) {
+	tests := []struct {
+		name        string
+		request     *http.Request
+		status      int
+		contentType string
+		body        string
+	}{
+		{
+			name:        "validation",
+			request:     httptest.NewRequest("POST", "/webhook?validationToken=Validation%3A+token+123", nil),
+			status:      http.StatusOK,
+			contentType: "text/plain",
+			body:        "Validation: token 123",
+		},
+		{
+			name:        "notification",
+			request:     httptest.NewRequest("POST", "/webhook", strings.NewReader(`{"value":[{"subscriptionId":"sub-1","changeType":"created"}]}`)),
+			status:      http.StatusOK,
+			contentType: "application/json",
+			body:        `{"status":"received"}` + "\n",
+		},
+		{
+			name:        "method not allowed",
+			request:     httptest.NewRequest("GET", "/webhook", nil),
+			status:      http.StatusMethodNotAllowed,
+			contentType: "application/json",
+			body:        `{"error":"Method not allowed"}` + "\n",
+		},
+		{
+			name:        "unreadable body",
+			request:     httptest.NewRequest("POST", "/webhook", failingReader{}),
+			status:      http.StatusInternalServerError,
+			contentType: "application/json",
+			body:        `{"error":"Failed to read request body"}` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			live := newLiveBookings(nil, newConsole())
+			queue := newNotificationQueue(1, nil, func(body []byte) { processNotification(body, live, nil, nil) })
+			recorder := httptest.NewRecorder()
+
+			handleGraphSubscription(recorder, test.request, queue, "")
+
+			if recorder.Code != test.status {
+				t.Errorf("status = %d, want %d", recorder.Code, test.status)
+			}
+			if got := recorder.Header().Get("Content-Type"); got != test.contentType {
+				t.Errorf("Content-Type = %q, want %q", got, test.contentType)
+			}
+			if got := recorder.Body.String(); got != test.body {
+				t.Errorf("body = %q, want %q", got, test.body)
+			}
+		})
+	}
+}
+
+func TestHandleGraphSubscriptionLogsNotifications(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notifications.log")
+	live := newLiveBookings(nil, newConsole())
+	queue := newNotificationQueue(1, nil, func(body []byte) { processNotification(body, live, newNotificationLog(path), nil) })
+	request := httptest.NewRequest("POST", "/webhook", strings.NewReader(`{"value":[{"subscriptionId":"sub-1","changeType":"updated"}]}`))
+	recorder := httptest.NewRecorder()
+
+	handleGraphSubscription(recorder, request, queue, "")
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+	queue.flush()
+	logged, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(logged), `"subscriptionId":"sub-1"`) {
+		t.Errorf("notification log %q does not contain the notification", logged)
+	}
+}
+
+func TestShutdownWebhookServerStopsServing(t *testing.T) {
+	server := &http.Server{Addr: "127.0.0.1:0"}
+	stopped := make(chan struct{})
+	go func() {
+		startWebhookServer(server, 0, "", "")
+		close(stopped)
+	}()
+
+	shutdownWebhookServer(server, newNotificationQueue(1, nil, func(body []byte) {}))
+
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("startWebhookServer() still serving after shutdown")
+	}
+}
+
+// startTestWebhookServer serves the webhook on an ephemeral port, logging notifications to a
+// temporary file, and returns the webhook URL, the log's path and the queue to flush before
+// reading the log.
+func startTestWebhookServer(t *testing.T, clientState string) (string, string, *notificationQueue) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "notifications.log")
+	live, notifications := newLiveBookings(nil, newConsole()), newNotificationLog(path)
+	queue := newNotificationQueue(1, nil, func(body []byte) { processNotification(body, live, notifications, nil) })
+	handler := newWebhookHandler(queue, func() string { return clientState })
+	server := &http.Server{Addr: "127.0.0.1:0", Handler: handler}
+
+	listener, err := bindWebhookPort(server.Addr, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	go serveWebhook(server, listener, "", "")
+	t.Cleanup(func() { shutdownWebhookServer(server, queue) })
+
+	return "http://" + listener.Addr().String() + "/webhook", path, queue
+}
+
+func TestWebhookServer(t *testing.T) {
+	tests := []struct {
+		name        string
+		clientState string
+		query       string
+		body        string
+		status      int
+		contentType string
+		response    string
+		logged      bool
+	}{
+		{
+			name:        "validation",
+			query:       "?validationToken=Validation%3A+token+123",
+			status:      http.StatusOK,
+			contentType: "text/plain",
+			response:    "Validation: token 123",
+		},
+		{
+			name:        "notification",
+			body:        `{"value":[{"subscriptionId":"sub-1","changeType":"created"}]}`,
+			status:      http.StatusOK,
+			contentType: "application/json",
+			response:    `{"status":"received"}` + "\n",
+			logged:      true,
+		},
+		{
+			name:        "matching client state",
+			clientState: "s3cret",
+			body:        `{"value":[{"subscriptionId":"sub-1","changeType":"created","clientState":"s3cret"}]}`,
+			status:      http.StatusOK,
+			contentType: "application/json",
+			response:    `{"status":"received"}` + "\n",
+			logged:      true,
+		},
+		{
+			name:        "client state mismatch",
+			clientState: "s3cret",
+			body:        `{"value":[{"subscriptionId":"sub-1","changeType":"created","clientState":"s3cret"},{"subscriptionId":"sub-2","changeType":"deleted","clientState":"guess"}]}`,
+			status:      http.StatusForbidden,
+			contentType: "application/json",
+			response:    `{"error":"Invalid clientState"}` + "\n",
+		},
+		{
+			name:        "client state missing",
+			clientState: "s3cret",
+			body:        `{"value":[{"subscriptionId":"sub-1","changeType":"created"}]}`,
+			status:      http.StatusForbidden,
+			contentType: "application/json",
+			response:    `{"error":"Invalid clientState"}` + "\n",
+		},
+		{
+			name:        "validation needs no client state",
+			clientState: "s3cret",
+			query:       "?validationToken=abc",
+			status:      http.StatusOK,
+			contentType: "text/plain",
+			response:    "abc",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			url, path, queue := startTestWebhookServer(t, test.clientState)
+
+			response, err := http.Post(url+test.query, "application/json", strings.NewReader(test.body))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer response.Body.Close()
+			body, err := io.ReadAll(response.Body)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if response.StatusCode != test.status {
+				t.Errorf("status = %d, want %d", response.StatusCode, test.status)
+			}
+			if got := response.Header.Get("Content-Type"); got != test.contentType {
+				t.Errorf("Content-Type = %q, want %q", got, test.contentType)
+			}
+			if string(body) != test.response {
+				t.Errorf("body = %q, want %q", body, test.response)
+			}
+
+			queue.flush()
+			logged, err := os.ReadFile(path)
+			if err != nil && !os.IsNotExist(err) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := strings.Contains(string(logged), `"subscriptionId":"sub-1"`); got != test.logged {
+				t.Errorf("notification logged = %t, want %t: %q", got, test.logged, logged)
+			}
+		})
+	}
+}
+
+func TestNotificationQueueOverflow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "overflow.log")
+	release := make(chan struct{})
+	var processed []string
+	queue := newNotificationQueue(1, nil, func(body []byte) {
+		<-release
+		processed = append(processed, string(body))
+	})
+
+	// the worker holds the first, the buffer the second, the third does not fit
+	if !queue.enqueue([]byte("first")) {
+		t.Fatal("enqueue(first) = false, want queued")
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for len(queue.bodies) != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if !queue.enqueue([]byte("second")) {
+		t.Fatal("enqueue(second) = false, want queued")
+	}
+	if queue.enqueue([]byte("third")) {
+		t.Fatal("enqueue(third) = true, want dropped")
+	}
+	queue.overflow = newNotificationLog(path)
+	if queue.enqueue([]byte(`{"value":[{"subscriptionId":"sub-4"}]}`)) {
+		t.Fatal("enqueue(fourth) = true, want written to the overflow log")
+	}
+
+	close(release)
+	queue.flush()
+	if strings.Join(processed, ",") != "first,second" {
+		t.Errorf("processed = %v, want first and second", processed)
+	}
+	if queued, dropped, persisted := queue.counts(); queued != 0 || dropped != 1 || persisted != 1 {
+		t.Errorf("counts() = %d, %d, %d, want 0, 1, 1", queued, dropped, persisted)
+	}
+	if logged, err := os.ReadFile(path); err != nil || !strings.Contains(string(logged), "sub-4") {
+		t.Errorf("overflow log = %q, %v, want the fourth notification", logged, err)
+	}
+}
+
+func TestNotificationQueueClose(t *testing.T) {
+	var processed []string
+	queue := newNotificationQueue(3, nil, func(body []byte) {
+		time.Sleep(10 * time.Millisecond)
+		processed = append(processed, string(body))
+	})
+	for _, body := range []string{"first", "second", "third"} {
+		queue.enqueue([]byte(body))
+	}
+
+	// the notifications already acknowledged are processed before close returns
+	if err := queue.close(context.Background()); err != nil {
+		t.Fatalf("close() = %v, want the queue drained", err)
+	}
+	if strings.Join(processed, ",") != "first,second,third" {
+		t.Errorf("processed = %v, want every queued notification", processed)
+	}
+	if queue.enqueue([]byte("late")) {
+		t.Error("enqueue() after close = true, want it refused")
+	}
+}
+
+func TestNotificationQueueCloseTimesOut(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	queue := newNotificationQueue(1, nil, func(body []byte) { <-release })
+	queue.enqueue([]byte("stuck"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := queue.close(ctx); err == nil {
+		t.Error("close() = nil, want it to give up when the worker does not finish in time")
+	}
+}